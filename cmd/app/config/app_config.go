@@ -17,6 +17,27 @@ type APPConfig struct {
 	HealthCheckTimeout time.Duration `env:"CONFIG_HEALTH_CHECK_TIMEOUT"`
 	Timezone           string        `env:"CONFIG_TIMEZONE"`
 	AppName            string        `env:"CONFIG_APP_NAME" env-default:"whalebone_clients"`
+
+	// DeletedClientsRetentionDays is the number of days after which soft deleted clients are purged, 0 disables the purge
+	DeletedClientsRetentionDays int           `env:"CONFIG_DELETED_CLIENTS_RETENTION_DAYS" env-default:"0"`
+	DeletedClientsPurgeInterval time.Duration `env:"CONFIG_DELETED_CLIENTS_PURGE_INTERVAL" env-default:"1h"`
+
+	IdempotencyKeyTTL           time.Duration `env:"CONFIG_IDEMPOTENCY_KEY_TTL" env-default:"24h"`
+	IdempotencyKeyPurgeInterval time.Duration `env:"CONFIG_IDEMPOTENCY_KEY_PURGE_INTERVAL" env-default:"1h"`
+
+	// ClientImportInterval is how often scheduled client imports are looked for, 0 disables the import
+	ClientImportInterval time.Duration `env:"CONFIG_CLIENT_IMPORT_INTERVAL" env-default:"5s"`
+
+	// OutboxPublisher is one of stdout, file and webhook, empty publishes only to the webhook subscriptions
+	OutboxPublisher      string        `env:"CONFIG_OUTBOX_PUBLISHER" env-default:"stdout"`
+	OutboxFilePath       string        `env:"CONFIG_OUTBOX_FILE_PATH"`
+	OutboxWebhookURL     string        `env:"CONFIG_OUTBOX_WEBHOOK_URL"`
+	OutboxWebhookTimeout time.Duration `env:"CONFIG_OUTBOX_WEBHOOK_TIMEOUT" env-default:"10s"`
+	OutboxRelayInterval  time.Duration `env:"CONFIG_OUTBOX_RELAY_INTERVAL" env-default:"1s"`
+
+	// WebhookDeliveryInterval is how often pending webhook deliveries are sent, 0 disables the delivery
+	WebhookDeliveryInterval time.Duration `env:"CONFIG_WEBHOOK_DELIVERY_INTERVAL" env-default:"1s"`
+	WebhookTimeout          time.Duration `env:"CONFIG_WEBHOOK_TIMEOUT" env-default:"10s"`
 }
 
 func CreateAPPConfig() (APPConfig, error) {
@@ -32,3 +53,7 @@ func CreateAPPConfig() (APPConfig, error) {
 func (cfg APPConfig) AllowedOrigins() []string {
 	return strings.Split(cfg.AllowOrigins, ";")
 }
+
+func (cfg APPConfig) DeletedClientsRetention() time.Duration {
+	return time.Duration(cfg.DeletedClientsRetentionDays) * 24 * time.Hour
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
@@ -21,6 +22,18 @@ type PostgresConfig struct {
 	MinConns          int32         `env:"CONFIG_DATABASE_POOL_MIN_CONNS"`
 	HealthCheckPeriod time.Duration `env:"CONFIG_DATABASE_POOL_HEALTH_CHECK_PERIOD"`
 	SSLMode           string        `env:"CONFIG_DATABASE_SSL_MODE" env-default:"disable"`
+	// TxMaxAttempts is how many times a transaction failing on a serialization failure or a deadlock is run
+	TxMaxAttempts int `env:"CONFIG_DATABASE_TX_MAX_ATTEMPTS" env-default:"3"`
+	// ReplicaHosts are host:port of the read replicas, they use the user, password and database of the primary
+	ReplicaHosts       []string      `env:"CONFIG_DATABASE_REPLICA_HOSTS" env-separator:","`
+	ReplicaMaxLag      time.Duration `env:"CONFIG_DATABASE_REPLICA_MAX_LAG" env-default:"5s"`
+	ReplicaCheckPeriod time.Duration `env:"CONFIG_DATABASE_REPLICA_CHECK_PERIOD" env-default:"5s"`
+	// SlowQueryThreshold logs slower queries at warn, 0 disables it
+	SlowQueryThreshold time.Duration `env:"CONFIG_DATABASE_SLOW_QUERY_THRESHOLD" env-default:"500ms"`
+	// LogQueryArgs adds the query arguments with the personal data masked to the query logs
+	LogQueryArgs bool `env:"CONFIG_DATABASE_LOG_QUERY_ARGS" env-default:"false"`
+	// AutoMigrate applies the pending migrations at startup
+	AutoMigrate bool `env:"CONFIG_DATABASE_AUTO_MIGRATE" env-default:"false"`
 }
 
 func CreatePostgresConfig() (PostgresConfig, error) {
@@ -43,3 +56,24 @@ func (pc PostgresConfig) ConnectionURL() string {
 		pc.SSLMode,
 	)
 }
+
+func (pc PostgresConfig) ReplicaConnectionURLs() []string {
+	urls := make([]string, 0, len(pc.ReplicaHosts))
+	for _, host := range pc.ReplicaHosts {
+		host = strings.TrimSpace(host)
+		if host == "" {
+			continue
+		}
+
+		urls = append(urls, fmt.Sprintf(
+			"postgresql://%s:%s@%s/%s?sslmode=%s",
+			pc.User,
+			pc.Password,
+			host,
+			pc.DBName,
+			pc.SSLMode,
+		))
+	}
+
+	return urls
+}
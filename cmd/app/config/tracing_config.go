@@ -0,0 +1,25 @@
+package config
+
+import (
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+type TracingConfig struct {
+	// Exporter is one of otlp, stdout and none, the spans are still created with none so the logs carry the trace ids
+	Exporter string `env:"CONFIG_TRACING_EXPORTER" env-default:"none"`
+	// OTLPEndpoint is host:port of the OTLP HTTP collector
+	OTLPEndpoint string `env:"CONFIG_TRACING_OTLP_ENDPOINT" env-default:"localhost:4318"`
+	OTLPInsecure bool   `env:"CONFIG_TRACING_OTLP_INSECURE" env-default:"true"`
+	// SampleRatio is the share of the traces started by the service which are sampled, the traces of the callers keep their decision
+	SampleRatio float64 `env:"CONFIG_TRACING_SAMPLE_RATIO" env-default:"1"`
+}
+
+func CreateTracingConfig() (TracingConfig, error) {
+	var cfg TracingConfig
+	err := cleanenv.ReadEnv(&cfg)
+	if err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,104 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jamm3e3333/whalebone-go-test-project/db"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/migrate"
+)
+
+const (
+	CommandUp     = "up"
+	CommandDown   = "down"
+	CommandStatus = "status"
+	CommandRedo   = "redo"
+	CommandReset  = "reset"
+)
+
+// Run runs the migrate subcommand with the embedded migrations
+func Run(ctx context.Context, connectionURL string, command string, lg logger.Logger) error {
+	return withMigrator(ctx, connectionURL, lg, func(m *migrate.Migrator) error {
+		switch command {
+		case CommandUp:
+			applied, err := m.Up(ctx)
+			if err != nil {
+				return err
+			}
+			lg.Info("%d migrations applied", applied)
+
+			return nil
+		case CommandDown:
+			return m.Down(ctx)
+		case CommandRedo:
+			return m.Redo(ctx)
+		case CommandReset:
+			return m.Reset(ctx)
+		case CommandStatus:
+			statuses, err := m.Status(ctx)
+			if err != nil {
+				return err
+			}
+
+			return printStatus(statuses)
+		default:
+			return fmt.Errorf("unknown migrate command %q, use %s, %s, %s, %s or %s", command, CommandUp, CommandDown, CommandStatus, CommandRedo, CommandReset)
+		}
+	})
+}
+
+// Up applies the pending embedded migrations, instances starting at the same time apply them only once
+func Up(ctx context.Context, connectionURL string, lg logger.Logger) error {
+	return Run(ctx, connectionURL, CommandUp, lg)
+}
+
+// Migrations returns the embedded migrations the binary was built with
+func Migrations() ([]migrate.Migration, error) {
+	return migrate.Load(db.Migrations, db.MigrationsDir)
+}
+
+func withMigrator(ctx context.Context, connectionURL string, lg logger.Logger, f func(m *migrate.Migrator) error) error {
+	migrations, err := fs.Sub(db.Migrations, db.MigrationsDir)
+	if err != nil {
+		return err
+	}
+
+	conn, err := sql.Open("pgx", connectionURL)
+	if err != nil {
+		return fmt.Errorf("unable to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	err = conn.PingContext(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to connect to database: %w", err)
+	}
+
+	m, err := migrate.New(conn, migrations, lg)
+	if err != nil {
+		return err
+	}
+
+	return f(m)
+}
+
+func printStatus(statuses []migrate.Status) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	fmt.Fprintln(w, "Applied At\tMigration")
+	for _, s := range statuses {
+		appliedAt := "Pending"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format(time.DateTime)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", appliedAt, s.Name)
+	}
+
+	return w.Flush()
+}
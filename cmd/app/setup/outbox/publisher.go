@@ -0,0 +1,44 @@
+package outbox
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/outbox"
+)
+
+const (
+	StdoutPublisher  = "stdout"
+	FilePublisher    = "file"
+	WebhookPublisher = "webhook"
+)
+
+func noClose() error {
+	return nil
+}
+
+// NewPublisher returns nil publisher when the kind is empty, the returned close releases the file of the file publisher
+// and does nothing for the other ones
+func NewPublisher(kind string, filePath string, webhookURL string, webhookTimeout time.Duration) (outbox.Publisher, func() error, error) {
+	switch kind {
+	case "":
+		return nil, noClose, nil
+	case StdoutPublisher:
+		return outbox.NewWriterPublisher(os.Stdout), noClose, nil
+	case FilePublisher:
+		f, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to open outbox file %s : %v", filePath, err)
+		}
+		return outbox.NewWriterPublisher(f), f.Close, nil
+	case WebhookPublisher:
+		if webhookURL == "" {
+			return nil, nil, fmt.Errorf("outbox webhook url is required")
+		}
+		return outbox.NewWebhookPublisher(webhookURL, &http.Client{Timeout: webhookTimeout}), noClose, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown outbox publisher %s", kind)
+	}
+}
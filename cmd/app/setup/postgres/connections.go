@@ -18,6 +18,7 @@ func EstablishConnection(ctx context.Context, cfg pgx.Config, lg logger.Logger,
 	conn.RegisterMetrics(pgx.RegisterMetricsOptions{
 		Qm: mm.Qm,
 		Tm: mm.Tm,
+		Rm: mm.Rm,
 	})
 
 	return conn
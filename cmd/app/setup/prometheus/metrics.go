@@ -4,6 +4,7 @@ import (
 	"sync"
 
 	promInfra "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/infrastructure/prometheus"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/pgx"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -38,9 +39,9 @@ func newMetrics(subsystem string) *promInfra.Metrics {
 		prometheus.CounterOpts{
 			Subsystem: subsystem,
 			Name:      "pg_queries",
-			Help:      "Number of queries executed on PG partitioned by success/error result and function name",
+			Help:      "Number of queries executed on PG partitioned by success/no_rows/error result, function name and SQLSTATE code",
 		},
-		[]string{"result", "pg_func_name"},
+		[]string{"result", "pg_func_name", "sqlstate"},
 	)
 	prometheus.MustRegister(queryCounter)
 
@@ -85,6 +86,56 @@ func newMetrics(subsystem string) *promInfra.Metrics {
 	)
 	prometheus.MustRegister(transactionDurationHistogram)
 
+	queryTargetCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: subsystem,
+			Name:      "pg_query_targets",
+			Help:      "Number of queries and transactions routed to PG partitioned by the primary or replica target",
+		},
+		[]string{"target"},
+	)
+	prometheus.MustRegister(queryTargetCounter)
+
+	replicaLagGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: subsystem,
+			Name:      "pg_replica_lag",
+			Help:      "Seconds the PG replica lags behind the primary partitioned by replica",
+		},
+		[]string{"target"},
+	)
+	prometheus.MustRegister(replicaLagGauge)
+
+	replicaHealthGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: subsystem,
+			Name:      "pg_replica_healthy",
+			Help:      "Whether queries are routed to the PG replica partitioned by replica",
+		},
+		[]string{"target"},
+	)
+	prometheus.MustRegister(replicaHealthGauge)
+
+	outboxMessageCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: subsystem,
+			Name:      "outbox_messages",
+			Help:      "Number of outbox messages partitioned by published/failed result and event type",
+		},
+		[]string{"result", "event_type"},
+	)
+	prometheus.MustRegister(outboxMessageCounter)
+
+	outboxLagHistogram := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300, 900},
+			Subsystem: subsystem,
+			Name:      "outbox_lag",
+			Help:      "Seconds between writing an outbox message and publishing it partitioned by event type",
+		}, []string{"event_type"},
+	)
+	prometheus.MustRegister(outboxLagHistogram)
+
 	return promInfra.NewMetrics(
 		&promInfra.PgMetrics{
 			Qm: &promInfra.QueryMetrics{
@@ -96,6 +147,20 @@ func newMetrics(subsystem string) *promInfra.Metrics {
 				TransactionDurationHistogram: transactionDurationHistogram,
 			},
 			Cm: &promInfra.ConnectionMetrics{DbConnectionGauge: dbConnectionGauge},
+			Rm: &promInfra.ReplicaMetrics{
+				QueryTargetCounter: queryTargetCounter,
+				ReplicaLagGauge:    replicaLagGauge,
+				ReplicaHealthGauge: replicaHealthGauge,
+			},
+		},
+		&promInfra.OutboxMetrics{
+			OutboxMessageCounter: outboxMessageCounter,
+			OutboxLagHistogram:   outboxLagHistogram,
 		},
 	)
 }
+
+// RegisterPoolStats exports the statistics of the connection pools, it is registered once the pools exist
+func RegisterPoolStats(subsystem string, pc *pgx.ConnectionPool) {
+	prometheus.MustRegister(pgx.NewPoolStatsCollector(pc, subsystem))
+}
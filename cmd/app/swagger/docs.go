@@ -87,8 +87,97 @@ const docTemplate = `{
             }
         },
         "/v1/client": {
+            "get": {
+                "description": "Lists clients ordered by creation with keyset pagination. Pass the returned next_cursor as cursor to get the next page, there are no more clients when next_cursor is missing.\nDate filters are RFC 3339 timestamps, the \"from\" bound is inclusive and the \"to\" bound is exclusive.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Client"
+                ],
+                "summary": "List clients",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page returned as next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "whalebone.io",
+                        "description": "Email domain",
+                        "name": "email_domain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "John",
+                        "description": "Name prefix",
+                        "name": "name_prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Date of birth from",
+                        "name": "date_of_birth_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Date of birth to",
+                        "name": "date_of_birth_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Created at from",
+                        "name": "created_at_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Created at to",
+                        "name": "created_at_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of clients",
+                        "schema": {
+                            "$ref": "#/definitions/client.ListClientsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_parameters with the violations",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_server_error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Creates a new client account with the provided details such as email, date of birth, name, and id.",
+                "description": "Creates a new client account with the provided details such as email, date of birth, name, and id.\nThe name has 2 to 100 letters, spaces, apostrophes, dots or hyphens and the date of birth is neither in the future nor more than 150 years ago. All violations of the request are reported together.",
                 "consumes": [
                     "application/json"
                 ],
@@ -108,6 +197,27 @@ const docTemplate = `{
                         "in": "header",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "example": "8e03978e-40d5-43e8-bc93-6894a57f9324",
+                        "description": "Unique key of the request, retries with the same key and body replay the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "john.doe@whalebone.io",
+                        "description": "Actor of the change recorded in the client history",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "8e03978e-40d5-43e8-bc93-6894a57f9324",
+                        "description": "Request ID recorded in the client history",
+                        "name": "X-Request-ID",
+                        "in": "header"
+                    },
                     {
                         "description": "Client data",
                         "name": "data",
@@ -126,103 +236,1247 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "{\"error\": \"bad request\"}",
+                        "description": "malformed_request or invalid_parameters with the violations, idempotency_key_too_long, or unreadable_body",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "idempotency_key_reused, or idempotency_key_in_progress",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "422": {
-                        "description": "{\"error\": \"unprocessable entity\"}",
+                        "description": "validation_failed with the violations, or client_already_exists",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_server_error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/client/export": {
+            "get": {
+                "description": "Streams all clients ordered by creation as CSV with the header row id,email,name,date_of_birth, as NDJSON with a client on every line or as a JSON array. The export can be imported back with POST /v1/client/import.\nFilters are the same as for the client listing. When the export fails after it started the connection is closed, so an incomplete export is never mistaken for a complete one.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/json"
+                ],
+                "tags": [
+                    "Client"
+                ],
+                "summary": "Export clients",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson",
+                            "json"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Format of the export",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "whalebone.io",
+                        "description": "Email domain",
+                        "name": "email_domain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "John",
+                        "description": "Name prefix",
+                        "name": "name_prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Date of birth from",
+                        "name": "date_of_birth_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Date of birth to",
+                        "name": "date_of_birth_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Created at from",
+                        "name": "created_at_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Created at to",
+                        "name": "created_at_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Clients",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/client.GetClientResponse"
+                            }
+                        },
+                        "headers": {
+                            "Content-Disposition": {
+                                "type": "string",
+                                "description": "attachment; filename=clients.csv"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_parameters with the violations",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_server_error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/client/import": {
+            "post": {
+                "description": "Imports clients from CSV (text/csv) with the header row id,email,name,date_of_birth or from NDJSON (application/x-ndjson) with a client object on every line. Every row is validated the same way as the client creation, rows whose id or email already exists or repeats in the import are duplicates.\nImports up to 1000 rows are answered with the per-row report. Larger imports run as a job and are answered with 202 and the Location of the import status. The import is decoded in memory, so it is limited to 50000 rows and a body of 8 MiB, larger imports are rejected with 413 and have to be split.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Client"
+                ],
+                "summary": "Import clients",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "text/csv",
+                        "description": "Content-Type",
+                        "name": "Content-Type",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "john.doe@whalebone.io",
+                        "description": "Actor of the change recorded in the client history",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "8e03978e-40d5-43e8-bc93-6894a57f9324",
+                        "description": "Request ID recorded in the client history",
+                        "name": "X-Request-ID",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Clients in CSV or NDJSON",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report of the import",
+                        "schema": {
+                            "$ref": "#/definitions/client.ClientImportReportResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Scheduled import",
+                        "schema": {
+                            "$ref": "#/definitions/client.ClientImportResponse"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the import status"
                             }
                         }
                     },
+                    "400": {
+                        "description": "malformed_request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "payload_too_large for more than 50000 rows or a body over 8 MiB",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "unsupported_media_type",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_server_error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/client/import/{id}": {
+            "get": {
+                "description": "Retrieves the status of the scheduled client import, the per-row report is returned once the import is completed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Client"
+                ],
+                "summary": "Get client import status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"123e4567-e89b-12d3-a456-426614174000\"",
+                        "description": "Import ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Client import",
+                        "schema": {
+                            "$ref": "#/definitions/client.ClientImportResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "client_import_not_found, or not_found for a malformed client import id",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
                     "500": {
-                        "description": "{\"error\": \"internal server error\"}",
+                        "description": "internal_server_error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/client/{id}": {
+            "get": {
+                "description": "Retrieves a client's information based on the provided client ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Client"
+                ],
+                "summary": "Get client details by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"123e4567-e89b-12d3-a456-426614174000\"",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"42\"",
+                        "description": "Entity tags of the client, the client is not returned when one of them is current",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Client details",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "$ref": "#/definitions/client.GetClientResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Entity tag of the current client version"
                             }
                         }
+                    },
+                    "304": {
+                        "description": "Not Modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "client_not_found, or not_found for a malformed client id",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_server_error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
                     }
                 }
+            },
+            "put": {
+                "description": "Replaces all details of an existing client identified by the provided client ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Client"
+                ],
+                "summary": "Replace a client",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "application/json",
+                        "description": "Content-Type",
+                        "name": "Content-Type",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"42\"",
+                        "description": "Entity tag of the client returned by GET, * matches any version",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"123e4567-e89b-12d3-a456-426614174000\"",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "john.doe@whalebone.io",
+                        "description": "Actor of the change recorded in the client history",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "8e03978e-40d5-43e8-bc93-6894a57f9324",
+                        "description": "Request ID recorded in the client history",
+                        "name": "X-Request-ID",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Client data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/client.UpdateClientReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Entity tag of the updated client version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "malformed_request with the violations",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "client_not_found, or not_found for a malformed client id",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "client_version_mismatch",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "validation_failed with the violations, or client_already_exists",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "precondition_required",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_server_error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Soft deletes a client identified by the provided client ID. Deleted client can be restored until it is purged.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Client"
+                ],
+                "summary": "Delete a client",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"42\"",
+                        "description": "Entity tag of the client returned by GET, * matches any version",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"123e4567-e89b-12d3-a456-426614174000\"",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "john.doe@whalebone.io",
+                        "description": "Actor of the change recorded in the client history",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "8e03978e-40d5-43e8-bc93-6894a57f9324",
+                        "description": "Request ID recorded in the client history",
+                        "name": "X-Request-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "client_not_found, or not_found for a malformed client id",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "client_version_mismatch",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "precondition_required",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_server_error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Applies a JSON merge patch (RFC 7386) to an existing client identified by the provided client ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Client"
+                ],
+                "summary": "Partially update a client",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "application/merge-patch+json",
+                        "description": "Content-Type",
+                        "name": "Content-Type",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"42\"",
+                        "description": "Entity tag of the client returned by GET, * matches any version",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"123e4567-e89b-12d3-a456-426614174000\"",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "john.doe@whalebone.io",
+                        "description": "Actor of the change recorded in the client history",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "8e03978e-40d5-43e8-bc93-6894a57f9324",
+                        "description": "Request ID recorded in the client history",
+                        "name": "X-Request-ID",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Client data to be merged",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/client.UpdateClientReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Entity tag of the updated client version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "malformed_request with the violations",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "client_not_found, or not_found for a malformed client id",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "client_version_mismatch",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "unsupported_media_type",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "validation_failed with the violations, or client_already_exists",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "precondition_required",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_server_error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/client/{id}/history": {
+            "get": {
+                "description": "Lists changes of the client from the oldest with keyset pagination. Every entry contains the client before and after the change, the actor and the request ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Client"
+                ],
+                "summary": "Get client change history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"123e4567-e89b-12d3-a456-426614174000\"",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page returned as next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of client changes",
+                        "schema": {
+                            "$ref": "#/definitions/client.ClientHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_parameters with the violations",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "client_not_found, or not_found for a malformed client id",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_server_error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/client/{id}/purge": {
+            "delete": {
+                "description": "Permanently removes a soft deleted client identified by the provided client ID. The client has to be deleted first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Client"
+                ],
+                "summary": "Purge a deleted client",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"123e4567-e89b-12d3-a456-426614174000\"",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "john.doe@whalebone.io",
+                        "description": "Actor of the change recorded in the client history",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "8e03978e-40d5-43e8-bc93-6894a57f9324",
+                        "description": "Request ID recorded in the client history",
+                        "name": "X-Request-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "client_not_found, or not_found for a malformed client id",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_server_error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/client/{id}/restore": {
+            "post": {
+                "description": "Restores a soft deleted client identified by the provided client ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Client"
+                ],
+                "summary": "Restore a deleted client",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"123e4567-e89b-12d3-a456-426614174000\"",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "john.doe@whalebone.io",
+                        "description": "Actor of the change recorded in the client history",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "8e03978e-40d5-43e8-bc93-6894a57f9324",
+                        "description": "Request ID recorded in the client history",
+                        "name": "X-Request-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "client_not_found, or not_found for a malformed client id",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "client_already_exists",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_server_error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks": {
+            "get": {
+                "description": "Lists all webhooks.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "List webhooks",
+                "responses": {
+                    "200": {
+                        "description": "Webhooks",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.ListWebhooksResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_server_error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Subscribes the URL to client events, no event types subscribe all of them. Every delivery is a POST of the JSON event signed in the X-Webhook-Signature header as \"t=\u003cunix timestamp\u003e,v1=\u003chex HMAC-SHA256 of \"\u003ctimestamp\u003e.\u003cbody\u003e\" with the secret\u003e\".",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Create a webhook",
+                "parameters": [
+                    {
+                        "description": "Webhook data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/webhook.CreateWebhookReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created webhook with its secret",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.CreateWebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "malformed_request with the violations",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "validation_failed with the violations",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_server_error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/{id}": {
+            "get": {
+                "description": "Retrieves the webhook, the secret is not returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Get webhook by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"123e4567-e89b-12d3-a456-426614174000\"",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.WebhookResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "webhook_not_found, or not_found for a malformed webhook id",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_server_error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces the URL, event types and active flag of the webhook, the secret is kept. Deliveries of an inactive webhook wait until it is activated again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Replace a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"123e4567-e89b-12d3-a456-426614174000\"",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Webhook data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/webhook.UpdateWebhookReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "malformed_request with the violations",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "webhook_not_found, or not_found for a malformed webhook id",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "validation_failed with the violations",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_server_error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes the webhook together with its delivery log.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Delete a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"123e4567-e89b-12d3-a456-426614174000\"",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "webhook_not_found, or not_found for a malformed webhook id",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_server_error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "Lists the delivery log of the webhook from the newest with keyset pagination. Pass the returned next_cursor as cursor to get the next page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"123e4567-e89b-12d3-a456-426614174000\"",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page returned as next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of deliveries",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.ListWebhookDeliveriesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_parameters with the violations",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "webhook_not_found, or not_found for a malformed webhook id",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_server_error",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "client.ClientHistoryEntryResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete",
+                        "restore",
+                        "purge"
+                    ],
+                    "example": "update"
+                },
+                "actor": {
+                    "type": "string",
+                    "example": "john.doe@whalebone.io"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "type": "object"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "8e03978e-40d5-43e8-bc93-6894a57f9324"
+                }
+            }
+        },
+        "client.ClientHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/client.ClientHistoryEntryResponse"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "MTIz"
+                }
+            }
+        },
+        "client.ClientImportReportResponse": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "duplicate": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "invalid": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/client.ClientImportRowResponse"
+                    }
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "client.ClientImportResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "INTERNAL_SERVER_ERROR"
+                },
+                "finished_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "report": {
+                    "description": "Report is set once the import is completed",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/client.ClientImportReportResponse"
+                        }
+                    ]
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "running",
+                        "completed",
+                        "failed"
+                    ],
+                    "example": "completed"
+                },
+                "total_rows": {
+                    "type": "integer",
+                    "example": 3
+                }
             }
         },
-        "/v1/client/{id}": {
-            "get": {
-                "description": "Retrieves a client's information based on the provided client ID.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Client"
-                ],
-                "summary": "Get client details by ID",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "example": "\"123e4567-e89b-12d3-a456-426614174000\"",
-                        "description": "Client ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Client details",
-                        "schema": {
-                            "$ref": "#/definitions/client.GetClientResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "{\"error\": \"bad request\"}",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "404": {
-                        "description": "{\"error\": \"not found\"}",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "422": {
-                        "description": "{\"error\": \"unprocessable entity\"}",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "500": {
-                        "description": "{\"error\": \"internal server error\"}",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    }
+        "client.ClientImportRowResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "invalid email"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "row": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "accepted",
+                        "duplicate",
+                        "invalid"
+                    ],
+                    "example": "accepted"
                 }
             }
-        }
-    },
-    "definitions": {
+        },
         "client.CreateClientReq": {
             "type": "object",
             "required": [
@@ -233,16 +1487,22 @@ const docTemplate = `{
             ],
             "properties": {
                 "date_of_birth": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "1990-01-01T00:00:00+00:00"
                 },
                 "email": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "john.doe@whalebone.io"
                 },
                 "id": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
                 },
                 "name": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 2,
+                    "example": "John Doe"
                 }
             }
         },
@@ -274,6 +1534,45 @@ const docTemplate = `{
                 }
             }
         },
+        "client.ListClientsResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/client.GetClientResponse"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "MTIz"
+                }
+            }
+        },
+        "client.UpdateClientReq": {
+            "type": "object",
+            "required": [
+                "date_of_birth",
+                "email",
+                "name"
+            ],
+            "properties": {
+                "date_of_birth": {
+                    "type": "string",
+                    "example": "1990-01-01T00:00:00+00:00"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "john.doe@whalebone.io"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 2,
+                    "example": "John Doe"
+                }
+            }
+        },
         "health.ComponentStatus": {
             "type": "object",
             "properties": {
@@ -281,6 +1580,10 @@ const docTemplate = `{
                     "type": "string",
                     "example": "main"
                 },
+                "details": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
                 "status": {
                     "enum": [
                         "up",
@@ -330,6 +1633,282 @@ const docTemplate = `{
                 "StatusDown",
                 "StatusTimeout"
             ]
+        },
+        "http.FieldViolation": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "enum": [
+                        "required",
+                        "invalid_format",
+                        "invalid_type",
+                        "too_short",
+                        "too_long",
+                        "invalid_characters",
+                        "in_future",
+                        "out_of_range",
+                        "not_allowed",
+                        "malformed"
+                    ],
+                    "example": "in_future"
+                },
+                "field": {
+                    "description": "Field is the path of the field in the request, it is empty when the whole request is malformed",
+                    "type": "string",
+                    "example": "date_of_birth"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "date of birth is in the future"
+                }
+            }
+        },
+        "http.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "client_not_found"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "client not found"
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/v1/client/123e4567-e89b-12d3-a456-426614174000"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "8e03978e-40d5-43e8-bc93-6894a57f9324"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Client not found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "urn:problem-type:client_not_found"
+                },
+                "violations": {
+                    "description": "Violations are set when the request is malformed or fails the validation",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.FieldViolation"
+                    }
+                }
+            }
+        },
+        "webhook.CreateWebhookReq": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "client.created",
+                        "client.updated"
+                    ]
+                },
+                "secret": {
+                    "description": "Secret is generated when empty",
+                    "type": "string",
+                    "example": "whsec_4f0c9b1e5a7d4c2e8b6a3f1d9e7c5b3a"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://partner.example.com/webhooks/clients"
+                }
+            }
+        },
+        "webhook.CreateWebhookResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "client.created",
+                        "client.updated"
+                    ]
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "secret": {
+                    "description": "Secret signs the deliveries, it is not returned anymore",
+                    "type": "string",
+                    "example": "whsec_4f0c9b1e5a7d4c2e8b6a3f1d9e7c5b3a"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://partner.example.com/webhooks/clients"
+                }
+            }
+        },
+        "webhook.ListWebhookDeliveriesResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/webhook.WebhookDeliveryResponse"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "MTIz"
+                }
+            }
+        },
+        "webhook.ListWebhooksResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/webhook.WebhookResponse"
+                    }
+                }
+            }
+        },
+        "webhook.UpdateWebhookReq": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "client.created",
+                        "client.updated"
+                    ]
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://partner.example.com/webhooks/clients"
+                }
+            }
+        },
+        "webhook.WebhookDeliveryResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "delivered_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "event_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "event_type": {
+                    "type": "string",
+                    "example": "client.created"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "last_error": {
+                    "type": "string",
+                    "example": "webhook responded with status 503"
+                },
+                "last_status_code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "next_attempt_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "status": {
+                    "description": "Status is pending, delivered or dead, dead deliveries are not attempted anymore",
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "delivered",
+                        "dead"
+                    ],
+                    "example": "delivered"
+                }
+            }
+        },
+        "webhook.WebhookResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "client.created",
+                        "client.updated"
+                    ]
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://partner.example.com/webhooks/clients"
+                }
+            }
         }
     }
 }`
@@ -341,7 +1920,7 @@ var SwaggerInfo = &swag.Spec{
 	BasePath:         "",
 	Schemes:          []string{},
 	Title:            "Whalebone Clients API",
-	Description:      "API provides endpoints for whalebone clients",
+	Description:      "API provides endpoints for whalebone clients\n\nErrors are returned as application/problem+json (RFC 7807) with a stable code, the type is urn:problem-type:<code>.\nMalformed requests and validation failures list the violations of the fields.\n\n| Code | Status | Title |\n| --- | --- | --- |\n| malformed_request | 400 | Malformed request |\n| invalid_parameters | 400 | Invalid parameters |\n| idempotency_key_too_long | 400 | Idempotency key is too long |\n| unreadable_body | 400 | Request body can't be read |\n| not_found | 404 | Not found |\n| client_not_found | 404 | Client not found |\n| client_import_not_found | 404 | Client import not found |\n| webhook_not_found | 404 | Webhook not found |\n| idempotency_key_reused | 409 | Idempotency key was reused |\n| idempotency_key_in_progress | 409 | Idempotency key is in progress |\n| client_version_mismatch | 412 | Client version mismatch |\n| payload_too_large | 413 | Payload too large |\n| unsupported_media_type | 415 | Unsupported media type |\n| validation_failed | 422 | Validation failed |\n| client_already_exists | 422 | Client already exists |\n| precondition_required | 428 | Precondition required |\n| internal_server_error | 500 | Internal server error |",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
@@ -0,0 +1,21 @@
+package audit
+
+import "context"
+
+// Meta tells who made a change of a client, it is stored with every client audit entry
+type Meta struct {
+	Actor     string
+	RequestID string
+}
+
+type metaKey struct{}
+
+func WithMeta(ctx context.Context, m Meta) context.Context {
+	return context.WithValue(ctx, metaKey{}, m)
+}
+
+// MetaFromContext returns empty Meta when the context carries none
+func MetaFromContext(ctx context.Context) Meta {
+	m, _ := ctx.Value(metaKey{}).(Meta)
+	return m
+}
@@ -1,5 +1,7 @@
 package error
 
+const ClientAlreadyExistsCode = "client_already_exists"
+
 type ClientAlreadyExists struct{}
 
 func NewClientAlreadyExists() *ClientAlreadyExists {
@@ -9,3 +11,7 @@ func NewClientAlreadyExists() *ClientAlreadyExists {
 func (e *ClientAlreadyExists) Error() string {
 	return "client already exists"
 }
+
+func (e *ClientAlreadyExists) Code() string {
+	return ClientAlreadyExistsCode
+}
@@ -0,0 +1,17 @@
+package error
+
+const ClientImportNotFoundCode = "client_import_not_found"
+
+type ClientImportNotFound struct{}
+
+func NewClientImportNotFound() *ClientImportNotFound {
+	return &ClientImportNotFound{}
+}
+
+func (e *ClientImportNotFound) Error() string {
+	return "client import not found"
+}
+
+func (e *ClientImportNotFound) Code() string {
+	return ClientImportNotFoundCode
+}
@@ -1,5 +1,7 @@
 package error
 
+const ClientNotFoundCode = "client_not_found"
+
 type ClientNotFound struct{}
 
 func NewClientNotFound() *ClientNotFound {
@@ -9,3 +11,7 @@ func NewClientNotFound() *ClientNotFound {
 func (e *ClientNotFound) Error() string {
 	return "client not found"
 }
+
+func (e *ClientNotFound) Code() string {
+	return ClientNotFoundCode
+}
@@ -0,0 +1,17 @@
+package error
+
+const ClientVersionMismatchCode = "client_version_mismatch"
+
+type ClientVersionMismatch struct{}
+
+func NewClientVersionMismatch() *ClientVersionMismatch {
+	return &ClientVersionMismatch{}
+}
+
+func (e *ClientVersionMismatch) Error() string {
+	return "client was modified by another request"
+}
+
+func (e *ClientVersionMismatch) Code() string {
+	return ClientVersionMismatchCode
+}
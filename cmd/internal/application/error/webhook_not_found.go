@@ -0,0 +1,17 @@
+package error
+
+const WebhookNotFoundCode = "webhook_not_found"
+
+type WebhookNotFound struct{}
+
+func NewWebhookNotFound() *WebhookNotFound {
+	return &WebhookNotFound{}
+}
+
+func (e *WebhookNotFound) Error() string {
+	return "webhook not found"
+}
+
+func (e *WebhookNotFound) Code() string {
+	return WebhookNotFoundCode
+}
@@ -0,0 +1,18 @@
+package handler
+
+// ClientEventTypes are the types of the client events published through the outbox
+var ClientEventTypes = []string{
+	ClientCreatedEvent,
+	ClientUpdatedEvent,
+	ClientDeletedEvent,
+	ClientRestoredEvent,
+	ClientPurgedEvent,
+}
+
+const (
+	ClientCreatedEvent  = "client.created"
+	ClientUpdatedEvent  = "client.updated"
+	ClientDeletedEvent  = "client.deleted"
+	ClientRestoredEvent = "client.restored"
+	ClientPurgedEvent   = "client.purged"
+)
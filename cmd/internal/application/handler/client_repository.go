@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ClientRepository covers every client persistence need of the handlers, it is implemented by Postgres and in memory.
+// A client is unique by its uuid until it is purged and a live client by its email, deleted clients don't take the email.
+type ClientRepository interface {
+	CreateClientOperation
+	GetClientOperation
+	UpdateClientOperation
+	DeleteClientOperation
+	ListClientsOperation
+	RestoreClientOperation
+	PurgeClientOperation
+	ImportClientsOperation
+	ExportClientsOperation
+	ListClientHistoryOperation
+}
+
+// ClientImportRepository keeps the scheduled client imports and is the store of the import job,
+// it is implemented by Postgres and in memory
+type ClientImportRepository interface {
+	ScheduleClientImportOperation
+	GetClientImportOperation
+	// Claim leases the oldest pending import, imports whose lease expired are claimed again
+	Claim(ctx context.Context, lease time.Duration) (ClientImportJobDTO, bool, error)
+	Complete(ctx context.Context, importUUID uuid.UUID, report ClientImportReportDTO) error
+	Fail(ctx context.Context, importUUID uuid.UUID, reason string) error
+}
@@ -15,7 +15,7 @@ type CreateClientDTO struct {
 }
 
 type CreateClientOperation interface {
-	Execute(ctx context.Context, p CreateClientDTO) error
+	Create(ctx context.Context, p CreateClientDTO) error
 }
 type CreateClientHandler struct {
 	createClient CreateClientOperation
@@ -25,8 +25,11 @@ func NewCreateClientHandler(createClient CreateClientOperation) *CreateClientHan
 	return &CreateClientHandler{createClient: createClient}
 }
 
-func (h *CreateClientHandler) Handle(ctx context.Context, p CreateClientDTO) error {
-	err := h.createClient.Execute(ctx, CreateClientDTO{
+func (h *CreateClientHandler) Handle(ctx context.Context, p CreateClientDTO) (err error) {
+	ctx, span := startSpan(ctx, "CreateClient")
+	defer endSpan(span, &err)
+
+	err = h.createClient.Create(ctx, CreateClientDTO{
 		Name:        p.Name,
 		Email:       p.Email,
 		ClientUUID:  p.ClientUUID,
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	webhookSecretPrefix = "whsec_"
+	webhookSecretBytes  = 32
+)
+
+type CreateWebhookDTO struct {
+	URL string
+	// Secret is generated when empty
+	Secret string
+	// EventTypes subscribes all client events when empty
+	EventTypes []string
+	Active     bool
+}
+
+type WebhookDTO struct {
+	WebhookUUID uuid.UUID
+	URL         string
+	Secret      string
+	EventTypes  []string
+	Active      bool
+	CreatedAt   time.Time
+}
+
+type CreateWebhookOperation interface {
+	Create(ctx context.Context, p WebhookDTO) (WebhookDTO, error)
+}
+
+type CreateWebhookHandler struct {
+	createWebhook CreateWebhookOperation
+}
+
+func NewCreateWebhookHandler(createWebhook CreateWebhookOperation) *CreateWebhookHandler {
+	return &CreateWebhookHandler{createWebhook: createWebhook}
+}
+
+// Handle returns the created webhook including the secret which is not returned anymore
+func (h *CreateWebhookHandler) Handle(ctx context.Context, p CreateWebhookDTO) (_ WebhookDTO, err error) {
+	ctx, span := startSpan(ctx, "CreateWebhook")
+	defer endSpan(span, &err)
+
+	secret := p.Secret
+	if secret == "" {
+		secret, err = generateWebhookSecret()
+		if err != nil {
+			return WebhookDTO{}, err
+		}
+	}
+
+	return h.createWebhook.Create(ctx, WebhookDTO{
+		WebhookUUID: uuid.New(),
+		URL:         p.URL,
+		Secret:      secret,
+		EventTypes:  p.EventTypes,
+		Active:      p.Active,
+	})
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return webhookSecretPrefix + hex.EncodeToString(b), nil
+}
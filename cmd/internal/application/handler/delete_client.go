@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type DeleteClientDTO struct {
+	ClientUUID uuid.UUID
+	// ExpectedVersions must contain the current version of the client, nil matches any version
+	ExpectedVersions []int64
+}
+
+type DeleteClientOperation interface {
+	Delete(ctx context.Context, p DeleteClientDTO) error
+}
+
+type DeleteClientHandler struct {
+	deleteClient DeleteClientOperation
+}
+
+func NewDeleteClientHandler(deleteClient DeleteClientOperation) *DeleteClientHandler {
+	return &DeleteClientHandler{deleteClient: deleteClient}
+}
+
+func (h *DeleteClientHandler) Handle(ctx context.Context, p DeleteClientDTO) (err error) {
+	ctx, span := startSpan(ctx, "DeleteClient")
+	defer endSpan(span, &err)
+
+	return h.deleteClient.Delete(ctx, DeleteClientDTO{
+		ClientUUID:       p.ClientUUID,
+		ExpectedVersions: p.ExpectedVersions,
+	})
+}
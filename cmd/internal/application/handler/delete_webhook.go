@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type DeleteWebhookOperation interface {
+	DeleteForUUID(ctx context.Context, webhookUUID uuid.UUID) error
+}
+
+type DeleteWebhookHandler struct {
+	deleteWebhook DeleteWebhookOperation
+}
+
+func NewDeleteWebhookHandler(deleteWebhook DeleteWebhookOperation) *DeleteWebhookHandler {
+	return &DeleteWebhookHandler{deleteWebhook: deleteWebhook}
+}
+
+// Handle removes the webhook together with its delivery log
+func (h *DeleteWebhookHandler) Handle(ctx context.Context, webhookUUID uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "DeleteWebhook")
+	defer endSpan(span, &err)
+
+	return h.deleteWebhook.DeleteForUUID(ctx, webhookUUID)
+}
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"context"
+)
+
+type ExportClientsOperation interface {
+	// Export calls f for every client matching the filter ordered by creation, it stops at the first error of f
+	Export(ctx context.Context, filter ClientFilter, f func(client GetClientDTO) error) error
+}
+
+type ExportClientsHandler struct {
+	exportClients ExportClientsOperation
+}
+
+func NewExportClientsHandler(exportClients ExportClientsOperation) ExportClientsHandler {
+	return ExportClientsHandler{
+		exportClients: exportClients,
+	}
+}
+
+func (h ExportClientsHandler) Handle(ctx context.Context, filter ClientFilter, f func(client GetClientDTO) error) (err error) {
+	ctx, span := startSpan(ctx, "ExportClients")
+	defer endSpan(span, &err)
+
+	return h.exportClients.Export(ctx, filter, f)
+}
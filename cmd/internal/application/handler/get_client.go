@@ -11,12 +11,27 @@ type GetClientDTO struct {
 	Email       string
 	DateOfBirth string
 	ClientUUID  uuid.UUID
+	// Version changes on every modification of the client
+	Version int64
 }
 
 type GetClientOperation interface {
 	GetForUUID(ctx context.Context, clientUUID uuid.UUID) (GetClientDTO, error)
 }
 
+type latestReadKey struct{}
+
+// ReadLatest marks the read of the context as the base of a change, e.g. the client a patch is merged into,
+// it must see the latest version so the operations don't serve it from a replica which may lag behind
+func ReadLatest(ctx context.Context) context.Context {
+	return context.WithValue(ctx, latestReadKey{}, true)
+}
+
+func ReadsLatest(ctx context.Context) bool {
+	latest, _ := ctx.Value(latestReadKey{}).(bool)
+	return latest
+}
+
 type GetClientHandler struct {
 	getClient GetClientOperation
 }
@@ -27,6 +42,9 @@ func NewGetClientHandler(getClient GetClientOperation) GetClientHandler {
 	}
 }
 
-func (h GetClientHandler) Handle(ctx context.Context, clientUUID uuid.UUID) (GetClientDTO, error) {
+func (h GetClientHandler) Handle(ctx context.Context, clientUUID uuid.UUID) (_ GetClientDTO, err error) {
+	ctx, span := startSpan(ctx, "GetClient")
+	defer endSpan(span, &err)
+
 	return h.getClient.GetForUUID(ctx, clientUUID)
 }
@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type GetClientImportOperation interface {
+	GetForUUID(ctx context.Context, importUUID uuid.UUID) (ClientImportDTO, error)
+}
+
+type GetClientImportHandler struct {
+	getClientImport GetClientImportOperation
+}
+
+func NewGetClientImportHandler(getClientImport GetClientImportOperation) GetClientImportHandler {
+	return GetClientImportHandler{
+		getClientImport: getClientImport,
+	}
+}
+
+func (h GetClientImportHandler) Handle(ctx context.Context, importUUID uuid.UUID) (_ ClientImportDTO, err error) {
+	ctx, span := startSpan(ctx, "GetClientImport")
+	defer endSpan(span, &err)
+
+	return h.getClientImport.GetForUUID(ctx, importUUID)
+}
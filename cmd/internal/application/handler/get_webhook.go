@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type GetWebhookOperation interface {
+	GetForUUID(ctx context.Context, webhookUUID uuid.UUID) (WebhookDTO, error)
+}
+
+type GetWebhookHandler struct {
+	getWebhook GetWebhookOperation
+}
+
+func NewGetWebhookHandler(getWebhook GetWebhookOperation) GetWebhookHandler {
+	return GetWebhookHandler{
+		getWebhook: getWebhook,
+	}
+}
+
+func (h GetWebhookHandler) Handle(ctx context.Context, webhookUUID uuid.UUID) (_ WebhookDTO, err error) {
+	ctx, span := startSpan(ctx, "GetWebhook")
+	defer endSpan(span, &err)
+
+	return h.getWebhook.GetForUUID(ctx, webhookUUID)
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ClientImportRowAccepted  = "accepted"
+	ClientImportRowDuplicate = "duplicate"
+	ClientImportRowInvalid   = "invalid"
+)
+
+// importClientsBatchSize is the number of clients imported in one transaction
+const importClientsBatchSize = 1000
+
+// ClientImportRow is one row of the import, Error is set when the row did not pass the validation
+type ClientImportRow struct {
+	// Row is the 1-based position of the row in the import
+	Row int
+	// ID is the client id as it was imported, it is kept for the report of invalid rows
+	ID     string
+	Client CreateClientDTO
+	Error  string
+}
+
+type ClientImportRowResultDTO struct {
+	Row    int
+	ID     string
+	Status string
+	Error  string
+}
+
+type ClientImportReportDTO struct {
+	Total     int
+	Accepted  int
+	Duplicate int
+	Invalid   int
+	Rows      []ClientImportRowResultDTO
+}
+
+type ImportClientsOperation interface {
+	// Import skips the clients which already exist and returns the ids of the imported ones
+	Import(ctx context.Context, clients []CreateClientDTO) ([]uuid.UUID, error)
+}
+
+type ImportClientsHandler struct {
+	importClients ImportClientsOperation
+}
+
+func NewImportClientsHandler(importClients ImportClientsOperation) ImportClientsHandler {
+	return ImportClientsHandler{
+		importClients: importClients,
+	}
+}
+
+// Handle imports the valid rows in batches, a row repeating the id or the email of a previous row is a duplicate
+func (h ImportClientsHandler) Handle(ctx context.Context, rows []ClientImportRow) (_ ClientImportReportDTO, err error) {
+	ctx, span := startSpan(ctx, "ImportClients")
+	defer endSpan(span, &err)
+
+	report := ClientImportReportDTO{
+		Total: len(rows),
+		Rows:  make([]ClientImportRowResultDTO, len(rows)),
+	}
+
+	seenUUIDs := make(map[uuid.UUID]bool, len(rows))
+	seenEmails := make(map[string]bool, len(rows))
+	batch := make([]int, 0, importClientsBatchSize)
+	for i, row := range rows {
+		report.Rows[i] = ClientImportRowResultDTO{
+			Row: row.Row,
+			ID:  row.ID,
+		}
+
+		switch {
+		case row.Error != "":
+			report.Rows[i].Status = ClientImportRowInvalid
+			report.Rows[i].Error = row.Error
+		case seenUUIDs[row.Client.ClientUUID] || seenEmails[row.Client.Email]:
+			report.Rows[i].Status = ClientImportRowDuplicate
+			report.Rows[i].Error = "client is repeated in the import"
+		default:
+			seenUUIDs[row.Client.ClientUUID] = true
+			seenEmails[row.Client.Email] = true
+			batch = append(batch, i)
+		}
+
+		if len(batch) == importClientsBatchSize || (i == len(rows)-1 && len(batch) > 0) {
+			err := h.importBatch(ctx, rows, batch, &report)
+			if err != nil {
+				return ClientImportReportDTO{}, err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	for _, row := range report.Rows {
+		switch row.Status {
+		case ClientImportRowAccepted:
+			report.Accepted++
+		case ClientImportRowDuplicate:
+			report.Duplicate++
+		case ClientImportRowInvalid:
+			report.Invalid++
+		}
+	}
+
+	return report, nil
+}
+
+func (h ImportClientsHandler) importBatch(ctx context.Context, rows []ClientImportRow, batch []int, report *ClientImportReportDTO) error {
+	clients := make([]CreateClientDTO, 0, len(batch))
+	for _, i := range batch {
+		clients = append(clients, rows[i].Client)
+	}
+
+	imported, err := h.importClients.Import(ctx, clients)
+	if err != nil {
+		return err
+	}
+
+	importedUUIDs := make(map[uuid.UUID]bool, len(imported))
+	for _, clientUUID := range imported {
+		importedUUIDs[clientUUID] = true
+	}
+
+	for _, i := range batch {
+		if importedUUIDs[rows[i].Client.ClientUUID] {
+			report.Rows[i].Status = ClientImportRowAccepted
+		} else {
+			report.Rows[i].Status = ClientImportRowDuplicate
+			report.Rows[i].Error = "client already exists"
+		}
+	}
+
+	return nil
+}
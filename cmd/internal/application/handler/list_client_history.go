@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ListClientHistoryQuery struct {
+	ClientUUID uuid.UUID
+	// AfterID is the keyset cursor, only entries with greater id are listed
+	AfterID int64
+	Limit   int
+}
+
+type ClientHistoryEntryDTO struct {
+	// Action is one of create, update, delete, restore and purge
+	Action string
+	// Before is nil for create, After is nil for purge
+	Before    json.RawMessage
+	After     json.RawMessage
+	Actor     string
+	RequestID string
+	CreatedAt time.Time
+}
+
+type ClientHistoryDTO struct {
+	Entries []ClientHistoryEntryDTO
+	// NextAfterID is the cursor of the next page, zero when there are no more entries
+	NextAfterID int64
+}
+
+// ListClientHistoryOperation returns ClientNotFound for the first page of a client which never existed,
+// a purged client keeps its history
+type ListClientHistoryOperation interface {
+	ListHistory(ctx context.Context, q ListClientHistoryQuery) (ClientHistoryDTO, error)
+}
+
+type ListClientHistoryHandler struct {
+	listClientHistory ListClientHistoryOperation
+}
+
+func NewListClientHistoryHandler(listClientHistory ListClientHistoryOperation) ListClientHistoryHandler {
+	return ListClientHistoryHandler{
+		listClientHistory: listClientHistory,
+	}
+}
+
+func (h ListClientHistoryHandler) Handle(ctx context.Context, q ListClientHistoryQuery) (_ ClientHistoryDTO, err error) {
+	ctx, span := startSpan(ctx, "ListClientHistory")
+	defer endSpan(span, &err)
+
+	return h.listClientHistory.ListHistory(ctx, q)
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"context"
+	"time"
+)
+
+type ClientFilter struct {
+	EmailDomain     string
+	NamePrefix      string
+	DateOfBirthFrom *time.Time
+	DateOfBirthTo   *time.Time
+	CreatedAtFrom   *time.Time
+	CreatedAtTo     *time.Time
+}
+
+type ListClientsQuery struct {
+	Filter ClientFilter
+	// AfterID is the keyset cursor, only clients with greater id are listed
+	AfterID int64
+	Limit   int
+}
+
+type ListClientsDTO struct {
+	Clients []GetClientDTO
+	// NextAfterID is the cursor of the next page, zero when there are no more clients
+	NextAfterID int64
+}
+
+type ListClientsOperation interface {
+	List(ctx context.Context, q ListClientsQuery) (ListClientsDTO, error)
+}
+
+type ListClientsHandler struct {
+	listClients ListClientsOperation
+}
+
+func NewListClientsHandler(listClients ListClientsOperation) ListClientsHandler {
+	return ListClientsHandler{
+		listClients: listClients,
+	}
+}
+
+func (h ListClientsHandler) Handle(ctx context.Context, q ListClientsQuery) (_ ListClientsDTO, err error) {
+	ctx, span := startSpan(ctx, "ListClients")
+	defer endSpan(span, &err)
+
+	return h.listClients.List(ctx, q)
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	// WebhookDeliveryDead is not attempted anymore
+	WebhookDeliveryDead = "dead"
+)
+
+type ListWebhookDeliveriesQuery struct {
+	WebhookUUID uuid.UUID
+	// BeforeID is the keyset cursor, only deliveries with lower id are listed, zero lists from the newest
+	BeforeID int64
+	Limit    int
+}
+
+type WebhookDeliveryDTO struct {
+	ID             int64
+	EventID        int64
+	EventType      string
+	Status         string
+	Attempts       int
+	LastStatusCode *int
+	LastError      string
+	CreatedAt      time.Time
+	NextAttemptAt  time.Time
+	DeliveredAt    *time.Time
+}
+
+type WebhookDeliveriesDTO struct {
+	Deliveries []WebhookDeliveryDTO
+	// NextBeforeID is the cursor of the next page, zero when there are no more deliveries
+	NextBeforeID int64
+}
+
+type ListWebhookDeliveriesOperation interface {
+	List(ctx context.Context, q ListWebhookDeliveriesQuery) (WebhookDeliveriesDTO, error)
+}
+
+type ListWebhookDeliveriesHandler struct {
+	getWebhook            GetWebhookOperation
+	listWebhookDeliveries ListWebhookDeliveriesOperation
+}
+
+func NewListWebhookDeliveriesHandler(
+	getWebhook GetWebhookOperation,
+	listWebhookDeliveries ListWebhookDeliveriesOperation,
+) ListWebhookDeliveriesHandler {
+	return ListWebhookDeliveriesHandler{
+		getWebhook:            getWebhook,
+		listWebhookDeliveries: listWebhookDeliveries,
+	}
+}
+
+// Handle returns the newest deliveries first, it fails with WebhookNotFound for unknown webhook
+func (h ListWebhookDeliveriesHandler) Handle(ctx context.Context, q ListWebhookDeliveriesQuery) (_ WebhookDeliveriesDTO, err error) {
+	ctx, span := startSpan(ctx, "ListWebhookDeliveries")
+	defer endSpan(span, &err)
+
+	_, err = h.getWebhook.GetForUUID(ctx, q.WebhookUUID)
+	if err != nil {
+		return WebhookDeliveriesDTO{}, err
+	}
+
+	return h.listWebhookDeliveries.List(ctx, q)
+}
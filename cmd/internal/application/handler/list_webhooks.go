@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"context"
+)
+
+type ListWebhooksOperation interface {
+	List(ctx context.Context) ([]WebhookDTO, error)
+}
+
+type ListWebhooksHandler struct {
+	listWebhooks ListWebhooksOperation
+}
+
+func NewListWebhooksHandler(listWebhooks ListWebhooksOperation) ListWebhooksHandler {
+	return ListWebhooksHandler{
+		listWebhooks: listWebhooks,
+	}
+}
+
+func (h ListWebhooksHandler) Handle(ctx context.Context) (_ []WebhookDTO, err error) {
+	ctx, span := startSpan(ctx, "ListWebhooks")
+	defer endSpan(span, &err)
+
+	return h.listWebhooks.List(ctx)
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PurgeClientOperation interface {
+	PurgeForUUID(ctx context.Context, clientUUID uuid.UUID) error
+	PurgeDeletedOlderThan(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+type PurgeClientHandler struct {
+	purgeClient PurgeClientOperation
+}
+
+func NewPurgeClientHandler(purgeClient PurgeClientOperation) *PurgeClientHandler {
+	return &PurgeClientHandler{purgeClient: purgeClient}
+}
+
+// Handle permanently removes the soft deleted client
+func (h *PurgeClientHandler) Handle(ctx context.Context, clientUUID uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "PurgeClient")
+	defer endSpan(span, &err)
+
+	return h.purgeClient.PurgeForUUID(ctx, clientUUID)
+}
+
+// HandleDeletedOlderThan permanently removes all clients soft deleted longer than the retention ago
+func (h *PurgeClientHandler) HandleDeletedOlderThan(ctx context.Context, retention time.Duration) (int64, error) {
+	return h.purgeClient.PurgeDeletedOlderThan(ctx, retention)
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type RestoreClientOperation interface {
+	RestoreForUUID(ctx context.Context, clientUUID uuid.UUID) error
+}
+
+type RestoreClientHandler struct {
+	restoreClient RestoreClientOperation
+}
+
+func NewRestoreClientHandler(restoreClient RestoreClientOperation) *RestoreClientHandler {
+	return &RestoreClientHandler{restoreClient: restoreClient}
+}
+
+func (h *RestoreClientHandler) Handle(ctx context.Context, clientUUID uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "RestoreClient")
+	defer endSpan(span, &err)
+
+	return h.restoreClient.RestoreForUUID(ctx, clientUUID)
+}
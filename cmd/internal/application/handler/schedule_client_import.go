@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/audit"
+)
+
+const (
+	ClientImportPending   = "pending"
+	ClientImportRunning   = "running"
+	ClientImportCompleted = "completed"
+	ClientImportFailed    = "failed"
+)
+
+type ClientImportDTO struct {
+	ImportUUID uuid.UUID
+	// Status is one of pending, running, completed and failed
+	Status     string
+	TotalRows  int
+	CreatedAt  time.Time
+	FinishedAt *time.Time
+	// Report is set once the import is completed, Error once it failed
+	Report *ClientImportReportDTO
+	Error  string
+}
+
+// ClientImportJobDTO is the import waiting for the import job, Meta is recorded in the client audit
+type ClientImportJobDTO struct {
+	ImportUUID uuid.UUID
+	Rows       []ClientImportRow
+	Meta       audit.Meta
+}
+
+type ScheduleClientImportOperation interface {
+	Create(ctx context.Context, job ClientImportJobDTO) (ClientImportDTO, error)
+}
+
+type ScheduleClientImportHandler struct {
+	scheduleClientImport ScheduleClientImportOperation
+}
+
+func NewScheduleClientImportHandler(scheduleClientImport ScheduleClientImportOperation) ScheduleClientImportHandler {
+	return ScheduleClientImportHandler{
+		scheduleClientImport: scheduleClientImport,
+	}
+}
+
+// Handle stores the rows to be imported by the import job on behalf of the audit meta of the context
+func (h ScheduleClientImportHandler) Handle(ctx context.Context, rows []ClientImportRow) (_ ClientImportDTO, err error) {
+	ctx, span := startSpan(ctx, "ScheduleClientImport")
+	defer endSpan(span, &err)
+
+	return h.scheduleClientImport.Create(ctx, ClientImportJobDTO{
+		ImportUUID: uuid.New(),
+		Rows:       rows,
+		Meta:       audit.MetaFromContext(ctx),
+	})
+}
@@ -0,0 +1,8 @@
+package handler
+
+import "context"
+
+// UnitOfWork runs f atomically, the operations executed with the context passed to f commit or roll back together
+type UnitOfWork interface {
+	Run(ctx context.Context, name string, f func(ctx context.Context) error) error
+}
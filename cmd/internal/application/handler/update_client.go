@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UpdateClientDTO struct {
+	Name        string
+	Email       string
+	DateOfBirth time.Time
+	ClientUUID  uuid.UUID
+	// ExpectedVersions must contain the current version of the client, nil matches any version
+	ExpectedVersions []int64
+}
+
+type UpdateClientOperation interface {
+	Update(ctx context.Context, p UpdateClientDTO) (int64, error)
+}
+
+type UpdateClientHandler struct {
+	updateClient UpdateClientOperation
+}
+
+func NewUpdateClientHandler(updateClient UpdateClientOperation) *UpdateClientHandler {
+	return &UpdateClientHandler{updateClient: updateClient}
+}
+
+// Handle returns the new version of the client
+func (h *UpdateClientHandler) Handle(ctx context.Context, p UpdateClientDTO) (_ int64, err error) {
+	ctx, span := startSpan(ctx, "UpdateClient")
+	defer endSpan(span, &err)
+
+	return h.updateClient.Update(ctx, UpdateClientDTO{
+		Name:             p.Name,
+		Email:            p.Email,
+		ClientUUID:       p.ClientUUID,
+		DateOfBirth:      p.DateOfBirth,
+		ExpectedVersions: p.ExpectedVersions,
+	})
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type UpdateWebhookDTO struct {
+	WebhookUUID uuid.UUID
+	URL         string
+	EventTypes  []string
+	Active      bool
+}
+
+type UpdateWebhookOperation interface {
+	Update(ctx context.Context, p UpdateWebhookDTO) error
+}
+
+type UpdateWebhookHandler struct {
+	updateWebhook UpdateWebhookOperation
+}
+
+func NewUpdateWebhookHandler(updateWebhook UpdateWebhookOperation) *UpdateWebhookHandler {
+	return &UpdateWebhookHandler{updateWebhook: updateWebhook}
+}
+
+func (h *UpdateWebhookHandler) Handle(ctx context.Context, p UpdateWebhookDTO) (err error) {
+	ctx, span := startSpan(ctx, "UpdateWebhook")
+	defer endSpan(span, &err)
+
+	return h.updateWebhook.Update(ctx, UpdateWebhookDTO{
+		WebhookUUID: p.WebhookUUID,
+		URL:         p.URL,
+		EventTypes:  p.EventTypes,
+		Active:      p.Active,
+	})
+}
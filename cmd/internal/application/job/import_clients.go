@@ -0,0 +1,114 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/audit"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/correlation"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
+)
+
+type ImportClientsHandler interface {
+	Handle(ctx context.Context, rows []handler.ClientImportRow) (handler.ClientImportReportDTO, error)
+}
+
+type ClientImportStore interface {
+	// Claim leases the oldest pending import, imports whose lease expired are claimed again
+	Claim(ctx context.Context, lease time.Duration) (handler.ClientImportJobDTO, bool, error)
+	Complete(ctx context.Context, importUUID uuid.UUID, report handler.ClientImportReportDTO) error
+	Fail(ctx context.Context, importUUID uuid.UUID, reason string) error
+}
+
+// ImportClients periodically runs the scheduled client imports one by one
+type ImportClients struct {
+	handler  ImportClientsHandler
+	store    ClientImportStore
+	interval time.Duration
+	// lease has to be longer than the longest import, an import running longer is started again by another instance
+	lease time.Duration
+	lg    logger.Logger
+}
+
+func NewImportClients(
+	handler ImportClientsHandler,
+	store ClientImportStore,
+	interval time.Duration,
+	lease time.Duration,
+	lg logger.Logger,
+) *ImportClients {
+	return &ImportClients{
+		handler:  handler,
+		store:    store,
+		interval: interval,
+		lease:    lease,
+		lg:       lg,
+	}
+}
+
+// Run blocks until the context is done
+func (j *ImportClients) Run(ctx context.Context) {
+	runEvery(ctx, j.interval, j.importPending)
+}
+
+func (j *ImportClients) importPending(ctx context.Context) {
+	for ctx.Err() == nil {
+		clientImport, ok, err := j.store.Claim(ctx, j.lease)
+		if err != nil {
+			j.lg.ErrorWithMetadata("unable to claim client import", map[string]any{
+				"error": err.Error(),
+			})
+			return
+		}
+		if !ok {
+			return
+		}
+
+		j.importClients(ctx, clientImport)
+	}
+}
+
+func (j *ImportClients) importClients(ctx context.Context, clientImport handler.ClientImportJobDTO) {
+	// the import is correlated with the request which scheduled it
+	ctx = correlation.WithIDs(ctx, correlation.IDs{RequestID: clientImport.Meta.RequestID})
+	lg := j.lg.WithContext(ctx)
+
+	report, err := j.handler.Handle(audit.WithMeta(ctx, clientImport.Meta), clientImport.Rows)
+	if ctx.Err() != nil {
+		// the import is claimed again once the lease expires
+		return
+	}
+	if err != nil {
+		lg.ErrorWithMetadata("client import failed", map[string]any{
+			"error": err.Error(),
+			"id":    clientImport.ImportUUID.String(),
+		})
+
+		err = j.store.Fail(ctx, clientImport.ImportUUID, err.Error())
+		if err != nil {
+			lg.ErrorWithMetadata("unable to fail client import", map[string]any{
+				"error": err.Error(),
+				"id":    clientImport.ImportUUID.String(),
+			})
+		}
+		return
+	}
+
+	err = j.store.Complete(ctx, clientImport.ImportUUID, report)
+	if err != nil {
+		lg.ErrorWithMetadata("unable to complete client import", map[string]any{
+			"error": err.Error(),
+			"id":    clientImport.ImportUUID.String(),
+		})
+		return
+	}
+
+	lg.InfoWithMetadata("clients imported", map[string]any{
+		"id":        clientImport.ImportUUID.String(),
+		"accepted":  report.Accepted,
+		"duplicate": report.Duplicate,
+		"invalid":   report.Invalid,
+	})
+}
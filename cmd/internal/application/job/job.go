@@ -0,0 +1,22 @@
+package job
+
+import (
+	"context"
+	"time"
+)
+
+// runEvery runs f immediately and then every interval until the context is done
+func runEvery(ctx context.Context, interval time.Duration, f func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		f(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
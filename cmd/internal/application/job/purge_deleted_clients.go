@@ -0,0 +1,62 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/audit"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
+)
+
+// purgeDeletedClientsActor is recorded in the client audit as the actor of the scheduled purge
+const purgeDeletedClientsActor = "job:purge_deleted_clients"
+
+type PurgeDeletedClientsHandler interface {
+	HandleDeletedOlderThan(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+// PurgeDeletedClients periodically removes clients which were soft deleted longer than the retention ago
+type PurgeDeletedClients struct {
+	handler   PurgeDeletedClientsHandler
+	retention time.Duration
+	interval  time.Duration
+	lg        logger.Logger
+}
+
+func NewPurgeDeletedClients(
+	handler PurgeDeletedClientsHandler,
+	retention time.Duration,
+	interval time.Duration,
+	lg logger.Logger,
+) *PurgeDeletedClients {
+	return &PurgeDeletedClients{
+		handler:   handler,
+		retention: retention,
+		interval:  interval,
+		lg:        lg,
+	}
+}
+
+// Run blocks until the context is done
+func (j *PurgeDeletedClients) Run(ctx context.Context) {
+	runEvery(ctx, j.interval, j.purge)
+}
+
+func (j *PurgeDeletedClients) purge(ctx context.Context) {
+	ctx = audit.WithMeta(ctx, audit.Meta{Actor: purgeDeletedClientsActor})
+
+	purged, err := j.handler.HandleDeletedOlderThan(ctx, j.retention)
+	if err != nil {
+		j.lg.ErrorWithMetadata("purge of deleted clients failed", map[string]any{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if purged > 0 {
+		j.lg.InfoWithMetadata("deleted clients purged", map[string]any{
+			"count":     purged,
+			"retention": j.retention.String(),
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
+)
+
+type ExpiredIdempotencyKeysPurger interface {
+	PurgeExpired(ctx context.Context) (int64, error)
+}
+
+// PurgeExpiredIdempotencyKeys periodically removes idempotency keys after their TTL
+type PurgeExpiredIdempotencyKeys struct {
+	purger   ExpiredIdempotencyKeysPurger
+	interval time.Duration
+	lg       logger.Logger
+}
+
+func NewPurgeExpiredIdempotencyKeys(
+	purger ExpiredIdempotencyKeysPurger,
+	interval time.Duration,
+	lg logger.Logger,
+) *PurgeExpiredIdempotencyKeys {
+	return &PurgeExpiredIdempotencyKeys{
+		purger:   purger,
+		interval: interval,
+		lg:       lg,
+	}
+}
+
+// Run blocks until the context is done
+func (j *PurgeExpiredIdempotencyKeys) Run(ctx context.Context) {
+	runEvery(ctx, j.interval, j.purge)
+}
+
+func (j *PurgeExpiredIdempotencyKeys) purge(ctx context.Context) {
+	purged, err := j.purger.PurgeExpired(ctx)
+	if err != nil {
+		j.lg.ErrorWithMetadata("purge of expired idempotency keys failed", map[string]any{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if purged > 0 {
+		j.lg.InfoWithMetadata("expired idempotency keys purged", map[string]any{
+			"count": purged,
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/error"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+)
+
+type clientImport struct {
+	dto handler.ClientImportDTO
+	// job is kept until the import is finished
+	job         handler.ClientImportJobDTO
+	lockedUntil time.Time
+}
+
+// ClientImportRepository keeps the scheduled client imports in memory the way the client_import table does
+type ClientImportRepository struct {
+	mu sync.Mutex
+	// imports are ordered by the time they were scheduled
+	imports []*clientImport
+}
+
+func NewClientImportRepository() *ClientImportRepository {
+	return &ClientImportRepository{}
+}
+
+func (r *ClientImportRepository) Create(_ context.Context, job handler.ClientImportJobDTO) (handler.ClientImportDTO, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := &clientImport{
+		dto: handler.ClientImportDTO{
+			ImportUUID: job.ImportUUID,
+			Status:     handler.ClientImportPending,
+			TotalRows:  len(job.Rows),
+			CreatedAt:  time.Now().UTC(),
+		},
+		job: job,
+	}
+	r.imports = append(r.imports, i)
+
+	return i.dto, nil
+}
+
+func (r *ClientImportRepository) GetForUUID(_ context.Context, importUUID uuid.UUID) (handler.ClientImportDTO, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.find(importUUID)
+	if i == nil {
+		return handler.ClientImportDTO{}, apperror.NewClientImportNotFound()
+	}
+
+	return i.dto, nil
+}
+
+func (r *ClientImportRepository) Claim(_ context.Context, lease time.Duration) (handler.ClientImportJobDTO, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, i := range r.imports {
+		if i.dto.Status != handler.ClientImportPending && (i.dto.Status != handler.ClientImportRunning || !i.lockedUntil.Before(now)) {
+			continue
+		}
+
+		i.dto.Status = handler.ClientImportRunning
+		i.lockedUntil = now.Add(lease)
+
+		return i.job, true, nil
+	}
+
+	return handler.ClientImportJobDTO{}, false, nil
+}
+
+func (r *ClientImportRepository) Complete(_ context.Context, importUUID uuid.UUID, report handler.ClientImportReportDTO) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.find(importUUID)
+	if i == nil {
+		return apperror.NewClientImportNotFound()
+	}
+
+	report.Rows = slices.Clone(report.Rows)
+	i.dto.Report = &report
+	r.finish(i, handler.ClientImportCompleted)
+
+	return nil
+}
+
+func (r *ClientImportRepository) Fail(_ context.Context, importUUID uuid.UUID, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.find(importUUID)
+	if i == nil {
+		return apperror.NewClientImportNotFound()
+	}
+
+	i.dto.Error = reason
+	r.finish(i, handler.ClientImportFailed)
+
+	return nil
+}
+
+func (r *ClientImportRepository) find(importUUID uuid.UUID) *clientImport {
+	for _, i := range r.imports {
+		if i.dto.ImportUUID == importUUID {
+			return i
+		}
+	}
+
+	return nil
+}
+
+// finish drops the rows of the import like the client_import table does
+func (r *ClientImportRepository) finish(i *clientImport, status string) {
+	finishedAt := time.Now().UTC()
+	i.dto.Status = status
+	i.dto.FinishedAt = &finishedAt
+	i.job.Rows = nil
+	i.lockedUntil = time.Time{}
+}
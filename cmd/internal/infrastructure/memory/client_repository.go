@@ -0,0 +1,427 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/audit"
+	apperror "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/error"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+)
+
+const (
+	dateOfBirthLayout = "2006-01-02T15:04:05-07:00"
+	// snapshotLayout is the layout of a TIMESTAMP in JSONB
+	snapshotLayout = "2006-01-02T15:04:05.999999"
+)
+
+// the history actions of the client_audit trigger
+const (
+	actionCreate  = "create"
+	actionUpdate  = "update"
+	actionDelete  = "delete"
+	actionRestore = "restore"
+	actionPurge   = "purge"
+)
+
+type client struct {
+	id          int64
+	uuid        uuid.UUID
+	name        string
+	email       string
+	dateOfBirth time.Time
+	createdAt   time.Time
+	deletedAt   *time.Time
+	version     int64
+}
+
+func (c *client) dto() handler.GetClientDTO {
+	return handler.GetClientDTO{
+		Name:        c.name,
+		Email:       c.email,
+		ClientUUID:  c.uuid,
+		DateOfBirth: c.dateOfBirth.Format(dateOfBirthLayout),
+		Version:     c.version,
+	}
+}
+
+// clientSnapshot is the client as the client_audit trigger records it
+type clientSnapshot struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Email       string    `json:"email"`
+	DateOfBirth string    `json:"date_of_birth"`
+	DeletedAt   *string   `json:"deleted_at"`
+}
+
+func (c *client) snapshot() json.RawMessage {
+	s := clientSnapshot{
+		ID:          c.uuid,
+		Name:        c.name,
+		Email:       c.email,
+		DateOfBirth: c.dateOfBirth.Format(snapshotLayout),
+	}
+	if c.deletedAt != nil {
+		deletedAt := c.deletedAt.Format(snapshotLayout)
+		s.DeletedAt = &deletedAt
+	}
+
+	// the snapshot has no value which could fail to marshal
+	b, _ := json.Marshal(s)
+
+	return b
+}
+
+type historyEntry struct {
+	id         int64
+	clientUUID uuid.UUID
+	entry      handler.ClientHistoryEntryDTO
+}
+
+// ClientRepository keeps the clients in memory the way the client table does, the changes are audited
+// the way the client_audit trigger does but they are not outboxed
+type ClientRepository struct {
+	mu sync.RWMutex
+	// clients are ordered by id, the deleted ones are kept until they are purged
+	clients     []*client
+	lastID      int64
+	lastVersion int64
+	// history is ordered by id, it is kept after the client is purged
+	history       []historyEntry
+	lastHistoryID int64
+}
+
+func NewClientRepository() *ClientRepository {
+	return &ClientRepository{}
+}
+
+func (r *ClientRepository) Create(ctx context.Context, p handler.CreateClientDTO) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.taken(nil, p.ClientUUID, p.Email) {
+		return apperror.NewClientAlreadyExists()
+	}
+	r.insert(ctx, p)
+
+	return nil
+}
+
+func (r *ClientRepository) GetForUUID(_ context.Context, clientUUID uuid.UUID) (handler.GetClientDTO, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c := r.live(clientUUID)
+	if c == nil {
+		return handler.GetClientDTO{}, apperror.NewClientNotFound()
+	}
+
+	return c.dto(), nil
+}
+
+func (r *ClientRepository) Update(ctx context.Context, p handler.UpdateClientDTO) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := r.live(p.ClientUUID)
+	if c == nil {
+		return 0, apperror.NewClientNotFound()
+	}
+	if !versionMatches(c, p.ExpectedVersions) {
+		return 0, apperror.NewClientVersionMismatch()
+	}
+	if r.taken(c, p.ClientUUID, p.Email) {
+		return 0, apperror.NewClientAlreadyExists()
+	}
+
+	before := c.snapshot()
+	c.email = p.Email
+	c.name = p.Name
+	c.dateOfBirth = timestamp(p.DateOfBirth)
+	c.version = r.nextVersion()
+	r.record(ctx, actionUpdate, c, before)
+
+	return c.version, nil
+}
+
+func (r *ClientRepository) Delete(ctx context.Context, p handler.DeleteClientDTO) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := r.live(p.ClientUUID)
+	if c == nil {
+		return apperror.NewClientNotFound()
+	}
+	if !versionMatches(c, p.ExpectedVersions) {
+		return apperror.NewClientVersionMismatch()
+	}
+
+	before := c.snapshot()
+	deletedAt := now()
+	c.deletedAt = &deletedAt
+	c.version = r.nextVersion()
+	r.record(ctx, actionDelete, c, before)
+
+	return nil
+}
+
+func (r *ClientRepository) List(_ context.Context, q handler.ListClientsQuery) (handler.ListClientsDTO, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clients := make([]handler.GetClientDTO, 0, q.Limit)
+	var lastID int64
+	for _, c := range r.clients {
+		if c.id <= q.AfterID || c.deletedAt != nil || !matches(c, q.Filter) {
+			continue
+		}
+		if len(clients) == q.Limit {
+			return handler.ListClientsDTO{Clients: clients, NextAfterID: lastID}, nil
+		}
+
+		clients = append(clients, listed(c))
+		lastID = c.id
+	}
+
+	return handler.ListClientsDTO{Clients: clients}, nil
+}
+
+func (r *ClientRepository) RestoreForUUID(ctx context.Context, clientUUID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	restored := r.deleted(clientUUID)
+	if restored == nil {
+		return apperror.NewClientNotFound()
+	}
+	if r.taken(restored, restored.uuid, restored.email) {
+		return apperror.NewClientAlreadyExists()
+	}
+
+	before := restored.snapshot()
+	restored.deletedAt = nil
+	restored.version = r.nextVersion()
+	r.record(ctx, actionRestore, restored, before)
+
+	return nil
+}
+
+func (r *ClientRepository) PurgeForUUID(ctx context.Context, clientUUID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := r.purge(ctx, func(c *client) bool {
+		return c.uuid == clientUUID
+	})
+	if purged == 0 {
+		return apperror.NewClientNotFound()
+	}
+
+	return nil
+}
+
+func (r *ClientRepository) PurgeDeletedOlderThan(ctx context.Context, retention time.Duration) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deletedBefore := now().Add(-retention)
+
+	return r.purge(ctx, func(c *client) bool {
+		return c.deletedAt.Before(deletedBefore)
+	}), nil
+}
+
+// Import skips the clients whose id or email is already taken, see taken
+func (r *ClientRepository) Import(ctx context.Context, clients []handler.CreateClientDTO) ([]uuid.UUID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	imported := make([]uuid.UUID, 0, len(clients))
+	for _, p := range clients {
+		if r.taken(nil, p.ClientUUID, p.Email) {
+			continue
+		}
+		r.insert(ctx, p)
+		imported = append(imported, p.ClientUUID)
+	}
+
+	return imported, nil
+}
+
+// Export calls f without holding the lock, the clients are the ones matching the filter when Export was called
+func (r *ClientRepository) Export(ctx context.Context, filter handler.ClientFilter, f func(client handler.GetClientDTO) error) error {
+	r.mu.RLock()
+	clients := make([]handler.GetClientDTO, 0, len(r.clients))
+	for _, c := range r.clients {
+		if c.deletedAt == nil && matches(c, filter) {
+			clients = append(clients, listed(c))
+		}
+	}
+	r.mu.RUnlock()
+
+	for _, c := range clients {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := f(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListHistory lists the history of the client like the client_audit table, the first page of a client
+// with no history which doesn't exist is ClientNotFound
+func (r *ClientRepository) ListHistory(_ context.Context, q handler.ListClientHistoryQuery) (handler.ClientHistoryDTO, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]handler.ClientHistoryEntryDTO, 0, q.Limit)
+	var lastID int64
+	for _, e := range r.history {
+		if e.id <= q.AfterID || e.clientUUID != q.ClientUUID {
+			continue
+		}
+		if len(entries) == q.Limit {
+			return handler.ClientHistoryDTO{Entries: entries, NextAfterID: lastID}, nil
+		}
+
+		entries = append(entries, e.entry)
+		lastID = e.id
+	}
+	if q.AfterID == 0 && len(entries) == 0 && r.live(q.ClientUUID) == nil && r.deleted(q.ClientUUID) == nil {
+		return handler.ClientHistoryDTO{}, apperror.NewClientNotFound()
+	}
+
+	return handler.ClientHistoryDTO{Entries: entries}, nil
+}
+
+func (r *ClientRepository) insert(ctx context.Context, p handler.CreateClientDTO) {
+	r.lastID++
+	c := &client{
+		id:          r.lastID,
+		uuid:        p.ClientUUID,
+		name:        p.Name,
+		email:       p.Email,
+		dateOfBirth: timestamp(p.DateOfBirth),
+		createdAt:   now(),
+		version:     r.nextVersion(),
+	}
+	r.clients = append(r.clients, c)
+	r.record(ctx, actionCreate, c, nil)
+}
+
+// purge removes the deleted clients matching the condition
+func (r *ClientRepository) purge(ctx context.Context, condition func(c *client) bool) int64 {
+	n := len(r.clients)
+	r.clients = slices.DeleteFunc(r.clients, func(c *client) bool {
+		if c.deletedAt == nil || !condition(c) {
+			return false
+		}
+		r.record(ctx, actionPurge, c, c.snapshot())
+
+		return true
+	})
+
+	return int64(n - len(r.clients))
+}
+
+// record appends the change of the client to its history, before is nil for create and the client is not recorded
+// after a purge, the actor and the request id are taken from the audit meta of the context
+func (r *ClientRepository) record(ctx context.Context, action string, c *client, before json.RawMessage) {
+	var after json.RawMessage
+	if action != actionPurge {
+		after = c.snapshot()
+	}
+
+	meta := audit.MetaFromContext(ctx)
+	r.lastHistoryID++
+	r.history = append(r.history, historyEntry{
+		id:         r.lastHistoryID,
+		clientUUID: c.uuid,
+		entry: handler.ClientHistoryEntryDTO{
+			Action:    action,
+			Before:    before,
+			After:     after,
+			Actor:     meta.Actor,
+			RequestID: meta.RequestID,
+			CreatedAt: now(),
+		},
+	})
+}
+
+func (r *ClientRepository) live(clientUUID uuid.UUID) *client {
+	for _, c := range r.clients {
+		if c.uuid == clientUUID && c.deletedAt == nil {
+			return c
+		}
+	}
+
+	return nil
+}
+
+func (r *ClientRepository) deleted(clientUUID uuid.UUID) *client {
+	for _, c := range r.clients {
+		if c.uuid == clientUUID && c.deletedAt != nil {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// taken tells whether a client other than self has the uuid or a live one has the email,
+// the uuid stays taken until the client is purged
+func (r *ClientRepository) taken(self *client, clientUUID uuid.UUID, email string) bool {
+	for _, c := range r.clients {
+		if c != self && (c.uuid == clientUUID || c.deletedAt == nil && c.email == email) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *ClientRepository) nextVersion() int64 {
+	r.lastVersion++
+	return r.lastVersion
+}
+
+func versionMatches(c *client, expectedVersions []int64) bool {
+	return expectedVersions == nil || slices.Contains(expectedVersions, c.version)
+}
+
+// matches is the condition of handler.ClientFilter, the email domain is case-insensitive and the name prefix is not
+func matches(c *client, f handler.ClientFilter) bool {
+	return (f.EmailDomain == "" || strings.HasSuffix(strings.ToLower(c.email), "@"+strings.ToLower(f.EmailDomain))) &&
+		(f.NamePrefix == "" || strings.HasPrefix(c.name, f.NamePrefix)) &&
+		(f.DateOfBirthFrom == nil || !c.dateOfBirth.Before(timestamp(*f.DateOfBirthFrom))) &&
+		(f.DateOfBirthTo == nil || c.dateOfBirth.Before(timestamp(*f.DateOfBirthTo))) &&
+		(f.CreatedAtFrom == nil || !c.createdAt.Before(timestamp(*f.CreatedAtFrom))) &&
+		(f.CreatedAtTo == nil || c.createdAt.Before(timestamp(*f.CreatedAtTo)))
+}
+
+// listed is the client as it is listed and exported, without the version
+func listed(c *client) handler.GetClientDTO {
+	dto := c.dto()
+	dto.Version = 0
+
+	return dto
+}
+
+// timestamp keeps the wall clock of t in UTC with microsecond precision like a TIMESTAMP column
+func timestamp(t time.Time) time.Time {
+	t = t.Truncate(time.Microsecond)
+
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+func now() time.Time {
+	return timestamp(time.Now().UTC())
+}
@@ -0,0 +1,36 @@
+package pg
+
+import (
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/infrastructure/pg/operation"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/pgx"
+)
+
+// ClientRepository is the handler.ClientRepository of the client operations, every change is audited and outboxed
+type ClientRepository struct {
+	*operation.CreateClient
+	*operation.GetClient
+	*operation.UpdateClient
+	*operation.DeleteClient
+	*operation.ListClients
+	*operation.RestoreClient
+	*operation.PurgeClient
+	*operation.ImportClients
+	*operation.ExportClients
+	*operation.ListClientHistory
+}
+
+func NewClientRepository(pgConn pgx.Connection) *ClientRepository {
+	return &ClientRepository{
+		CreateClient:  operation.NewCreateClientOperation(pgConn),
+		GetClient:     operation.NewGetClientOperation(pgConn),
+		UpdateClient:  operation.NewUpdateClientOperation(pgConn),
+		DeleteClient:  operation.NewDeleteClientOperation(pgConn),
+		ListClients:   operation.NewListClientsOperation(pgConn),
+		RestoreClient: operation.NewRestoreClientOperation(pgConn),
+		PurgeClient:   operation.NewPurgeClientOperation(pgConn),
+		ImportClients: operation.NewImportClientsOperation(pgConn),
+		ExportClients: operation.NewExportClientsOperation(pgConn),
+
+		ListClientHistory: operation.NewListClientHistoryOperation(pgConn),
+	}
+}
@@ -0,0 +1,42 @@
+package operation
+
+import (
+	"context"
+
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/audit"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/pgx"
+)
+
+type auditMetaResult struct {
+	Actor     string `db:"actor"`
+	RequestID string `db:"request_id"`
+}
+
+// withAudit runs f in a transaction which tells the client_audit trigger who made the change
+func withAudit(ctx context.Context, pgConn pgx.Connection, name string, f func(tx pgx.ConnectionTx) error) error {
+	meta := audit.MetaFromContext(ctx)
+
+	cancel, err := pgConn.WithTransaction(ctx, name, pgx.TxOptions{}, func(tx pgx.ConnectionTx) error {
+		_, err := pgx.QueryOne[auditMetaResult](ctx, tx, "SetAuditMeta", setAuditMetaSQL(), pgx.NamedArgs{
+			"actor":     meta.Actor,
+			"requestID": meta.RequestID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return f(tx)
+	})
+	defer cancel()
+
+	return err
+}
+
+// setAuditMetaSQL sets the audit settings for the current transaction only
+func setAuditMetaSQL() string {
+	return `
+SELECT
+	set_config('audit.actor', @actor::TEXT, TRUE) AS actor,
+	set_config('audit.request_id', @requestID::TEXT, TRUE) AS request_id;
+`
+}
@@ -0,0 +1,335 @@
+package operation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/audit"
+	apperror "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/error"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/pgx"
+)
+
+// ClientImport stores the scheduled client imports and is the store of the import job
+type ClientImport struct {
+	pgConn pgx.Connection
+}
+
+type ClientImportResult struct {
+	ImportUUID string     `db:"uuid"`
+	Status     string     `db:"status"`
+	TotalRows  int        `db:"total_rows"`
+	Report     []byte     `db:"report"`
+	Error      *string    `db:"error"`
+	CreatedAt  time.Time  `db:"created_at"`
+	FinishedAt *time.Time `db:"finished_at"`
+}
+
+type ClientImportCreateResult struct {
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type ClientImportClaimResult struct {
+	ImportUUID string `db:"uuid"`
+	Rows       []byte `db:"rows"`
+	Actor      string `db:"actor"`
+	RequestID  string `db:"request_id"`
+}
+
+// ClientImportRowPayload is a row of the import stored in the rows column
+type ClientImportRowPayload struct {
+	Row         int       `json:"row"`
+	ID          string    `json:"id"`
+	ClientUUID  uuid.UUID `json:"client_id"`
+	Email       string    `json:"email"`
+	Name        string    `json:"name"`
+	DateOfBirth time.Time `json:"date_of_birth"`
+	Error       string    `json:"error,omitempty"`
+}
+
+type ClientImportRowResultPayload struct {
+	Row    int    `json:"row"`
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ClientImportReportPayload is the report of the completed import stored in the report column
+type ClientImportReportPayload struct {
+	Total     int                            `json:"total"`
+	Accepted  int                            `json:"accepted"`
+	Duplicate int                            `json:"duplicate"`
+	Invalid   int                            `json:"invalid"`
+	Rows      []ClientImportRowResultPayload `json:"rows"`
+}
+
+func NewClientImportOperation(pgConn pgx.Connection) *ClientImport {
+	return &ClientImport{pgConn: pgConn}
+}
+
+func (o *ClientImport) Create(ctx context.Context, job handler.ClientImportJobDTO) (handler.ClientImportDTO, error) {
+	rows := make([]ClientImportRowPayload, 0, len(job.Rows))
+	for _, row := range job.Rows {
+		rows = append(rows, ClientImportRowPayload{
+			Row:         row.Row,
+			ID:          row.ID,
+			ClientUUID:  row.Client.ClientUUID,
+			Email:       row.Client.Email,
+			Name:        row.Client.Name,
+			DateOfBirth: row.Client.DateOfBirth,
+			Error:       row.Error,
+		})
+	}
+	rowsJSON, err := json.Marshal(rows)
+	if err != nil {
+		return handler.ClientImportDTO{}, err
+	}
+
+	res, err := pgx.QueryOne[ClientImportCreateResult](ctx, o.pgConn, "CreateClientImport", o.createSQL(), pgx.NamedArgs{
+		"uuid":      job.ImportUUID.String(),
+		"rows":      string(rowsJSON),
+		"totalRows": len(job.Rows),
+		"actor":     job.Meta.Actor,
+		"requestID": job.Meta.RequestID,
+	})
+	if err != nil {
+		return handler.ClientImportDTO{}, err
+	}
+
+	return handler.ClientImportDTO{
+		ImportUUID: job.ImportUUID,
+		Status:     handler.ClientImportPending,
+		TotalRows:  len(job.Rows),
+		CreatedAt:  res.CreatedAt,
+	}, nil
+}
+
+func (o *ClientImport) GetForUUID(ctx context.Context, importUUID uuid.UUID) (handler.ClientImportDTO, error) {
+	res, err := pgx.QueryOne[ClientImportResult](ctx, o.pgConn, "GetClientImport", o.getSQL(), pgx.NamedArgs{
+		"uuid": importUUID.String(),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return handler.ClientImportDTO{}, apperror.NewClientImportNotFound()
+		}
+		return handler.ClientImportDTO{}, err
+	}
+
+	clientImport := handler.ClientImportDTO{
+		ImportUUID: importUUID,
+		Status:     res.Status,
+		TotalRows:  res.TotalRows,
+		CreatedAt:  res.CreatedAt,
+		FinishedAt: res.FinishedAt,
+	}
+	if res.Error != nil {
+		clientImport.Error = *res.Error
+	}
+	if res.Report != nil {
+		var report ClientImportReportPayload
+		err := json.Unmarshal(res.Report, &report)
+		if err != nil {
+			return handler.ClientImportDTO{}, err
+		}
+
+		clientImport.Report = &handler.ClientImportReportDTO{
+			Total:     report.Total,
+			Accepted:  report.Accepted,
+			Duplicate: report.Duplicate,
+			Invalid:   report.Invalid,
+			Rows:      make([]handler.ClientImportRowResultDTO, 0, len(report.Rows)),
+		}
+		for _, row := range report.Rows {
+			clientImport.Report.Rows = append(clientImport.Report.Rows, handler.ClientImportRowResultDTO{
+				Row:    row.Row,
+				ID:     row.ID,
+				Status: row.Status,
+				Error:  row.Error,
+			})
+		}
+	}
+
+	return clientImport, nil
+}
+
+func (o *ClientImport) Claim(ctx context.Context, lease time.Duration) (handler.ClientImportJobDTO, bool, error) {
+	res, err := pgx.QueryOne[ClientImportClaimResult](ctx, o.pgConn, "ClaimClientImport", o.claimSQL(), pgx.NamedArgs{
+		"lease": lease,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return handler.ClientImportJobDTO{}, false, nil
+		}
+		return handler.ClientImportJobDTO{}, false, err
+	}
+
+	importUUID, err := uuid.Parse(res.ImportUUID)
+	if err != nil {
+		return handler.ClientImportJobDTO{}, false, err
+	}
+
+	var rows []ClientImportRowPayload
+	err = json.Unmarshal(res.Rows, &rows)
+	if err != nil {
+		return handler.ClientImportJobDTO{}, false, err
+	}
+
+	job := handler.ClientImportJobDTO{
+		ImportUUID: importUUID,
+		Rows:       make([]handler.ClientImportRow, 0, len(rows)),
+		Meta: audit.Meta{
+			Actor:     res.Actor,
+			RequestID: res.RequestID,
+		},
+	}
+	for _, row := range rows {
+		job.Rows = append(job.Rows, handler.ClientImportRow{
+			Row: row.Row,
+			ID:  row.ID,
+			Client: handler.CreateClientDTO{
+				Name:        row.Name,
+				Email:       row.Email,
+				DateOfBirth: row.DateOfBirth,
+				ClientUUID:  row.ClientUUID,
+			},
+			Error: row.Error,
+		})
+	}
+
+	return job, true, nil
+}
+
+func (o *ClientImport) Complete(ctx context.Context, importUUID uuid.UUID, report handler.ClientImportReportDTO) error {
+	payload := ClientImportReportPayload{
+		Total:     report.Total,
+		Accepted:  report.Accepted,
+		Duplicate: report.Duplicate,
+		Invalid:   report.Invalid,
+		Rows:      make([]ClientImportRowResultPayload, 0, len(report.Rows)),
+	}
+	for _, row := range report.Rows {
+		payload.Rows = append(payload.Rows, ClientImportRowResultPayload{
+			Row:    row.Row,
+			ID:     row.ID,
+			Status: row.Status,
+			Error:  row.Error,
+		})
+	}
+	reportJSON, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	updated, err := o.pgConn.Exec(ctx, "CompleteClientImport", o.completeSQL(), pgx.NamedArgs{
+		"uuid":   importUUID.String(),
+		"report": string(reportJSON),
+	})
+	if err != nil {
+		return err
+	}
+
+	if updated == 0 {
+		return apperror.NewClientImportNotFound()
+	}
+
+	return nil
+}
+
+func (o *ClientImport) Fail(ctx context.Context, importUUID uuid.UUID, reason string) error {
+	updated, err := o.pgConn.Exec(ctx, "FailClientImport", o.failSQL(), pgx.NamedArgs{
+		"uuid":   importUUID.String(),
+		"reason": reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	if updated == 0 {
+		return apperror.NewClientImportNotFound()
+	}
+
+	return nil
+}
+
+func (o *ClientImport) createSQL() string {
+	return `
+INSERT INTO client_import (uuid, rows, total_rows, actor, request_id)
+	VALUES (@uuid::UUID, @rows::JSONB, @totalRows, @actor, @requestID)
+RETURNING created_at;
+`
+}
+
+func (o *ClientImport) getSQL() string {
+	return `
+SELECT
+	uuid,
+	status,
+	total_rows,
+	report,
+	error,
+	created_at,
+	finished_at
+FROM
+	client_import
+WHERE
+	uuid = @uuid::UUID;
+`
+}
+
+// claimSQL claims again the running imports whose lease expired, their instance probably stopped
+func (o *ClientImport) claimSQL() string {
+	return `
+UPDATE client_import
+SET
+	status = 'running',
+	locked_until = NOW() + @lease::INTERVAL,
+	started_at = COALESCE(started_at, NOW())
+WHERE
+	id = (
+		SELECT
+			id
+		FROM
+			client_import
+		WHERE
+			status = 'pending'
+			OR (status = 'running' AND locked_until < NOW())
+		ORDER BY
+			id
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	)
+RETURNING uuid, rows, actor, request_id;
+`
+}
+
+// completeSQL drops the imported rows, only the report is kept
+func (o *ClientImport) completeSQL() string {
+	return `
+UPDATE client_import
+SET
+	status = 'completed',
+	rows = '[]',
+	report = @report::JSONB,
+	locked_until = NULL,
+	finished_at = NOW()
+WHERE
+	uuid = @uuid::UUID;
+`
+}
+
+func (o *ClientImport) failSQL() string {
+	return `
+UPDATE client_import
+SET
+	status = 'failed',
+	rows = '[]',
+	error = @reason,
+	locked_until = NULL,
+	finished_at = NOW()
+WHERE
+	uuid = @uuid::UUID;
+`
+}
@@ -15,44 +15,40 @@ type CreateClient struct {
 	pgConn pgx.Connection
 }
 
-type CreateClientResult struct {
-	ClientID int64 `db:"id_client"`
-}
-
 func NewCreateClientOperation(pgConn pgx.Connection) *CreateClient {
 	return &CreateClient{pgConn: pgConn}
 }
 
-func (o *CreateClient) Execute(ctx context.Context, p handler.CreateClientDTO) error {
-	r, cancel := o.pgConn.QueryRow(ctx, "CreateClient", o.sql(), pgx.NamedArgs{
-		"email":       p.Email,
-		"name":        p.Name,
-		"uuid":        p.ClientUUID.String(),
-		"dateOfBirth": p.DateOfBirth,
-	})
-	defer cancel()
-
-	res := CreateClientResult{}
-	err := (*r).Scan(
-		&res.ClientID,
-	)
-	if err != nil {
-		if pgErr, ok := err.(*pgconn.PgError); ok {
-			if pgErr.Code == DuplicationViolationCode {
-				return apperror.NewClientAlreadyExists()
+func (o *CreateClient) Create(ctx context.Context, p handler.CreateClientDTO) error {
+	return withAudit(ctx, o.pgConn, "CreateClient", func(tx pgx.ConnectionTx) error {
+		_, err := tx.Exec(ctx, "CreateClient", o.sql(), pgx.NamedArgs{
+			"email":       p.Email,
+			"name":        p.Name,
+			"uuid":        p.ClientUUID.String(),
+			"dateOfBirth": p.DateOfBirth,
+		})
+		if err != nil {
+			if pgErr, ok := err.(*pgconn.PgError); ok {
+				if pgErr.Code == DuplicationViolationCode {
+					return apperror.NewClientAlreadyExists()
+				}
 			}
-		}
 
-		return err
-	}
+			return err
+		}
 
-	return nil
+		return writeClientEvent(ctx, tx, handler.ClientCreatedEvent, p.ClientUUID, ClientEventPayload{
+			ID:          p.ClientUUID.String(),
+			Name:        p.Name,
+			Email:       p.Email,
+			DateOfBirth: p.DateOfBirth.Format(dateOfBirthLayout),
+		})
+	})
 }
 
 func (o *CreateClient) sql() string {
 	return `
 INSERT INTO client (email, name, uuid, date_of_birth)
-		values(@email, @name, @uuid::UUID, @dateOfBirth)
-	RETURNING id;
+		values(@email, @name, @uuid::UUID, @dateOfBirth);
 `
 }
@@ -0,0 +1,80 @@
+package operation
+
+import (
+	"context"
+	"errors"
+
+	apperror "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/error"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/pgx"
+)
+
+type DeleteClient struct {
+	pgConn pgx.Connection
+}
+
+type DeleteClientResult struct {
+	CurrentVersion int64 `db:"current_version"`
+	Deleted        bool  `db:"deleted"`
+}
+
+func NewDeleteClientOperation(pgConn pgx.Connection) *DeleteClient {
+	return &DeleteClient{pgConn: pgConn}
+}
+
+func (o *DeleteClient) Delete(ctx context.Context, p handler.DeleteClientDTO) error {
+	return withAudit(ctx, o.pgConn, "DeleteClient", func(tx pgx.ConnectionTx) error {
+		res, err := pgx.QueryOne[DeleteClientResult](ctx, tx, "DeleteClient", o.sql(), pgx.NamedArgs{
+			"uuid":             p.ClientUUID.String(),
+			"anyVersion":       p.ExpectedVersions == nil,
+			"expectedVersions": p.ExpectedVersions,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return apperror.NewClientNotFound()
+			}
+			return err
+		}
+
+		if !res.Deleted {
+			return apperror.NewClientVersionMismatch()
+		}
+
+		return writeClientEvent(ctx, tx, handler.ClientDeletedEvent, p.ClientUUID, ClientEventPayload{
+			ID: p.ClientUUID.String(),
+		})
+	})
+}
+
+// sql returns no row when the client does not exist and deleted false when the expected version does not match
+func (o *DeleteClient) sql() string {
+	return `
+WITH current AS (
+	SELECT
+		id,
+		version
+	FROM
+		client
+	WHERE
+		uuid = @uuid::UUID
+		AND deleted_at IS NULL
+	FOR UPDATE
+), deleted AS (
+	UPDATE client
+	SET
+		deleted_at = NOW()
+	FROM
+		current
+	WHERE
+		client.id = current.id
+		AND (@anyVersion::BOOLEAN OR current.version = ANY(@expectedVersions::BIGINT[]))
+	RETURNING client.id
+)
+SELECT
+	current.version AS current_version,
+	deleted.id IS NOT NULL AS deleted
+FROM
+	current
+	LEFT JOIN deleted ON TRUE;
+`
+}
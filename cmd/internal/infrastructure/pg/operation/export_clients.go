@@ -0,0 +1,66 @@
+package operation
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/pgx"
+)
+
+// exportClientsFetchSize is the number of clients fetched from the cursor at a time
+const exportClientsFetchSize = 1000
+
+type ExportClients struct {
+	pgConn pgx.Connection
+}
+
+func NewExportClientsOperation(pgConn pgx.Connection) *ExportClients {
+	return &ExportClients{pgConn: pgConn}
+}
+
+func (o *ExportClients) Export(ctx context.Context, filter handler.ClientFilter, f func(client handler.GetClientDTO) error) error {
+	return o.pgConn.QueryCursor(ctx, "ExportClients", o.sql(), clientFilterArgs(filter), exportClientsFetchSize, func(row pgx.Row) error {
+		res := ListClientsResult{}
+		err := row.Scan(
+			&res.ClientID,
+			&res.Name,
+			&res.ClientUUID,
+			&res.Email,
+			&res.DateOfBirth,
+		)
+		if err != nil {
+			return err
+		}
+
+		clientUUID, err := uuid.Parse(res.ClientUUID)
+		if err != nil {
+			return err
+		}
+
+		return f(handler.GetClientDTO{
+			Name:        res.Name,
+			Email:       res.Email,
+			ClientUUID:  clientUUID,
+			DateOfBirth: res.DateOfBirth.Format(dateOfBirthLayout),
+		})
+	})
+}
+
+func (o *ExportClients) sql() string {
+	return `
+SELECT
+	id,
+	name,
+	uuid,
+	email,
+	date_of_birth
+FROM
+	client
+WHERE
+	deleted_at IS NULL
+	AND` + clientFilterSQL + `
+ORDER BY
+	id;
+`
+}
@@ -26,21 +26,18 @@ type GetClientDataResult struct {
 	Name        string    `db:"name"`
 	ClientUUID  string    `db:"uuid"`
 	DateOfBirth time.Time `db:"date_of_birth"`
+	Version     int64     `db:"version"`
 }
 
+// GetForUUID reads from a replica unless the read is marked by handler.ReadLatest
 func (o *GetClient) GetForUUID(ctx context.Context, clientUUID uuid.UUID) (handler.GetClientDTO, error) {
-	r, cancel := o.pgConn.QueryRow(ctx, "GetClient", o.sql(), pgx.NamedArgs{
+	if !handler.ReadsLatest(ctx) {
+		ctx = pgx.ReadFromReplica(ctx)
+	}
+
+	res, err := pgx.QueryOne[GetClientDataResult](ctx, o.pgConn, "GetClient", o.sql(), pgx.NamedArgs{
 		"uuid": clientUUID.String(),
 	})
-	defer cancel()
-
-	res := GetClientDataResult{}
-	err := (*r).Scan(
-		&res.Name,
-		&res.ClientUUID,
-		&res.Email,
-		&res.DateOfBirth,
-	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return handler.GetClientDTO{}, apperror.NewClientNotFound()
@@ -58,19 +55,22 @@ func (o *GetClient) GetForUUID(ctx context.Context, clientUUID uuid.UUID) (handl
 		Email:       res.Email,
 		ClientUUID:  clientUUIDParsed,
 		DateOfBirth: res.DateOfBirth.Format(dateOfBirthLayout),
+		Version:     res.Version,
 	}, nil
 }
 
 func (o *GetClient) sql() string {
 	return `
 SELECT
-  name,
+	name,
 	uuid,
 	email,
-	date_of_birth
+	date_of_birth,
+	version
 FROM
 	client
 WHERE
-	uuid = @uuid;
+	uuid = @uuid
+	AND deleted_at IS NULL;
 `
 }
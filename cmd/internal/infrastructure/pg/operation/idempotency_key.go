@@ -0,0 +1,168 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	pkgGin "github.com/jamm3e3333/whalebone-go-test-project/pkg/net/http/gin"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/pgx"
+)
+
+type IdempotencyKey struct {
+	pgConn pgx.Connection
+}
+
+type IdempotencyKeyResult struct {
+	Claimed             bool    `db:"claimed"`
+	RequestFingerprint  string  `db:"request_fingerprint"`
+	ResponseStatus      *int    `db:"response_status"`
+	ResponseContentType *string `db:"response_content_type"`
+	ResponseBody        []byte  `db:"response_body"`
+}
+
+type IdempotencyKeyPurgeResult struct {
+	PurgedCount int64 `db:"purged_count"`
+}
+
+func NewIdempotencyKeyOperation(pgConn pgx.Connection) *IdempotencyKey {
+	return &IdempotencyKey{pgConn: pgConn}
+}
+
+func (o *IdempotencyKey) Claim(
+	ctx context.Context,
+	key string,
+	fingerprint string,
+	ttl time.Duration,
+) (bool, pkgGin.IdempotencyRecord, error) {
+	res, err := pgx.QueryOne[IdempotencyKeyResult](ctx, o.pgConn, "ClaimIdempotencyKey", o.claimSQL(), pgx.NamedArgs{
+		"key":         key,
+		"fingerprint": fingerprint,
+		"ttl":         ttl,
+	})
+	if err != nil {
+		// the key was claimed by a concurrent request which is not visible yet
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, pkgGin.IdempotencyRecord{Fingerprint: fingerprint}, nil
+		}
+		return false, pkgGin.IdempotencyRecord{}, err
+	}
+
+	record := pkgGin.IdempotencyRecord{
+		Fingerprint: res.RequestFingerprint,
+		Completed:   res.ResponseStatus != nil,
+		Body:        res.ResponseBody,
+	}
+	if res.ResponseStatus != nil {
+		record.StatusCode = *res.ResponseStatus
+	}
+	if res.ResponseContentType != nil {
+		record.ContentType = *res.ResponseContentType
+	}
+
+	return res.Claimed, record, nil
+}
+
+func (o *IdempotencyKey) Complete(ctx context.Context, key string, statusCode int, contentType string, body []byte) error {
+	_, err := o.pgConn.Exec(ctx, "CompleteIdempotencyKey", o.completeSQL(), pgx.NamedArgs{
+		"key":         key,
+		"status":      statusCode,
+		"contentType": contentType,
+		"body":        body,
+	})
+
+	return err
+}
+
+func (o *IdempotencyKey) Release(ctx context.Context, key string) error {
+	_, err := o.pgConn.Exec(ctx, "ReleaseIdempotencyKey", o.releaseSQL(), pgx.NamedArgs{
+		"key": key,
+	})
+
+	return err
+}
+
+func (o *IdempotencyKey) PurgeExpired(ctx context.Context) (int64, error) {
+	res, err := pgx.QueryOne[IdempotencyKeyPurgeResult](ctx, o.pgConn, "PurgeExpiredIdempotencyKeys", o.purgeExpiredSQL(), pgx.NamedArgs{})
+	if err != nil {
+		return 0, err
+	}
+
+	return res.PurgedCount, nil
+}
+
+// claimSQL takes over an expired key, otherwise it returns the stored key
+func (o *IdempotencyKey) claimSQL() string {
+	return `
+WITH claimed AS (
+	INSERT INTO idempotency_key (key, request_fingerprint, expires_at)
+		VALUES (@key, @fingerprint, NOW() + @ttl::INTERVAL)
+	ON CONFLICT (key) DO UPDATE
+	SET
+		request_fingerprint = EXCLUDED.request_fingerprint,
+		response_status = NULL,
+		response_content_type = NULL,
+		response_body = NULL,
+		created_at = NOW(),
+		expires_at = EXCLUDED.expires_at
+	WHERE
+		idempotency_key.expires_at < NOW()
+	RETURNING request_fingerprint, response_status, response_content_type, response_body
+)
+SELECT
+	TRUE AS claimed,
+	request_fingerprint,
+	response_status,
+	response_content_type,
+	response_body
+FROM
+	claimed
+UNION ALL
+SELECT
+	FALSE AS claimed,
+	request_fingerprint,
+	response_status,
+	response_content_type,
+	response_body
+FROM
+	idempotency_key
+WHERE
+	key = @key
+	AND NOT EXISTS (SELECT 1 FROM claimed);
+`
+}
+
+func (o *IdempotencyKey) completeSQL() string {
+	return `
+UPDATE idempotency_key
+SET
+	response_status = @status,
+	response_content_type = @contentType,
+	response_body = @body
+WHERE
+	key = @key;
+`
+}
+
+func (o *IdempotencyKey) releaseSQL() string {
+	return `
+DELETE FROM
+	idempotency_key
+WHERE
+	key = @key
+	AND response_status IS NULL;
+`
+}
+
+func (o *IdempotencyKey) purgeExpiredSQL() string {
+	return `
+WITH purged AS (
+	DELETE FROM
+		idempotency_key
+	WHERE
+		expires_at < NOW()
+	RETURNING key
+)
+SELECT COUNT(*) AS purged_count FROM purged;
+`
+}
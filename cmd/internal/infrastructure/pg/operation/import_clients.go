@@ -0,0 +1,125 @@
+package operation
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/pgx"
+)
+
+const clientImportStagingTable = "client_import_staging"
+
+type ImportClients struct {
+	pgConn pgx.Connection
+}
+
+type ImportClientsResult struct {
+	ClientUUID uuid.UUID `db:"uuid"`
+}
+
+func NewImportClientsOperation(pgConn pgx.Connection) *ImportClients {
+	return &ImportClients{pgConn: pgConn}
+}
+
+// Import copies the clients into a staging table and moves the new ones to the client table,
+// the created event of every imported client is copied to the outbox
+func (o *ImportClients) Import(ctx context.Context, clients []handler.CreateClientDTO) ([]uuid.UUID, error) {
+	imported := make([]uuid.UUID, 0, len(clients))
+	err := withAudit(ctx, o.pgConn, "ImportClients", func(tx pgx.ConnectionTx) error {
+		_, err := tx.Exec(ctx, "CreateClientImportStaging", o.createStagingSQL(), pgx.NamedArgs{})
+		if err != nil {
+			return err
+		}
+
+		stagingRows := make([][]any, 0, len(clients))
+		for i, c := range clients {
+			stagingRows = append(stagingRows, []any{i, c.ClientUUID, c.Email, c.Name, c.DateOfBirth})
+		}
+		_, err = tx.CopyFrom(ctx, "CopyClientImportStaging", clientImportStagingTable, []string{
+			"position",
+			"uuid",
+			"email",
+			"name",
+			"date_of_birth",
+		}, stagingRows)
+		if err != nil {
+			return err
+		}
+
+		rows, err := pgx.QueryAll[ImportClientsResult](ctx, tx, "ImportClients", o.sql(), pgx.NamedArgs{})
+		if err != nil {
+			return err
+		}
+
+		importedUUIDs := make(map[uuid.UUID]bool, len(rows))
+		for _, res := range rows {
+			importedUUIDs[res.ClientUUID] = true
+		}
+
+		outboxRows := make([][]any, 0, len(importedUUIDs))
+		for _, c := range clients {
+			if !importedUUIDs[c.ClientUUID] {
+				continue
+			}
+
+			payload, err := json.Marshal(ClientEventPayload{
+				ID:          c.ClientUUID.String(),
+				Name:        c.Name,
+				Email:       c.Email,
+				DateOfBirth: c.DateOfBirth.Format(dateOfBirthLayout),
+			})
+			if err != nil {
+				return err
+			}
+
+			outboxRows = append(outboxRows, []any{c.ClientUUID.String(), handler.ClientCreatedEvent, string(payload)})
+			imported = append(imported, c.ClientUUID)
+		}
+
+		_, err = tx.CopyFrom(ctx, "CopyClientImportOutbox", "outbox", []string{
+			"aggregate_id",
+			"event_type",
+			"payload",
+		}, outboxRows)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return imported, nil
+}
+
+// createStagingSQL creates the staging table which is dropped with the end of the transaction
+func (o *ImportClients) createStagingSQL() string {
+	return `
+CREATE TEMPORARY TABLE client_import_staging (
+	position INT NOT NULL,
+	uuid UUID NOT NULL,
+	email TEXT NOT NULL,
+	name TEXT NOT NULL,
+	date_of_birth TIMESTAMP NOT NULL
+) ON COMMIT DROP;
+`
+}
+
+// sql skips the clients whose id or email is already taken
+func (o *ImportClients) sql() string {
+	return `
+INSERT INTO client (email, name, uuid, date_of_birth)
+SELECT
+	email,
+	name,
+	uuid,
+	date_of_birth
+FROM
+	client_import_staging
+ORDER BY
+	position
+ON CONFLICT DO NOTHING
+RETURNING uuid;
+`
+}
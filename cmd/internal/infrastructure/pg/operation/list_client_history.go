@@ -0,0 +1,118 @@
+package operation
+
+import (
+	"context"
+	"time"
+
+	apperror "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/error"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/pgx"
+)
+
+type ListClientHistory struct {
+	pgConn pgx.Connection
+}
+
+func NewListClientHistoryOperation(pgConn pgx.Connection) *ListClientHistory {
+	return &ListClientHistory{pgConn: pgConn}
+}
+
+type ListClientHistoryResult struct {
+	ID        int64     `db:"id"`
+	Action    string    `db:"action"`
+	Before    []byte    `db:"before"`
+	After     []byte    `db:"after"`
+	Actor     *string   `db:"actor"`
+	RequestID *string   `db:"request_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type ClientExistsResult struct {
+	Exists bool `db:"exists"`
+}
+
+func (o *ListClientHistory) ListHistory(ctx context.Context, q handler.ListClientHistoryQuery) (handler.ClientHistoryDTO, error) {
+	rows, err := pgx.QueryAll[ListClientHistoryResult](ctx, o.pgConn, "ListClientHistory", o.sql(), pgx.NamedArgs{
+		"uuid":    q.ClientUUID.String(),
+		"afterID": q.AfterID,
+		// one extra row tells whether there is a next page
+		"limit": q.Limit + 1,
+	})
+	if err != nil {
+		return handler.ClientHistoryDTO{}, err
+	}
+
+	// a client created before the audit has no history, only a client which never existed is not found
+	if q.AfterID == 0 && len(rows) == 0 {
+		res, err := pgx.QueryOne[ClientExistsResult](ctx, o.pgConn, "ClientExists", o.existsSQL(), pgx.NamedArgs{
+			"uuid": q.ClientUUID.String(),
+		})
+		if err != nil {
+			return handler.ClientHistoryDTO{}, err
+		}
+		if !res.Exists {
+			return handler.ClientHistoryDTO{}, apperror.NewClientNotFound()
+		}
+	}
+
+	entries := make([]handler.ClientHistoryEntryDTO, 0, q.Limit)
+	var lastID int64
+	for _, res := range rows {
+		if len(entries) == q.Limit {
+			return handler.ClientHistoryDTO{Entries: entries, NextAfterID: lastID}, nil
+		}
+
+		entry := handler.ClientHistoryEntryDTO{
+			Action:    res.Action,
+			Before:    res.Before,
+			After:     res.After,
+			CreatedAt: res.CreatedAt,
+		}
+		if res.Actor != nil {
+			entry.Actor = *res.Actor
+		}
+		if res.RequestID != nil {
+			entry.RequestID = *res.RequestID
+		}
+
+		entries = append(entries, entry)
+		lastID = res.ID
+	}
+
+	return handler.ClientHistoryDTO{Entries: entries}, nil
+}
+
+func (o *ListClientHistory) sql() string {
+	return `
+SELECT
+	id,
+	action,
+	before,
+	after,
+	actor,
+	request_id,
+	created_at
+FROM
+	client_audit
+WHERE
+	client_uuid = @uuid::UUID
+	AND id > @afterID
+ORDER BY
+	id
+LIMIT @limit;
+`
+}
+
+// existsSQL finds the deleted clients as well
+func (o *ListClientHistory) existsSQL() string {
+	return `
+SELECT EXISTS (
+	SELECT
+		1
+	FROM
+		client
+	WHERE
+		uuid = @uuid::UUID
+) AS exists;
+`
+}
@@ -0,0 +1,112 @@
+package operation
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/pgx"
+)
+
+var likePatternEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+type ListClients struct {
+	pgConn pgx.Connection
+}
+
+func NewListClientsOperation(pgConn pgx.Connection) *ListClients {
+	return &ListClients{pgConn: pgConn}
+}
+
+type ListClientsResult struct {
+	ClientID    int64     `db:"id"`
+	Name        string    `db:"name"`
+	ClientUUID  string    `db:"uuid"`
+	Email       string    `db:"email"`
+	DateOfBirth time.Time `db:"date_of_birth"`
+}
+
+func (o *ListClients) List(ctx context.Context, q handler.ListClientsQuery) (handler.ListClientsDTO, error) {
+	args := clientFilterArgs(q.Filter)
+	args["afterID"] = q.AfterID
+	// one extra row tells whether there is a next page
+	args["limit"] = q.Limit + 1
+
+	rows, err := pgx.QueryAll[ListClientsResult](pgx.ReadFromReplica(ctx), o.pgConn, "ListClients", o.sql(), args)
+	if err != nil {
+		return handler.ListClientsDTO{}, err
+	}
+
+	clients := make([]handler.GetClientDTO, 0, q.Limit)
+	var lastID int64
+	for _, res := range rows {
+		if len(clients) == q.Limit {
+			return handler.ListClientsDTO{Clients: clients, NextAfterID: lastID}, nil
+		}
+
+		clientUUID, err := uuid.Parse(res.ClientUUID)
+		if err != nil {
+			return handler.ListClientsDTO{}, err
+		}
+
+		clients = append(clients, handler.GetClientDTO{
+			Name:        res.Name,
+			Email:       res.Email,
+			ClientUUID:  clientUUID,
+			DateOfBirth: res.DateOfBirth.Format(dateOfBirthLayout),
+		})
+		lastID = res.ClientID
+	}
+
+	return handler.ListClientsDTO{Clients: clients}, nil
+}
+
+func clientFilterArgs(f handler.ClientFilter) pgx.NamedArgs {
+	args := pgx.NamedArgs{
+		"emailDomain":     nil,
+		"namePrefix":      nil,
+		"dateOfBirthFrom": f.DateOfBirthFrom,
+		"dateOfBirthTo":   f.DateOfBirthTo,
+		"createdAtFrom":   f.CreatedAtFrom,
+		"createdAtTo":     f.CreatedAtTo,
+	}
+	if f.EmailDomain != "" {
+		args["emailDomain"] = "%@" + likePatternEscaper.Replace(strings.ToLower(f.EmailDomain))
+	}
+	if f.NamePrefix != "" {
+		args["namePrefix"] = likePatternEscaper.Replace(f.NamePrefix) + "%"
+	}
+
+	return args
+}
+
+// clientFilterSQL is the WHERE condition matching handler.ClientFilter, see clientFilterArgs
+const clientFilterSQL = `
+	(@emailDomain::TEXT IS NULL OR LOWER(email) LIKE @emailDomain::TEXT)
+	AND (@namePrefix::TEXT IS NULL OR name LIKE @namePrefix::TEXT)
+	AND (@dateOfBirthFrom::TIMESTAMP IS NULL OR date_of_birth >= @dateOfBirthFrom::TIMESTAMP)
+	AND (@dateOfBirthTo::TIMESTAMP IS NULL OR date_of_birth < @dateOfBirthTo::TIMESTAMP)
+	AND (@createdAtFrom::TIMESTAMP IS NULL OR created_at >= @createdAtFrom::TIMESTAMP)
+	AND (@createdAtTo::TIMESTAMP IS NULL OR created_at < @createdAtTo::TIMESTAMP)`
+
+func (o *ListClients) sql() string {
+	return `
+SELECT
+	id,
+	name,
+	uuid,
+	email,
+	date_of_birth
+FROM
+	client
+WHERE
+	id > @afterID
+	AND deleted_at IS NULL
+	AND` + clientFilterSQL + `
+ORDER BY
+	id
+LIMIT @limit;
+`
+}
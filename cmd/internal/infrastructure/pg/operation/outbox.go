@@ -0,0 +1,151 @@
+package operation
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/outbox"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/pgx"
+)
+
+// ClientEventPayload is the payload of the client outbox messages, only the id is set for delete, restore and purge
+type ClientEventPayload struct {
+	ID          string `json:"id"`
+	Name        string `json:"name,omitempty"`
+	Email       string `json:"email,omitempty"`
+	DateOfBirth string `json:"date_of_birth,omitempty"`
+	Version     int64  `json:"version,omitempty"`
+}
+
+// writeClientEvent stores the event in the outbox, it has to run in the transaction of the change
+func writeClientEvent(ctx context.Context, tx pgx.ConnectionTx, eventType string, clientUUID uuid.UUID, payload ClientEventPayload) error {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, "WriteOutboxMessage", writeOutboxMessageSQL(), pgx.NamedArgs{
+		"aggregateID": clientUUID.String(),
+		"eventType":   eventType,
+		"payload":     string(payloadJSON),
+	})
+
+	return err
+}
+
+func writeOutboxMessageSQL() string {
+	return `
+INSERT INTO outbox (aggregate_id, event_type, payload)
+	VALUES (@aggregateID, @eventType, @payload::JSONB);
+`
+}
+
+type Outbox struct {
+	pgConn pgx.Connection
+}
+
+type OutboxResult struct {
+	ID          int64     `db:"id"`
+	AggregateID string    `db:"aggregate_id"`
+	EventType   string    `db:"event_type"`
+	Payload     []byte    `db:"payload"`
+	CreatedAt   time.Time `db:"created_at"`
+	Attempts    int       `db:"attempts"`
+}
+
+func NewOutboxOperation(pgConn pgx.Connection) *Outbox {
+	return &Outbox{pgConn: pgConn}
+}
+
+func (o *Outbox) Claim(ctx context.Context, limit int, lease time.Duration) ([]outbox.Message, error) {
+	rows, err := pgx.QueryAll[OutboxResult](ctx, o.pgConn, "ClaimOutboxMessages", o.claimSQL(), pgx.NamedArgs{
+		"limit": limit,
+		"lease": lease,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]outbox.Message, 0, len(rows))
+	for _, res := range rows {
+		messages = append(messages, outbox.Message{
+			ID:          res.ID,
+			AggregateID: res.AggregateID,
+			EventType:   res.EventType,
+			Payload:     res.Payload,
+			CreatedAt:   res.CreatedAt,
+			Attempts:    res.Attempts,
+		})
+	}
+
+	return messages, nil
+}
+
+func (o *Outbox) Complete(ctx context.Context, id int64) error {
+	_, err := o.pgConn.Exec(ctx, "CompleteOutboxMessage", o.completeSQL(), pgx.NamedArgs{
+		"id": id,
+	})
+
+	return err
+}
+
+func (o *Outbox) Fail(ctx context.Context, id int64, backoff time.Duration, reason string) error {
+	_, err := o.pgConn.Exec(ctx, "FailOutboxMessage", o.failSQL(), pgx.NamedArgs{
+		"id":      id,
+		"backoff": backoff,
+		"reason":  reason,
+	})
+
+	return err
+}
+
+// claimSQL claims only the oldest message of an aggregate, the later ones wait until it is published
+func (o *Outbox) claimSQL() string {
+	return `
+UPDATE outbox
+SET
+	locked_until = NOW() + @lease::INTERVAL
+WHERE
+	id IN (
+		SELECT
+			id
+		FROM
+			outbox o
+		WHERE
+			o.next_attempt_at <= NOW()
+			AND (o.locked_until IS NULL OR o.locked_until < NOW())
+			AND NOT EXISTS (
+				SELECT 1 FROM outbox earlier WHERE earlier.aggregate_id = o.aggregate_id AND earlier.id < o.id
+			)
+		ORDER BY
+			id
+		LIMIT @limit
+		FOR UPDATE SKIP LOCKED
+	)
+RETURNING id, aggregate_id, event_type, payload, created_at, attempts;
+`
+}
+
+func (o *Outbox) completeSQL() string {
+	return `
+DELETE FROM
+	outbox
+WHERE
+	id = @id;
+`
+}
+
+func (o *Outbox) failSQL() string {
+	return `
+UPDATE outbox
+SET
+	attempts = attempts + 1,
+	next_attempt_at = NOW() + @backoff::INTERVAL,
+	locked_until = NULL,
+	last_error = @reason
+WHERE
+	id = @id;
+`
+}
@@ -0,0 +1,94 @@
+package operation
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/error"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/pgx"
+)
+
+type PurgeClient struct {
+	pgConn pgx.Connection
+}
+
+type PurgeClientResult struct {
+	PurgedCount int64 `db:"purged_count"`
+}
+
+func NewPurgeClientOperation(pgConn pgx.Connection) *PurgeClient {
+	return &PurgeClient{pgConn: pgConn}
+}
+
+func (o *PurgeClient) PurgeForUUID(ctx context.Context, clientUUID uuid.UUID) error {
+	return withAudit(ctx, o.pgConn, "PurgeClient", func(tx pgx.ConnectionTx) error {
+		purged, err := tx.Exec(ctx, "PurgeClient", o.purgeForUUIDSQL(), pgx.NamedArgs{
+			"uuid": clientUUID.String(),
+		})
+		if err != nil {
+			return err
+		}
+
+		if purged == 0 {
+			return apperror.NewClientNotFound()
+		}
+
+		return writeClientEvent(ctx, tx, handler.ClientPurgedEvent, clientUUID, ClientEventPayload{
+			ID: clientUUID.String(),
+		})
+	})
+}
+
+func (o *PurgeClient) PurgeDeletedOlderThan(ctx context.Context, retention time.Duration) (int64, error) {
+	var res PurgeClientResult
+	err := withAudit(ctx, o.pgConn, "PurgeDeletedClients", func(tx pgx.ConnectionTx) error {
+		var err error
+		res, err = pgx.QueryOne[PurgeClientResult](ctx, tx, "PurgeDeletedClients", o.purgeDeletedOlderThanSQL(), pgx.NamedArgs{
+			"retention": retention,
+			"eventType": handler.ClientPurgedEvent,
+		})
+
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return res.PurgedCount, nil
+}
+
+func (o *PurgeClient) purgeForUUIDSQL() string {
+	return `
+DELETE FROM
+	client
+WHERE
+	uuid = @uuid::UUID
+	AND deleted_at IS NOT NULL;
+`
+}
+
+// purgeDeletedOlderThanSQL writes the purge event of every purged client to the outbox, see ClientEventPayload
+func (o *PurgeClient) purgeDeletedOlderThanSQL() string {
+	return `
+WITH purged AS (
+	DELETE FROM
+		client
+	WHERE
+		deleted_at < NOW() - @retention::INTERVAL
+	RETURNING id, uuid
+), outboxed AS (
+	INSERT INTO outbox (aggregate_id, event_type, payload)
+	SELECT
+		uuid::TEXT,
+		@eventType,
+		jsonb_build_object('id', uuid)
+	FROM
+		purged
+	ORDER BY
+		id
+)
+SELECT COUNT(*) AS purged_count FROM purged;
+`
+}
@@ -0,0 +1,60 @@
+package operation
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	apperror "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/error"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/pgx"
+)
+
+type RestoreClient struct {
+	pgConn pgx.Connection
+}
+
+type RestoreClientResult struct {
+	ClientID int64 `db:"id"`
+}
+
+func NewRestoreClientOperation(pgConn pgx.Connection) *RestoreClient {
+	return &RestoreClient{pgConn: pgConn}
+}
+
+func (o *RestoreClient) RestoreForUUID(ctx context.Context, clientUUID uuid.UUID) error {
+	return withAudit(ctx, o.pgConn, "RestoreClient", func(tx pgx.ConnectionTx) error {
+		_, err := pgx.QueryOne[RestoreClientResult](ctx, tx, "RestoreClient", o.sql(), pgx.NamedArgs{
+			"uuid": clientUUID.String(),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return apperror.NewClientNotFound()
+			}
+			if pgErr, ok := err.(*pgconn.PgError); ok {
+				if pgErr.Code == DuplicationViolationCode {
+					return apperror.NewClientAlreadyExists()
+				}
+			}
+
+			return err
+		}
+
+		return writeClientEvent(ctx, tx, handler.ClientRestoredEvent, clientUUID, ClientEventPayload{
+			ID: clientUUID.String(),
+		})
+	})
+}
+
+func (o *RestoreClient) sql() string {
+	return `
+UPDATE client
+SET
+	deleted_at = NULL
+WHERE
+	uuid = @uuid::UUID
+	AND deleted_at IS NOT NULL
+RETURNING id;
+`
+}
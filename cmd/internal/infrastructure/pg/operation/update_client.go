@@ -0,0 +1,103 @@
+package operation
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	apperror "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/error"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/pgx"
+)
+
+type UpdateClient struct {
+	pgConn pgx.Connection
+}
+
+type UpdateClientResult struct {
+	CurrentVersion int64  `db:"current_version"`
+	NewVersion     *int64 `db:"new_version"`
+}
+
+func NewUpdateClientOperation(pgConn pgx.Connection) *UpdateClient {
+	return &UpdateClient{pgConn: pgConn}
+}
+
+func (o *UpdateClient) Update(ctx context.Context, p handler.UpdateClientDTO) (int64, error) {
+	var res UpdateClientResult
+	err := withAudit(ctx, o.pgConn, "UpdateClient", func(tx pgx.ConnectionTx) error {
+		var err error
+		res, err = pgx.QueryOne[UpdateClientResult](ctx, tx, "UpdateClient", o.sql(), pgx.NamedArgs{
+			"email":            p.Email,
+			"name":             p.Name,
+			"uuid":             p.ClientUUID.String(),
+			"dateOfBirth":      p.DateOfBirth,
+			"anyVersion":       p.ExpectedVersions == nil,
+			"expectedVersions": p.ExpectedVersions,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return apperror.NewClientNotFound()
+			}
+			if pgErr, ok := err.(*pgconn.PgError); ok {
+				if pgErr.Code == DuplicationViolationCode {
+					return apperror.NewClientAlreadyExists()
+				}
+			}
+
+			return err
+		}
+
+		if res.NewVersion == nil {
+			return apperror.NewClientVersionMismatch()
+		}
+
+		return writeClientEvent(ctx, tx, handler.ClientUpdatedEvent, p.ClientUUID, ClientEventPayload{
+			ID:          p.ClientUUID.String(),
+			Name:        p.Name,
+			Email:       p.Email,
+			DateOfBirth: p.DateOfBirth.Format(dateOfBirthLayout),
+			Version:     *res.NewVersion,
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return *res.NewVersion, nil
+}
+
+// sql returns no row when the client does not exist and no new_version when the expected version does not match
+func (o *UpdateClient) sql() string {
+	return `
+WITH current AS (
+	SELECT
+		id,
+		version
+	FROM
+		client
+	WHERE
+		uuid = @uuid::UUID
+		AND deleted_at IS NULL
+	FOR UPDATE
+), updated AS (
+	UPDATE client
+	SET
+		email = @email,
+		name = @name,
+		date_of_birth = @dateOfBirth
+	FROM
+		current
+	WHERE
+		client.id = current.id
+		AND (@anyVersion::BOOLEAN OR current.version = ANY(@expectedVersions::BIGINT[]))
+	RETURNING client.version
+)
+SELECT
+	current.version AS current_version,
+	updated.version AS new_version
+FROM
+	current
+	LEFT JOIN updated ON TRUE;
+`
+}
@@ -0,0 +1,258 @@
+package operation
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/outbox"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/pgx"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/webhook"
+)
+
+// WebhookDelivery is the outbox publisher which enqueues a delivery of the message for every subscribed webhook
+// and the store of the webhook dispatcher
+type WebhookDelivery struct {
+	pgConn pgx.Connection
+}
+
+type WebhookDeliveryClaimResult struct {
+	ID        int64  `db:"id"`
+	URL       string `db:"url"`
+	Secret    string `db:"secret"`
+	EventType string `db:"event_type"`
+	Body      []byte `db:"body"`
+	Attempts  int    `db:"attempts"`
+}
+
+type WebhookDeliveryResult struct {
+	ID             int64      `db:"id"`
+	EventID        int64      `db:"event_id"`
+	EventType      string     `db:"event_type"`
+	Status         string     `db:"status"`
+	Attempts       int        `db:"attempts"`
+	LastStatusCode *int       `db:"last_status_code"`
+	LastError      *string    `db:"last_error"`
+	CreatedAt      time.Time  `db:"created_at"`
+	NextAttemptAt  time.Time  `db:"next_attempt_at"`
+	DeliveredAt    *time.Time `db:"delivered_at"`
+}
+
+func NewWebhookDeliveryOperation(pgConn pgx.Connection) *WebhookDelivery {
+	return &WebhookDelivery{pgConn: pgConn}
+}
+
+// Publish is idempotent, the message is enqueued only once for every webhook
+func (o *WebhookDelivery) Publish(ctx context.Context, m outbox.Message) error {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	_, err = o.pgConn.Exec(ctx, "EnqueueWebhookDeliveries", o.enqueueSQL(), pgx.NamedArgs{
+		"eventID":   m.ID,
+		"eventType": m.EventType,
+		"body":      string(body),
+	})
+
+	return err
+}
+
+func (o *WebhookDelivery) Claim(ctx context.Context, limit int, lease time.Duration) ([]webhook.Delivery, error) {
+	rows, err := pgx.QueryAll[WebhookDeliveryClaimResult](ctx, o.pgConn, "ClaimWebhookDeliveries", o.claimSQL(), pgx.NamedArgs{
+		"limit": limit,
+		"lease": lease,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]webhook.Delivery, 0, len(rows))
+	for _, res := range rows {
+		deliveries = append(deliveries, webhook.Delivery{
+			ID:        res.ID,
+			URL:       res.URL,
+			Secret:    res.Secret,
+			EventType: res.EventType,
+			Body:      res.Body,
+			Attempts:  res.Attempts,
+		})
+	}
+
+	return deliveries, nil
+}
+
+func (o *WebhookDelivery) Complete(ctx context.Context, id int64, statusCode int) error {
+	_, err := o.pgConn.Exec(ctx, "CompleteWebhookDelivery", o.completeSQL(), pgx.NamedArgs{
+		"id":         id,
+		"statusCode": statusCode,
+	})
+
+	return err
+}
+
+func (o *WebhookDelivery) Fail(ctx context.Context, id int64, statusCode int, reason string, backoff time.Duration, dead bool) error {
+	status := handler.WebhookDeliveryPending
+	if dead {
+		status = handler.WebhookDeliveryDead
+	}
+
+	var lastStatusCode *int
+	if statusCode != 0 {
+		lastStatusCode = &statusCode
+	}
+
+	_, err := o.pgConn.Exec(ctx, "FailWebhookDelivery", o.failSQL(), pgx.NamedArgs{
+		"id":         id,
+		"statusCode": lastStatusCode,
+		"reason":     reason,
+		"backoff":    backoff,
+		"status":     status,
+	})
+
+	return err
+}
+
+func (o *WebhookDelivery) List(ctx context.Context, q handler.ListWebhookDeliveriesQuery) (handler.WebhookDeliveriesDTO, error) {
+	rows, err := pgx.QueryAll[WebhookDeliveryResult](ctx, o.pgConn, "ListWebhookDeliveries", o.listSQL(), pgx.NamedArgs{
+		"uuid":     q.WebhookUUID.String(),
+		"beforeID": q.BeforeID,
+		// one extra row tells whether there is a next page
+		"limit": q.Limit + 1,
+	})
+	if err != nil {
+		return handler.WebhookDeliveriesDTO{}, err
+	}
+
+	deliveries := make([]handler.WebhookDeliveryDTO, 0, q.Limit)
+	var lastID int64
+	for _, res := range rows {
+		if len(deliveries) == q.Limit {
+			return handler.WebhookDeliveriesDTO{Deliveries: deliveries, NextBeforeID: lastID}, nil
+		}
+
+		delivery := handler.WebhookDeliveryDTO{
+			ID:             res.ID,
+			EventID:        res.EventID,
+			EventType:      res.EventType,
+			Status:         res.Status,
+			Attempts:       res.Attempts,
+			LastStatusCode: res.LastStatusCode,
+			CreatedAt:      res.CreatedAt,
+			NextAttemptAt:  res.NextAttemptAt,
+			DeliveredAt:    res.DeliveredAt,
+		}
+		if res.LastError != nil {
+			delivery.LastError = *res.LastError
+		}
+
+		deliveries = append(deliveries, delivery)
+		lastID = res.ID
+	}
+
+	return handler.WebhookDeliveriesDTO{Deliveries: deliveries}, nil
+}
+
+// enqueueSQL matches the webhooks with no event types to all events
+func (o *WebhookDelivery) enqueueSQL() string {
+	return `
+INSERT INTO webhook_delivery (subscription_id, event_id, event_type, body)
+SELECT
+	id,
+	@eventID,
+	@eventType,
+	@body::JSONB
+FROM
+	webhook_subscription
+WHERE
+	active
+	AND (cardinality(event_types) = 0 OR @eventType = ANY(event_types))
+ON CONFLICT (subscription_id, event_id) DO NOTHING;
+`
+}
+
+// claimSQL skips the deliveries of inactive webhooks, they are delivered once the webhook is activated again
+func (o *WebhookDelivery) claimSQL() string {
+	return `
+UPDATE webhook_delivery d
+SET
+	locked_until = NOW() + @lease::INTERVAL
+FROM
+	webhook_subscription s
+WHERE
+	s.id = d.subscription_id
+	AND d.id IN (
+		SELECT
+			pending.id
+		FROM
+			webhook_delivery pending
+			JOIN webhook_subscription ps ON ps.id = pending.subscription_id
+		WHERE
+			pending.status = 'pending'
+			AND pending.next_attempt_at <= NOW()
+			AND (pending.locked_until IS NULL OR pending.locked_until < NOW())
+			AND ps.active
+		ORDER BY
+			pending.id
+		LIMIT @limit
+		FOR UPDATE OF pending SKIP LOCKED
+	)
+RETURNING d.id, s.url, s.secret, d.event_type, d.body, d.attempts;
+`
+}
+
+func (o *WebhookDelivery) completeSQL() string {
+	return `
+UPDATE webhook_delivery
+SET
+	status = 'delivered',
+	attempts = attempts + 1,
+	locked_until = NULL,
+	last_status_code = @statusCode,
+	last_error = NULL,
+	delivered_at = NOW()
+WHERE
+	id = @id;
+`
+}
+
+func (o *WebhookDelivery) failSQL() string {
+	return `
+UPDATE webhook_delivery
+SET
+	status = @status,
+	attempts = attempts + 1,
+	next_attempt_at = NOW() + @backoff::INTERVAL,
+	locked_until = NULL,
+	last_status_code = @statusCode::INT,
+	last_error = @reason
+WHERE
+	id = @id;
+`
+}
+
+func (o *WebhookDelivery) listSQL() string {
+	return `
+SELECT
+	d.id,
+	d.event_id,
+	d.event_type,
+	d.status,
+	d.attempts,
+	d.last_status_code,
+	d.last_error,
+	d.created_at,
+	d.next_attempt_at,
+	d.delivered_at
+FROM
+	webhook_delivery d
+	JOIN webhook_subscription s ON s.id = d.subscription_id
+WHERE
+	s.uuid = @uuid::UUID
+	AND (@beforeID::BIGINT = 0 OR d.id < @beforeID::BIGINT)
+ORDER BY
+	d.id DESC
+LIMIT @limit;
+`
+}
@@ -0,0 +1,196 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/error"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/pgx"
+)
+
+type WebhookSubscription struct {
+	pgConn pgx.Connection
+}
+
+type WebhookSubscriptionResult struct {
+	WebhookUUID string    `db:"uuid"`
+	URL         string    `db:"url"`
+	EventTypes  []string  `db:"event_types"`
+	Active      bool      `db:"active"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+type WebhookSubscriptionCreateResult struct {
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func NewWebhookSubscriptionOperation(pgConn pgx.Connection) *WebhookSubscription {
+	return &WebhookSubscription{pgConn: pgConn}
+}
+
+func (o *WebhookSubscription) Create(ctx context.Context, p handler.WebhookDTO) (handler.WebhookDTO, error) {
+	res, err := pgx.QueryOne[WebhookSubscriptionCreateResult](ctx, o.pgConn, "CreateWebhookSubscription", o.createSQL(), pgx.NamedArgs{
+		"uuid":       p.WebhookUUID.String(),
+		"url":        p.URL,
+		"secret":     p.Secret,
+		"eventTypes": eventTypesArg(p.EventTypes),
+		"active":     p.Active,
+	})
+	if err != nil {
+		return handler.WebhookDTO{}, err
+	}
+
+	p.CreatedAt = res.CreatedAt
+	return p, nil
+}
+
+func (o *WebhookSubscription) GetForUUID(ctx context.Context, webhookUUID uuid.UUID) (handler.WebhookDTO, error) {
+	res, err := pgx.QueryOne[WebhookSubscriptionResult](ctx, o.pgConn, "GetWebhookSubscription", o.getSQL(), pgx.NamedArgs{
+		"uuid": webhookUUID.String(),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return handler.WebhookDTO{}, apperror.NewWebhookNotFound()
+		}
+		return handler.WebhookDTO{}, err
+	}
+
+	return webhookDTO(res)
+}
+
+func (o *WebhookSubscription) List(ctx context.Context) ([]handler.WebhookDTO, error) {
+	rows, err := pgx.QueryAll[WebhookSubscriptionResult](ctx, o.pgConn, "ListWebhookSubscriptions", o.listSQL(), pgx.NamedArgs{})
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]handler.WebhookDTO, 0, len(rows))
+	for _, res := range rows {
+		webhook, err := webhookDTO(res)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, nil
+}
+
+func (o *WebhookSubscription) Update(ctx context.Context, p handler.UpdateWebhookDTO) error {
+	count, err := o.pgConn.Exec(ctx, "UpdateWebhookSubscription", o.updateSQL(), pgx.NamedArgs{
+		"uuid":       p.WebhookUUID.String(),
+		"url":        p.URL,
+		"eventTypes": eventTypesArg(p.EventTypes),
+		"active":     p.Active,
+	})
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return apperror.NewWebhookNotFound()
+	}
+
+	return nil
+}
+
+func (o *WebhookSubscription) DeleteForUUID(ctx context.Context, webhookUUID uuid.UUID) error {
+	count, err := o.pgConn.Exec(ctx, "DeleteWebhookSubscription", o.deleteSQL(), pgx.NamedArgs{
+		"uuid": webhookUUID.String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return apperror.NewWebhookNotFound()
+	}
+
+	return nil
+}
+
+func webhookDTO(res WebhookSubscriptionResult) (handler.WebhookDTO, error) {
+	webhookUUID, err := uuid.Parse(res.WebhookUUID)
+	if err != nil {
+		return handler.WebhookDTO{}, err
+	}
+
+	return handler.WebhookDTO{
+		WebhookUUID: webhookUUID,
+		URL:         res.URL,
+		EventTypes:  res.EventTypes,
+		Active:      res.Active,
+		CreatedAt:   res.CreatedAt,
+	}, nil
+}
+
+// eventTypesArg keeps the NOT NULL event_types empty instead of NULL
+func eventTypesArg(eventTypes []string) []string {
+	if eventTypes == nil {
+		return []string{}
+	}
+
+	return eventTypes
+}
+
+func (o *WebhookSubscription) createSQL() string {
+	return `
+INSERT INTO webhook_subscription (uuid, url, secret, event_types, active)
+	VALUES (@uuid::UUID, @url, @secret, @eventTypes::TEXT[], @active)
+RETURNING created_at;
+`
+}
+
+func (o *WebhookSubscription) getSQL() string {
+	return `
+SELECT
+	uuid,
+	url,
+	event_types,
+	active,
+	created_at
+FROM
+	webhook_subscription
+WHERE
+	uuid = @uuid::UUID;
+`
+}
+
+func (o *WebhookSubscription) listSQL() string {
+	return `
+SELECT
+	uuid,
+	url,
+	event_types,
+	active,
+	created_at
+FROM
+	webhook_subscription
+ORDER BY
+	id;
+`
+}
+
+func (o *WebhookSubscription) updateSQL() string {
+	return `
+UPDATE webhook_subscription
+SET
+	url = @url,
+	event_types = @eventTypes::TEXT[],
+	active = @active
+WHERE
+	uuid = @uuid::UUID;
+`
+}
+
+func (o *WebhookSubscription) deleteSQL() string {
+	return `
+DELETE FROM
+	webhook_subscription
+WHERE
+	uuid = @uuid::UUID;
+`
+}
@@ -0,0 +1,45 @@
+package pg
+
+import (
+	"context"
+
+	healthcheck "github.com/jamm3e3333/whalebone-go-test-project/pkg/health"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
+)
+
+// ReplicaChecker is the pgx.ConnectionPool
+type ReplicaChecker interface {
+	CheckReplica(ctx context.Context, name string) error
+}
+
+type ReplicaHealthIndicator struct {
+	ctx     context.Context
+	checker ReplicaChecker
+	replica string
+	lg      logger.Logger
+}
+
+func NewReplicaHealthIndicator(ctx context.Context, checker ReplicaChecker, replica string, lg logger.Logger) *ReplicaHealthIndicator {
+	return &ReplicaHealthIndicator{
+		ctx:     ctx,
+		checker: checker,
+		replica: replica,
+		lg:      lg,
+	}
+}
+
+func (i *ReplicaHealthIndicator) ComponentName() string {
+	return "pg-whalebone-clients-" + i.replica
+}
+
+// Status is down also when the replica lags too much, the queries fall back to the primary meanwhile
+func (i *ReplicaHealthIndicator) Status() healthcheck.Status {
+	err := i.checker.CheckReplica(i.ctx, i.replica)
+	if err != nil {
+		i.lg.Error("whalebone clients postgres %s is down and threw %s!", i.replica, err)
+
+		return healthcheck.StatusDown
+	}
+
+	return healthcheck.StatusUp
+}
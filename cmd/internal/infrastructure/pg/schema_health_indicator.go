@@ -0,0 +1,135 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	healthcheck "github.com/jamm3e3333/whalebone-go-test-project/pkg/health"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/migrate"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/pgx"
+)
+
+// undefinedTableCode is the SQLSTATE of a missing goose version table, no migration was applied
+const undefinedTableCode = "42P01"
+
+// schemaVersionUnavailable is the detail of an unreadable schema version, the error itself is only logged
+const schemaVersionUnavailable = "schema version unavailable"
+
+type appliedVersion struct {
+	VersionID int64 `db:"version_id"`
+}
+
+// SchemaHealthIndicator compares the migrations the binary was built with to the goose version table
+type SchemaHealthIndicator struct {
+	ctx        context.Context
+	conn       pgx.Connection
+	migrations []migrate.Migration
+	lg         logger.Logger
+
+	mu sync.Mutex
+	// state is the last reported state, it is logged only when it changes so the probes don't flood the log
+	state string
+}
+
+func NewSchemaHealthIndicator(ctx context.Context, conn pgx.Connection, migrations []migrate.Migration, lg logger.Logger) *SchemaHealthIndicator {
+	return &SchemaHealthIndicator{
+		ctx:        ctx,
+		conn:       conn,
+		migrations: migrations,
+		lg:         lg,
+	}
+}
+
+func (i *SchemaHealthIndicator) ComponentName() string {
+	return "pg-whalebone-clients-schema"
+}
+
+func (i *SchemaHealthIndicator) Status() healthcheck.Status {
+	s, _ := i.StatusWithDetails()
+	return s
+}
+
+// StatusWithDetails is down when the schema is behind or ahead of the binary, the details list the differing versions
+func (i *SchemaHealthIndicator) StatusWithDetails() (healthcheck.Status, map[string]any) {
+	d, err := i.Drift()
+	if err != nil {
+		if i.changed(schemaVersionUnavailable) {
+			i.lg.Error("whalebone clients postgres schema version can't be read and threw %s!", err)
+		}
+
+		return healthcheck.StatusDown, map[string]any{"error": schemaVersionUnavailable}
+	}
+
+	if !d.InSync() {
+		if i.changed(d.String()) {
+			i.lg.Error("whalebone clients postgres %s!", d)
+		}
+
+		return healthcheck.StatusDown, map[string]any{
+			"expected_version": d.Expected,
+			"current_version":  d.Current,
+			"pending":          d.Pending,
+			"unknown":          d.Unknown,
+		}
+	}
+
+	if i.changed(d.String()) {
+		i.lg.Info("whalebone clients postgres %s", d)
+	}
+
+	return healthcheck.StatusUp, nil
+}
+
+// changed records the state and tells whether it differs from the previous one
+func (i *SchemaHealthIndicator) changed(state string) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.state == state {
+		return false
+	}
+	i.state = state
+
+	return true
+}
+
+// Drift reads the applied versions from the primary, replicas may not have replayed the latest migration yet
+func (i *SchemaHealthIndicator) Drift() (migrate.Drift, error) {
+	rows, err := pgx.QueryAll[appliedVersion](i.ctx, i.conn, "SchemaVersion", appliedVersionsSQL(), pgx.NamedArgs{})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) || pgErr.Code != undefinedTableCode {
+			return migrate.Drift{}, err
+		}
+	}
+
+	applied := make([]int64, 0, len(rows))
+	for _, r := range rows {
+		applied = append(applied, r.VersionID)
+	}
+
+	return migrate.Compare(i.migrations, applied), nil
+}
+
+// appliedVersionsSQL takes the latest row of every version, goose keeps the history of the versions
+func appliedVersionsSQL() string {
+	return `
+SELECT
+	version_id
+FROM (
+	SELECT DISTINCT ON (version_id)
+		version_id,
+		is_applied
+	FROM
+		goose_db_version
+	ORDER BY
+		version_id,
+		id DESC
+) AS latest
+WHERE
+	is_applied;
+`
+}
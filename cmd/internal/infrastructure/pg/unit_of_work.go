@@ -0,0 +1,20 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/pgx"
+)
+
+type UnitOfWork struct {
+	pgConn pgx.Connection
+}
+
+func NewUnitOfWork(pgConn pgx.Connection) *UnitOfWork {
+	return &UnitOfWork{pgConn: pgConn}
+}
+
+// Run runs f in a transaction, f may be run again when the transaction conflicts with another one
+func (u *UnitOfWork) Run(ctx context.Context, name string, f func(ctx context.Context) error) error {
+	return u.pgConn.RunInTransaction(ctx, name, pgx.TxOptions{}, f)
+}
@@ -23,10 +23,22 @@ type ConnectionMetrics struct {
 	DbConnectionGauge prometheus.Gauge
 }
 
+type ReplicaMetrics struct {
+	QueryTargetCounter *prometheus.CounterVec
+	ReplicaLagGauge    *prometheus.GaugeVec
+	ReplicaHealthGauge *prometheus.GaugeVec
+}
+
+type OutboxMetrics struct {
+	OutboxMessageCounter *prometheus.CounterVec
+	OutboxLagHistogram   *prometheus.HistogramVec
+}
+
 type PgMetrics struct {
 	Qm *QueryMetrics
 	Tm *TransactionMetrics
 	Cm *ConnectionMetrics
+	Rm *ReplicaMetrics
 }
 
 func (m *RequestMetrics) IncRequestCounter(labels ...string) {
@@ -61,14 +73,37 @@ func (m *TransactionMetrics) ObserveTransactionDurationHistogram(timestampDiff f
 	m.TransactionDurationHistogram.WithLabelValues(labels...).Observe(timestampDiff)
 }
 
+func (m *ReplicaMetrics) IncQueryTargetCounter(labels ...string) {
+	m.QueryTargetCounter.WithLabelValues(labels...).Inc()
+}
+
+func (m *ReplicaMetrics) SetReplicaLagGauge(lag float64, labels ...string) {
+	m.ReplicaLagGauge.WithLabelValues(labels...).Set(lag)
+}
+
+func (m *ReplicaMetrics) SetReplicaHealthyGauge(healthy float64, labels ...string) {
+	m.ReplicaHealthGauge.WithLabelValues(labels...).Set(healthy)
+}
+
+func (m *OutboxMetrics) IncOutboxMessageCounter(labels ...string) {
+	m.OutboxMessageCounter.WithLabelValues(labels...).Inc()
+}
+
+func (m *OutboxMetrics) ObserveOutboxLagHistogram(lag float64, labels ...string) {
+	m.OutboxLagHistogram.WithLabelValues(labels...).Observe(lag)
+}
+
 type Metrics struct {
 	Pm *PgMetrics
+	Om *OutboxMetrics
 }
 
 func NewMetrics(
 	pm *PgMetrics,
+	om *OutboxMetrics,
 ) *Metrics {
 	return &Metrics{
 		Pm: pm,
+		Om: om,
 	}
 }
@@ -1,28 +1,169 @@
 package internal
 
 import (
+	"context"
+	"net/http"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/job"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/infrastructure/pg"
 	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/infrastructure/pg/operation"
+	pkghttp "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/ui/http"
 	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/ui/http/v1/client"
+	webhookCTRL "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/ui/http/v1/webhook"
 	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
+	pkgGin "github.com/jamm3e3333/whalebone-go-test-project/pkg/net/http/gin"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/outbox"
 	"github.com/jamm3e3333/whalebone-go-test-project/pkg/pgx"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/webhook"
+)
+
+const (
+	outboxRelayBatchSize  = 100
+	outboxRelayLease      = time.Minute
+	outboxRelayMinBackoff = time.Second
+	outboxRelayMaxBackoff = 5 * time.Minute
+
+	webhookDispatcherBatchSize   = 50
+	webhookDispatcherMinBackoff  = 5 * time.Second
+	webhookDispatcherMaxBackoff  = time.Hour
+	webhookDispatcherMaxAttempts = 10
+
+	clientImportLease = 30 * time.Minute
 )
 
 type ModuleParams struct {
 	AppENV string
 	PGConn pgx.Connection
 	Logger logger.Logger
+
+	// ClientRepository and ClientImportRepository are optional, the clients and their imports are kept in Postgres by default
+	ClientRepository       handler.ClientRepository
+	ClientImportRepository handler.ClientImportRepository
+
+	// DeletedClientsRetention disables the purge of deleted clients when zero
+	DeletedClientsRetention     time.Duration
+	DeletedClientsPurgeInterval time.Duration
+
+	IdempotencyKeyTTL           time.Duration
+	IdempotencyKeyPurgeInterval time.Duration
+
+	// OutboxPublisher is optional, the outbox relay always fans the events out to the webhook subscriptions
+	OutboxPublisher     outbox.Publisher
+	OutboxRelayInterval time.Duration
+	OutboxMetrics       outbox.Metrics
+
+	// ClientImportInterval disables the import of scheduled client imports when zero
+	ClientImportInterval time.Duration
+
+	// WebhookDeliveryInterval disables the webhook dispatcher when zero
+	WebhookDeliveryInterval time.Duration
+	WebhookTimeout          time.Duration
 }
 
-func RegisterModule(ge *gin.Engine, p ModuleParams) {
-	createClient := operation.NewCreateClientOperation(p.PGConn)
-	getClient := operation.NewGetClientOperation(p.PGConn)
+func RegisterModule(ctx context.Context, ge *gin.Engine, p ModuleParams) {
+	clients := p.ClientRepository
+	if clients == nil {
+		clients = pg.NewClientRepository(p.PGConn)
+	}
+	clientImport := p.ClientImportRepository
+	if clientImport == nil {
+		clientImport = operation.NewClientImportOperation(p.PGConn)
+	}
+
+	idempotencyKey := operation.NewIdempotencyKeyOperation(p.PGConn)
+	outboxStore := operation.NewOutboxOperation(p.PGConn)
+	webhookSubscription := operation.NewWebhookSubscriptionOperation(p.PGConn)
+	webhookDelivery := operation.NewWebhookDeliveryOperation(p.PGConn)
+
+	getClientHan := handler.NewGetClientHandler(clients)
+	createClientHan := handler.NewCreateClientHandler(clients)
+	updateClientHan := handler.NewUpdateClientHandler(clients)
+	deleteClientHan := handler.NewDeleteClientHandler(clients)
+	listClientsHan := handler.NewListClientsHandler(clients)
+	restoreClientHan := handler.NewRestoreClientHandler(clients)
+	purgeClientHan := handler.NewPurgeClientHandler(clients)
+	listClientHistoryHan := handler.NewListClientHistoryHandler(clients)
+	importClientsHan := handler.NewImportClientsHandler(clients)
+	scheduleClientImportHan := handler.NewScheduleClientImportHandler(clientImport)
+	getClientImportHan := handler.NewGetClientImportHandler(clientImport)
+	exportClientsHan := handler.NewExportClientsHandler(clients)
+	createWebhookHan := handler.NewCreateWebhookHandler(webhookSubscription)
+	getWebhookHan := handler.NewGetWebhookHandler(webhookSubscription)
+	listWebhooksHan := handler.NewListWebhooksHandler(webhookSubscription)
+	updateWebhookHan := handler.NewUpdateWebhookHandler(webhookSubscription)
+	deleteWebhookHan := handler.NewDeleteWebhookHandler(webhookSubscription)
+	listWebhookDeliveriesHan := handler.NewListWebhookDeliveriesHandler(webhookSubscription, webhookDelivery)
+
+	clientCTRL := client.NewController(client.Handlers{
+		CreateClient:  createClientHan,
+		GetClient:     getClientHan,
+		UpdateClient:  updateClientHan,
+		DeleteClient:  deleteClientHan,
+		ListClients:   listClientsHan,
+		RestoreClient: restoreClientHan,
+		PurgeClient:   purgeClientHan,
+		ClientHistory: listClientHistoryHan,
+
+		ImportClients:        importClientsHan,
+		ScheduleClientImport: scheduleClientImportHan,
+		GetClientImport:      getClientImportHan,
+		ExportClients:        exportClientsHan,
+	})
+
+	clientCTRL.Register(ge, pkgGin.IdempotencyMiddleware(idempotencyKey, p.IdempotencyKeyTTL, p.Logger), pkghttp.ErrorMiddleware(p.Logger))
+
+	webhookCTRL.NewController(webhookCTRL.Handlers{
+		CreateWebhook:         createWebhookHan,
+		GetWebhook:            getWebhookHan,
+		ListWebhooks:          listWebhooksHan,
+		UpdateWebhook:         updateWebhookHan,
+		DeleteWebhook:         deleteWebhookHan,
+		ListWebhookDeliveries: listWebhookDeliveriesHan,
+	}).Register(ge)
+
+	if p.DeletedClientsRetention > 0 && p.DeletedClientsPurgeInterval > 0 {
+		purgeJob := job.NewPurgeDeletedClients(purgeClientHan, p.DeletedClientsRetention, p.DeletedClientsPurgeInterval, p.Logger)
+		go purgeJob.Run(ctx)
+	}
+
+	if p.IdempotencyKeyPurgeInterval > 0 {
+		idempotencyKeyPurgeJob := job.NewPurgeExpiredIdempotencyKeys(idempotencyKey, p.IdempotencyKeyPurgeInterval, p.Logger)
+		go idempotencyKeyPurgeJob.Run(ctx)
+	}
+
+	if p.ClientImportInterval > 0 {
+		importClientsJob := job.NewImportClients(importClientsHan, clientImport, p.ClientImportInterval, clientImportLease, p.Logger)
+		go importClientsJob.Run(ctx)
+	}
 
-	getClientHan := handler.NewGetClientHandler(getClient)
-	createClientHan := handler.NewCreateClientHandler(createClient)
+	if p.OutboxRelayInterval > 0 {
+		publishers := []outbox.Publisher{webhookDelivery}
+		if p.OutboxPublisher != nil {
+			publishers = append(publishers, p.OutboxPublisher)
+		}
 
-	clientCTRL := client.NewController(createClientHan, getClientHan)
+		outboxRelay := outbox.NewRelay(outboxStore, outbox.NewMultiPublisher(publishers...), outbox.RelayConfig{
+			Interval:   p.OutboxRelayInterval,
+			BatchSize:  outboxRelayBatchSize,
+			Lease:      outboxRelayLease,
+			MinBackoff: outboxRelayMinBackoff,
+			MaxBackoff: outboxRelayMaxBackoff,
+		}, p.OutboxMetrics, p.Logger)
+		go outboxRelay.Run(ctx)
+	}
 
-	clientCTRL.Register(ge)
+	if p.WebhookDeliveryInterval > 0 {
+		webhookDispatcher := webhook.NewDispatcher(webhookDelivery, &http.Client{Timeout: p.WebhookTimeout}, webhook.DispatcherConfig{
+			Interval:    p.WebhookDeliveryInterval,
+			BatchSize:   webhookDispatcherBatchSize,
+			Lease:       2 * p.WebhookTimeout,
+			MinBackoff:  webhookDispatcherMinBackoff,
+			MaxBackoff:  webhookDispatcherMaxBackoff,
+			MaxAttempts: webhookDispatcherMaxAttempts,
+		}, p.Logger)
+		go webhookDispatcher.Run(ctx)
+	}
 }
@@ -0,0 +1,28 @@
+package http
+
+import (
+	"encoding/base64"
+	"strconv"
+)
+
+// EncodeCursor encodes the keyset of the next page, there is no next page when the id is zero
+func EncodeCursor(id int64) string {
+	if id == 0 {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+func DecodeCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseInt(string(decoded), 10, 64)
+}
@@ -5,8 +5,71 @@ import (
 	"net/http"
 
 	apperror "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/error"
+	pkgGin "github.com/jamm3e3333/whalebone-go-test-project/pkg/net/http/gin"
 )
 
+// codes of the errors raised by the http layer
+const (
+	MalformedRequestCode     = "malformed_request"
+	ValidationFailedCode     = "validation_failed"
+	InvalidParametersCode    = "invalid_parameters"
+	NotFoundCode             = "not_found"
+	PreconditionRequiredCode = "precondition_required"
+	UnsupportedMediaTypeCode = "unsupported_media_type"
+	PayloadTooLargeCode      = "payload_too_large"
+	InternalServerErrorCode  = pkgGin.InternalServerErrorCode
+)
+
+type ProblemType struct {
+	Code   string
+	Status int
+	Title  string
+}
+
+var internalServerErrorType = ProblemType{InternalServerErrorCode, http.StatusInternalServerError, "Internal server error"}
+
+// ProblemTypes is the catalog of the errors of the API, the codes are stable and published in the API docs
+var ProblemTypes = []ProblemType{
+	{MalformedRequestCode, http.StatusBadRequest, "Malformed request"},
+	{InvalidParametersCode, http.StatusBadRequest, "Invalid parameters"},
+	{pkgGin.IdempotencyKeyTooLongCode, http.StatusBadRequest, "Idempotency key is too long"},
+	{pkgGin.UnreadableBodyCode, http.StatusBadRequest, "Request body can't be read"},
+	{NotFoundCode, http.StatusNotFound, "Not found"},
+	{apperror.ClientNotFoundCode, http.StatusNotFound, "Client not found"},
+	{apperror.ClientImportNotFoundCode, http.StatusNotFound, "Client import not found"},
+	{apperror.WebhookNotFoundCode, http.StatusNotFound, "Webhook not found"},
+	{pkgGin.IdempotencyKeyReusedCode, http.StatusConflict, "Idempotency key was reused"},
+	{pkgGin.IdempotencyKeyInProgressCode, http.StatusConflict, "Idempotency key is in progress"},
+	{apperror.ClientVersionMismatchCode, http.StatusPreconditionFailed, "Client version mismatch"},
+	{PayloadTooLargeCode, http.StatusRequestEntityTooLarge, "Payload too large"},
+	{UnsupportedMediaTypeCode, http.StatusUnsupportedMediaType, "Unsupported media type"},
+	{ValidationFailedCode, http.StatusUnprocessableEntity, "Validation failed"},
+	{apperror.ClientAlreadyExistsCode, http.StatusUnprocessableEntity, "Client already exists"},
+	{PreconditionRequiredCode, http.StatusPreconditionRequired, "Precondition required"},
+	internalServerErrorType,
+}
+
+// Error is an error of the request without an application error, the code has to be in ProblemTypes
+type Error struct {
+	code   string
+	detail string
+}
+
+func NewError(code, detail string) *Error {
+	return &Error{
+		code:   code,
+		detail: detail,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.detail
+}
+
+func (e *Error) Code() string {
+	return e.code
+}
+
 type InternalServerError struct{}
 
 func NewInternalServerError() *InternalServerError {
@@ -14,19 +77,27 @@ func NewInternalServerError() *InternalServerError {
 }
 
 func (e *InternalServerError) Error() string {
-	return "INTERNAL_SERVER_ERROR"
+	return "internal server error"
 }
 
-func MapError(err error) (int, error) {
-	var clientAlreadyExists *apperror.ClientAlreadyExists
-	var clientNotFound *apperror.ClientNotFound
+func (e *InternalServerError) Code() string {
+	return InternalServerErrorCode
+}
 
-	switch {
-	case errors.As(err, &clientAlreadyExists):
-		return http.StatusUnprocessableEntity, err
-	case errors.As(err, &clientNotFound):
-		return http.StatusNotFound, err
-	default:
-		return http.StatusInternalServerError, NewInternalServerError()
+// MapError finds the problem type of the error, errors without a cataloged code are internal server errors
+// and they are replaced so that their details don't leak
+func MapError(err error) (ProblemType, error) {
+	var coded interface {
+		error
+		Code() string
 	}
+	if errors.As(err, &coded) {
+		for _, t := range ProblemTypes {
+			if t.Code == coded.Code() {
+				return t, coded
+			}
+		}
+	}
+
+	return internalServerErrorType, NewInternalServerError()
 }
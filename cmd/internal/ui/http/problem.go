@@ -0,0 +1,66 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
+	pkgGin "github.com/jamm3e3333/whalebone-go-test-project/pkg/net/http/gin"
+)
+
+// errorHandledKey marks the request whose error was already handled by an inner ErrorMiddleware
+const errorHandledKey = "pkghttp.errorHandled"
+
+// Problem is rendered as application/problem+json for every error of the API
+type Problem struct {
+	pkgGin.Problem
+	// Violations are set when the request is malformed or fails the validation
+	Violations []FieldViolation `json:"violations,omitempty"`
+}
+
+// AbortWithError stops the request, the error is rendered by ErrorMiddleware
+func AbortWithError(ctx *gin.Context, err error) {
+	_ = ctx.Error(err)
+	ctx.Abort()
+}
+
+// ErrorMiddleware renders the last error of the request as a problem unless a response was already written,
+// internal server errors are logged since their details are not rendered. It is registered globally and also
+// after the middlewares that need the rendered response like the idempotency middleware.
+func ErrorMiddleware(lg logger.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		if len(ctx.Errors) == 0 || ctx.GetBool(errorHandledKey) {
+			return
+		}
+		ctx.Set(errorHandledKey, true)
+
+		err := ctx.Errors.Last().Err
+		problemType, mapped := MapError(err)
+		if problemType.Status >= http.StatusInternalServerError {
+			lg.WithContext(ctx.Request.Context()).ErrorWithMetadata("request failed", map[string]any{
+				"error":  err.Error(),
+				"method": ctx.Request.Method,
+				"path":   ctx.Request.URL.Path,
+			})
+		}
+		if ctx.Writer.Written() {
+			return
+		}
+
+		problem := Problem{
+			Problem: pkgGin.NewProblem(ctx, problemType.Status, problemType.Code, problemType.Title, mapped.Error()),
+		}
+		if verr, ok := mapped.(*ValidationError); ok {
+			problem.Violations = verr.Violations
+		}
+
+		pkgGin.AbortWithProblem(ctx, problemType.Status, &problem)
+	}
+}
+
+// NoRoute answers the requests of unknown routes with a problem
+func NoRoute(ctx *gin.Context) {
+	AbortWithError(ctx, NewError(NotFoundCode, "no route for "+ctx.Request.Method+" "+ctx.Request.URL.Path))
+}
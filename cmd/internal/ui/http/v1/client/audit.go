@@ -0,0 +1,21 @@
+package client
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/audit"
+)
+
+const (
+	headerActor     = "X-Actor"
+	headerRequestID = "X-Request-ID"
+)
+
+// auditContext carries the actor and the request id of the mutation into the client audit
+func auditContext(ctx *gin.Context) context.Context {
+	return audit.WithMeta(ctx, audit.Meta{
+		Actor:     ctx.GetHeader(headerActor),
+		RequestID: ctx.GetHeader(headerRequestID),
+	})
+}
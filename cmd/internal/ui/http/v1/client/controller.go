@@ -2,10 +2,8 @@ package client
 
 import (
 	"context"
-	"errors"
+	"encoding/json"
 	"net/http"
-	"net/mail"
-	"regexp"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -14,8 +12,6 @@ import (
 	pkghttp "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/ui/http"
 )
 
-var emailRegexPattern = `^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`
-
 const dateOfBirthLayout = "2006-01-02T15:04:05-07:00"
 
 type CreateClientHandler interface {
@@ -23,57 +19,99 @@ type CreateClientHandler interface {
 }
 
 type Controller struct {
-	createClientHandler CreateClientHandler
-	getClientHandler    GetClientHandler
+	createClientHandler  CreateClientHandler
+	getClientHandler     GetClientHandler
+	updateClientHandler  UpdateClientHandler
+	deleteClientHandler  DeleteClientHandler
+	listClientsHandler   ListClientsHandler
+	restoreClientHandler RestoreClientHandler
+	purgeClientHandler   PurgeClientHandler
+	clientHistoryHandler ClientHistoryHandler
+
+	importClientsHandler        ImportClientsHandler
+	scheduleClientImportHandler ScheduleClientImportHandler
+	getClientImportHandler      GetClientImportHandler
+	exportClientsHandler        ExportClientsHandler
+}
+
+type Handlers struct {
+	CreateClient  CreateClientHandler
+	GetClient     GetClientHandler
+	UpdateClient  UpdateClientHandler
+	DeleteClient  DeleteClientHandler
+	ListClients   ListClientsHandler
+	RestoreClient RestoreClientHandler
+	PurgeClient   PurgeClientHandler
+	ClientHistory ClientHistoryHandler
+
+	ImportClients        ImportClientsHandler
+	ScheduleClientImport ScheduleClientImportHandler
+	GetClientImport      GetClientImportHandler
+	ExportClients        ExportClientsHandler
 }
 
-func NewController(createClient CreateClientHandler, getClientHandler GetClientHandler) *Controller {
+func NewController(h Handlers) *Controller {
 	return &Controller{
-		createClientHandler: createClient,
-		getClientHandler:    getClientHandler,
+		createClientHandler:  h.CreateClient,
+		getClientHandler:     h.GetClient,
+		updateClientHandler:  h.UpdateClient,
+		deleteClientHandler:  h.DeleteClient,
+		listClientsHandler:   h.ListClients,
+		restoreClientHandler: h.RestoreClient,
+		purgeClientHandler:   h.PurgeClient,
+		clientHistoryHandler: h.ClientHistory,
+
+		importClientsHandler:        h.ImportClients,
+		scheduleClientImportHandler: h.ScheduleClientImport,
+		getClientImportHandler:      h.GetClientImport,
+		exportClientsHandler:        h.ExportClients,
 	}
 }
 
-func (c *Controller) Register(ge *gin.Engine) {
-	ge.POST("/v1/client", c.CreateClient)
+// Register registers the client routes, idempotency middleware guards the client creation and stores
+// the problems rendered by the error middleware
+func (c *Controller) Register(ge *gin.Engine, idempotency gin.HandlerFunc, errorMiddleware gin.HandlerFunc) {
+	ge.POST("/v1/client", idempotency, errorMiddleware, c.CreateClient)
+	ge.GET("/v1/client", c.ListClients)
 	ge.GET("/v1/client/:id", c.GetClient)
+	ge.PUT("/v1/client/:id", c.UpdateClient)
+	ge.PATCH("/v1/client/:id", c.PatchClient)
+	ge.DELETE("/v1/client/:id", c.DeleteClient)
+	ge.POST("/v1/client/:id/restore", c.RestoreClient)
+	ge.DELETE("/v1/client/:id/purge", c.PurgeClient)
+	ge.GET("/v1/client/:id/history", c.ClientHistory)
+	ge.POST("/v1/client/import", c.ImportClients)
+	ge.GET("/v1/client/import/:id", c.GetClientImport)
+	ge.GET("/v1/client/export", c.ExportClients)
 }
 
 type Header struct {
 	Value string `header:"Content-Type" example:"application/json" binding:"required"`
 }
 
+// CreateClientReq is validated by createClientDTOFactory so that all violations are reported together
 type CreateClientReq struct {
-	Email       string `json:"email" binding:"required"`
-	DateOfBirth string `json:"date_of_birth" binding:"required"`
-	Name        string `json:"name" binding:"required"`
-	ID          string `json:"id" binding:"required"`
+	Email       string `json:"email" validate:"required" example:"john.doe@whalebone.io"`
+	DateOfBirth string `json:"date_of_birth" validate:"required" example:"1990-01-01T00:00:00+00:00"`
+	Name        string `json:"name" validate:"required" minLength:"2" maxLength:"100" example:"John Doe"`
+	ID          string `json:"id" validate:"required" example:"123e4567-e89b-12d3-a456-426614174000"`
 }
 
+// createClientDTOFactory reports all violations of the request at once
 func createClientDTOFactory(r CreateClientReq) (handler.CreateClientDTO, error) {
-	emailRegex := regexp.MustCompile(emailRegexPattern)
-	if ok := emailRegex.MatchString(r.Email); !ok {
-		return handler.CreateClientDTO{}, errors.New("invalid email")
-	}
+	v := pkghttp.NewValidationError()
 
-	email, err := mail.ParseAddress(r.Email)
-	if err != nil {
-		return handler.CreateClientDTO{}, errors.New("invalid email")
-	}
-
-	dateOfBirth, err := time.Parse(dateOfBirthLayout, r.DateOfBirth)
-	if err != nil {
-		return handler.CreateClientDTO{}, errors.New("invalid date of birth")
-	}
-
-	clientUUID, err := uuid.Parse(r.ID)
-	if err != nil {
-		return handler.CreateClientDTO{}, errors.New("invalid client id")
+	email := validateEmail(v, r.Email)
+	validateName(v, r.Name)
+	dateOfBirth := validateDateOfBirth(v, r.DateOfBirth, time.Now())
+	clientUUID := validateClientID(v, r.ID)
+	if err := v.Err(); err != nil {
+		return handler.CreateClientDTO{}, err
 	}
 
 	return handler.CreateClientDTO{
 		Name:        r.Name,
-		Email:       email.String(),
+		Email:       email,
 		DateOfBirth: dateOfBirth,
 		ClientUUID:  clientUUID,
 	}, nil
@@ -82,40 +120,44 @@ func createClientDTOFactory(r CreateClientReq) (handler.CreateClientDTO, error)
 // CreateClient godoc
 // @Summary Create a new client
 // @Description Creates a new client account with the provided details such as email, date of birth, name, and id.
+// @Description The name has 2 to 100 letters, spaces, apostrophes, dots or hyphens and the date of birth is neither in the future nor more than 150 years ago. All violations of the request are reported together.
 // @Tags Client
 // @Accept json
 // @Produce json
 // @Param Content-Type header string true "Content-Type" example(application/json)
+// @Param Idempotency-Key header string false "Unique key of the request, retries with the same key and body replay the first response" example(8e03978e-40d5-43e8-bc93-6894a57f9324)
+// @Param X-Actor header string false "Actor of the change recorded in the client history" example(john.doe@whalebone.io)
+// @Param X-Request-ID header string false "Request ID recorded in the client history" example(8e03978e-40d5-43e8-bc93-6894a57f9324)
 // @Param data body CreateClientReq true "Client data"
 // @Success 201 {string} string "Created"
-// @Failure 400 {object} map[string]string "{"error": "bad request"}"
-// @Failure 422 {object} map[string]string "{"error": "unprocessable entity"}"
-// @Failure 500 {object} map[string]string "{"error": "internal server error"}"
+// @Failure 400 {object} pkghttp.Problem "malformed_request or invalid_parameters with the violations, idempotency_key_too_long, or unreadable_body"
+// @Failure 409 {object} pkghttp.Problem "idempotency_key_reused, or idempotency_key_in_progress"
+// @Failure 422 {object} pkghttp.Problem "validation_failed with the violations, or client_already_exists"
+// @Failure 500 {object} pkghttp.Problem "internal_server_error"
 // @Router /v1/client [post]
 func (c *Controller) CreateClient(ctx *gin.Context) {
 	var h Header
 	err := ctx.ShouldBindHeader(&h)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		pkghttp.AbortWithError(ctx, pkghttp.MapParamBindingError(err))
 		return
 	}
 
 	var req CreateClientReq
 	err = ctx.ShouldBindBodyWithJSON(&req)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		pkghttp.AbortWithError(ctx, pkghttp.MapBindingError(err))
 		return
 	}
 	clientDTO, err := createClientDTOFactory(req)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		pkghttp.AbortWithError(ctx, err)
 		return
 	}
 
-	err = c.createClientHandler.Handle(ctx, clientDTO)
+	err = c.createClientHandler.Handle(auditContext(ctx), clientDTO)
 	if err != nil {
-		statusCode, err := pkghttp.MapError(err)
-		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		pkghttp.AbortWithError(ctx, err)
 		return
 	}
 
@@ -139,28 +181,35 @@ type GetClientHandler interface {
 // @Tags Client
 // @Produce json
 // @Param id path string true "Client ID" example("123e4567-e89b-12d3-a456-426614174000")
+// @Param If-None-Match header string false "Entity tags of the client, the client is not returned when one of them is current" example("42")
 // @Success 200 {object} GetClientResponse "Client details"
-// @Failure 400 {object} map[string]string "{"error": "bad request"}"
-// @Failure 404 {object} map[string]string "{"error": "not found"}"
-// @Failure 422 {object} map[string]string "{"error": "unprocessable entity"}"
-// @Failure 500 {object} map[string]string "{"error": "internal server error"}"
+// @Header 200 {string} ETag "Entity tag of the current client version"
+// @Success 304 {string} string "Not Modified"
+// @Failure 404 {object} pkghttp.Problem "client_not_found, or not_found for a malformed client id"
+// @Failure 500 {object} pkghttp.Problem "internal_server_error"
 // @Router /v1/client/{id} [get]
 func (c *Controller) GetClient(ctx *gin.Context) {
 	clientIDParam := ctx.Param("id")
 	if clientIDParam == "" {
-		ctx.AbortWithStatus(http.StatusNotFound)
+		pkghttp.AbortWithError(ctx, pkghttp.NewError(pkghttp.NotFoundCode, "client id has to be a UUID"))
 		return
 	}
 	clientUUID, err := uuid.Parse(clientIDParam)
 	if err != nil {
-		ctx.AbortWithStatus(http.StatusNotFound)
+		pkghttp.AbortWithError(ctx, pkghttp.NewError(pkghttp.NotFoundCode, "client id has to be a UUID"))
 		return
 	}
 
 	client, err := c.getClientHandler.Handle(ctx, clientUUID)
 	if err != nil {
-		statusCode, err := pkghttp.MapError(err)
-		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		pkghttp.AbortWithError(ctx, err)
+		return
+	}
+
+	etag := clientETag(client.Version)
+	ctx.Header(headerETag, etag)
+	if ifNoneMatch(ctx.GetHeader(headerIfNoneMatch), etag) {
+		ctx.AbortWithStatus(http.StatusNotModified)
 		return
 	}
 
@@ -173,3 +222,532 @@ func (c *Controller) GetClient(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, &response)
 }
+
+const mergePatchContentType = "application/merge-patch+json"
+
+type UpdateClientHandler interface {
+	Handle(ctx context.Context, dto handler.UpdateClientDTO) (int64, error)
+}
+
+type UpdateClientReq struct {
+	Email       string `json:"email" validate:"required" example:"john.doe@whalebone.io"`
+	DateOfBirth string `json:"date_of_birth" validate:"required" example:"1990-01-01T00:00:00+00:00"`
+	Name        string `json:"name" validate:"required" minLength:"2" maxLength:"100" example:"John Doe"`
+}
+
+func updateClientDTOFactory(clientUUID uuid.UUID, expectedVersions []int64, r UpdateClientReq) (handler.UpdateClientDTO, error) {
+	dto, err := createClientDTOFactory(CreateClientReq{
+		Email:       r.Email,
+		DateOfBirth: r.DateOfBirth,
+		Name:        r.Name,
+		ID:          clientUUID.String(),
+	})
+	if err != nil {
+		return handler.UpdateClientDTO{}, err
+	}
+
+	return handler.UpdateClientDTO{
+		Name:             dto.Name,
+		Email:            dto.Email,
+		DateOfBirth:      dto.DateOfBirth,
+		ClientUUID:       dto.ClientUUID,
+		ExpectedVersions: expectedVersions,
+	}, nil
+}
+
+// UpdateClient godoc
+// @Summary Replace a client
+// @Description Replaces all details of an existing client identified by the provided client ID.
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Param Content-Type header string true "Content-Type" example(application/json)
+// @Param If-Match header string true "Entity tag of the client returned by GET, * matches any version" example("42")
+// @Param id path string true "Client ID" example("123e4567-e89b-12d3-a456-426614174000")
+// @Param X-Actor header string false "Actor of the change recorded in the client history" example(john.doe@whalebone.io)
+// @Param X-Request-ID header string false "Request ID recorded in the client history" example(8e03978e-40d5-43e8-bc93-6894a57f9324)
+// @Param data body UpdateClientReq true "Client data"
+// @Success 204 {string} string "No Content"
+// @Header 204 {string} ETag "Entity tag of the updated client version"
+// @Failure 400 {object} pkghttp.Problem "malformed_request with the violations"
+// @Failure 404 {object} pkghttp.Problem "client_not_found, or not_found for a malformed client id"
+// @Failure 412 {object} pkghttp.Problem "client_version_mismatch"
+// @Failure 422 {object} pkghttp.Problem "validation_failed with the violations, or client_already_exists"
+// @Failure 428 {object} pkghttp.Problem "precondition_required"
+// @Failure 500 {object} pkghttp.Problem "internal_server_error"
+// @Router /v1/client/{id} [put]
+func (c *Controller) UpdateClient(ctx *gin.Context) {
+	clientUUID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		pkghttp.AbortWithError(ctx, pkghttp.NewError(pkghttp.NotFoundCode, "client id has to be a UUID"))
+		return
+	}
+
+	var h Header
+	err = ctx.ShouldBindHeader(&h)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, pkghttp.MapParamBindingError(err))
+		return
+	}
+
+	expectedVersions, ok := requireIfMatch(ctx)
+	if !ok {
+		return
+	}
+
+	var req UpdateClientReq
+	err = ctx.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, pkghttp.MapBindingError(err))
+		return
+	}
+
+	c.updateClient(ctx, clientUUID, expectedVersions, req)
+}
+
+// PatchClient godoc
+// @Summary Partially update a client
+// @Description Applies a JSON merge patch (RFC 7386) to an existing client identified by the provided client ID.
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Param Content-Type header string true "Content-Type" example(application/merge-patch+json)
+// @Param If-Match header string true "Entity tag of the client returned by GET, * matches any version" example("42")
+// @Param id path string true "Client ID" example("123e4567-e89b-12d3-a456-426614174000")
+// @Param X-Actor header string false "Actor of the change recorded in the client history" example(john.doe@whalebone.io)
+// @Param X-Request-ID header string false "Request ID recorded in the client history" example(8e03978e-40d5-43e8-bc93-6894a57f9324)
+// @Param data body UpdateClientReq true "Client data to be merged"
+// @Success 204 {string} string "No Content"
+// @Header 204 {string} ETag "Entity tag of the updated client version"
+// @Failure 400 {object} pkghttp.Problem "malformed_request with the violations"
+// @Failure 404 {object} pkghttp.Problem "client_not_found, or not_found for a malformed client id"
+// @Failure 412 {object} pkghttp.Problem "client_version_mismatch"
+// @Failure 415 {object} pkghttp.Problem "unsupported_media_type"
+// @Failure 422 {object} pkghttp.Problem "validation_failed with the violations, or client_already_exists"
+// @Failure 428 {object} pkghttp.Problem "precondition_required"
+// @Failure 500 {object} pkghttp.Problem "internal_server_error"
+// @Router /v1/client/{id} [patch]
+func (c *Controller) PatchClient(ctx *gin.Context) {
+	clientUUID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		pkghttp.AbortWithError(ctx, pkghttp.NewError(pkghttp.NotFoundCode, "client id has to be a UUID"))
+		return
+	}
+
+	var h Header
+	err = ctx.ShouldBindHeader(&h)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, pkghttp.MapParamBindingError(err))
+		return
+	}
+	if ct := ctx.ContentType(); ct != mergePatchContentType && ct != gin.MIMEJSON {
+		pkghttp.AbortWithError(ctx, pkghttp.NewError(pkghttp.UnsupportedMediaTypeCode, "unsupported content type "+ct))
+		return
+	}
+
+	expectedVersions, ok := requireIfMatch(ctx)
+	if !ok {
+		return
+	}
+
+	patch, err := ctx.GetRawData()
+	if err != nil {
+		pkghttp.AbortWithError(ctx, pkghttp.NewError(pkghttp.MalformedRequestCode, err.Error()))
+		return
+	}
+
+	// the patch is applied on the latest version, a stale one would fail the If-Match check or lose newer changes
+	client, err := c.getClientHandler.Handle(handler.ReadLatest(ctx), clientUUID)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, err)
+		return
+	}
+	// the read version must not change before the update
+	if expectedVersions == nil {
+		expectedVersions = []int64{client.Version}
+	}
+
+	req, err := applyMergePatch(UpdateClientReq{
+		Email:       client.Email,
+		DateOfBirth: client.DateOfBirth,
+		Name:        client.Name,
+	}, patch)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, pkghttp.MapBindingError(err))
+		return
+	}
+
+	c.updateClient(ctx, clientUUID, expectedVersions, req)
+}
+
+func (c *Controller) updateClient(ctx *gin.Context, clientUUID uuid.UUID, expectedVersions []int64, req UpdateClientReq) {
+	clientDTO, err := updateClientDTOFactory(clientUUID, expectedVersions, req)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, err)
+		return
+	}
+
+	version, err := c.updateClientHandler.Handle(auditContext(ctx), clientDTO)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.Header(headerETag, clientETag(version))
+	ctx.AbortWithStatus(http.StatusNoContent)
+}
+
+// requireIfMatch aborts with 428 Precondition Required when the If-Match header is missing
+func requireIfMatch(ctx *gin.Context) ([]int64, bool) {
+	header := ctx.GetHeader(headerIfMatch)
+	if header == "" {
+		pkghttp.AbortWithError(ctx, pkghttp.NewError(pkghttp.PreconditionRequiredCode, "If-Match header is required"))
+		return nil, false
+	}
+
+	return ifMatchVersions(header), true
+}
+
+type DeleteClientHandler interface {
+	Handle(ctx context.Context, dto handler.DeleteClientDTO) error
+}
+
+// DeleteClient godoc
+// @Summary Delete a client
+// @Description Soft deletes a client identified by the provided client ID. Deleted client can be restored until it is purged.
+// @Tags Client
+// @Produce json
+// @Param If-Match header string true "Entity tag of the client returned by GET, * matches any version" example("42")
+// @Param id path string true "Client ID" example("123e4567-e89b-12d3-a456-426614174000")
+// @Param X-Actor header string false "Actor of the change recorded in the client history" example(john.doe@whalebone.io)
+// @Param X-Request-ID header string false "Request ID recorded in the client history" example(8e03978e-40d5-43e8-bc93-6894a57f9324)
+// @Success 204 {string} string "No Content"
+// @Failure 404 {object} pkghttp.Problem "client_not_found, or not_found for a malformed client id"
+// @Failure 412 {object} pkghttp.Problem "client_version_mismatch"
+// @Failure 428 {object} pkghttp.Problem "precondition_required"
+// @Failure 500 {object} pkghttp.Problem "internal_server_error"
+// @Router /v1/client/{id} [delete]
+func (c *Controller) DeleteClient(ctx *gin.Context) {
+	clientUUID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		pkghttp.AbortWithError(ctx, pkghttp.NewError(pkghttp.NotFoundCode, "client id has to be a UUID"))
+		return
+	}
+
+	expectedVersions, ok := requireIfMatch(ctx)
+	if !ok {
+		return
+	}
+
+	err = c.deleteClientHandler.Handle(auditContext(ctx), handler.DeleteClientDTO{
+		ClientUUID:       clientUUID,
+		ExpectedVersions: expectedVersions,
+	})
+	if err != nil {
+		pkghttp.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.AbortWithStatus(http.StatusNoContent)
+}
+
+const (
+	defaultListClientsLimit = 50
+	maxListClientsLimit     = 500
+)
+
+type ListClientsHandler interface {
+	Handle(ctx context.Context, q handler.ListClientsQuery) (handler.ListClientsDTO, error)
+}
+
+type ListClientsReq struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=500"`
+	ClientFilterReq
+}
+
+// ClientFilterReq is shared by the client listing and export
+type ClientFilterReq struct {
+	EmailDomain     string `form:"email_domain"`
+	NamePrefix      string `form:"name_prefix"`
+	DateOfBirthFrom string `form:"date_of_birth_from"`
+	DateOfBirthTo   string `form:"date_of_birth_to"`
+	CreatedAtFrom   string `form:"created_at_from"`
+	CreatedAtTo     string `form:"created_at_to"`
+}
+
+type ListClientsResponse struct {
+	Items      []GetClientResponse `json:"items"`
+	NextCursor string              `json:"next_cursor,omitempty" example:"MTIz"`
+}
+
+func listClientsQueryFactory(r ListClientsReq) (handler.ListClientsQuery, error) {
+	v := pkghttp.NewInvalidParametersError()
+
+	filter := validateClientFilter(v, r.ClientFilterReq)
+	afterID, err := pkghttp.DecodeCursor(r.Cursor)
+	if err != nil {
+		v.Add("cursor", pkghttp.ViolationInvalidFormat, "invalid cursor")
+	}
+	if err := v.Err(); err != nil {
+		return handler.ListClientsQuery{}, err
+	}
+
+	limit := r.Limit
+	if limit == 0 {
+		limit = defaultListClientsLimit
+	}
+
+	return handler.ListClientsQuery{
+		Filter:  filter,
+		AfterID: afterID,
+		Limit:   min(limit, maxListClientsLimit),
+	}, nil
+}
+
+func clientFilterFactory(r ClientFilterReq) (handler.ClientFilter, error) {
+	v := pkghttp.NewInvalidParametersError()
+	filter := validateClientFilter(v, r)
+
+	return filter, v.Err()
+}
+
+func validateClientFilter(v *pkghttp.ValidationError, r ClientFilterReq) handler.ClientFilter {
+	filter := handler.ClientFilter{
+		EmailDomain: r.EmailDomain,
+		NamePrefix:  r.NamePrefix,
+	}
+
+	for _, t := range []struct {
+		value string
+		dst   **time.Time
+		name  string
+	}{
+		{r.DateOfBirthFrom, &filter.DateOfBirthFrom, "date_of_birth_from"},
+		{r.DateOfBirthTo, &filter.DateOfBirthTo, "date_of_birth_to"},
+		{r.CreatedAtFrom, &filter.CreatedAtFrom, "created_at_from"},
+		{r.CreatedAtTo, &filter.CreatedAtTo, "created_at_to"},
+	} {
+		if t.value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, t.value)
+		if err != nil {
+			v.Add(t.name, pkghttp.ViolationInvalidFormat, "invalid "+t.name)
+			continue
+		}
+		*t.dst = &parsed
+	}
+
+	return filter
+}
+
+// ListClients godoc
+// @Summary List clients
+// @Description Lists clients ordered by creation with keyset pagination. Pass the returned next_cursor as cursor to get the next page, there are no more clients when next_cursor is missing.
+// @Description Date filters are RFC 3339 timestamps, the "from" bound is inclusive and the "to" bound is exclusive.
+// @Tags Client
+// @Produce json
+// @Param cursor query string false "Cursor of the page returned as next_cursor"
+// @Param limit query int false "Page size" default(50) minimum(1) maximum(500)
+// @Param email_domain query string false "Email domain" example(whalebone.io)
+// @Param name_prefix query string false "Name prefix" example(John)
+// @Param date_of_birth_from query string false "Date of birth from" format(date-time)
+// @Param date_of_birth_to query string false "Date of birth to" format(date-time)
+// @Param created_at_from query string false "Created at from" format(date-time)
+// @Param created_at_to query string false "Created at to" format(date-time)
+// @Success 200 {object} ListClientsResponse "Page of clients"
+// @Failure 400 {object} pkghttp.Problem "invalid_parameters with the violations"
+// @Failure 500 {object} pkghttp.Problem "internal_server_error"
+// @Router /v1/client [get]
+func (c *Controller) ListClients(ctx *gin.Context) {
+	var req ListClientsReq
+	err := ctx.ShouldBindQuery(&req)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, pkghttp.MapParamBindingError(err))
+		return
+	}
+
+	q, err := listClientsQueryFactory(req)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, err)
+		return
+	}
+
+	clients, err := c.listClientsHandler.Handle(ctx, q)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, err)
+		return
+	}
+
+	response := ListClientsResponse{
+		Items:      make([]GetClientResponse, 0, len(clients.Clients)),
+		NextCursor: pkghttp.EncodeCursor(clients.NextAfterID),
+	}
+	for _, client := range clients.Clients {
+		response.Items = append(response.Items, GetClientResponse{
+			Name:        client.Name,
+			Email:       client.Email,
+			DateOfBirth: client.DateOfBirth,
+			ID:          client.ClientUUID.String(),
+		})
+	}
+
+	ctx.JSON(http.StatusOK, &response)
+}
+
+type RestoreClientHandler interface {
+	Handle(ctx context.Context, clientUUID uuid.UUID) error
+}
+
+// RestoreClient godoc
+// @Summary Restore a deleted client
+// @Description Restores a soft deleted client identified by the provided client ID.
+// @Tags Client
+// @Produce json
+// @Param id path string true "Client ID" example("123e4567-e89b-12d3-a456-426614174000")
+// @Param X-Actor header string false "Actor of the change recorded in the client history" example(john.doe@whalebone.io)
+// @Param X-Request-ID header string false "Request ID recorded in the client history" example(8e03978e-40d5-43e8-bc93-6894a57f9324)
+// @Success 204 {string} string "No Content"
+// @Failure 404 {object} pkghttp.Problem "client_not_found, or not_found for a malformed client id"
+// @Failure 422 {object} pkghttp.Problem "client_already_exists"
+// @Failure 500 {object} pkghttp.Problem "internal_server_error"
+// @Router /v1/client/{id}/restore [post]
+func (c *Controller) RestoreClient(ctx *gin.Context) {
+	clientUUID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		pkghttp.AbortWithError(ctx, pkghttp.NewError(pkghttp.NotFoundCode, "client id has to be a UUID"))
+		return
+	}
+
+	err = c.restoreClientHandler.Handle(auditContext(ctx), clientUUID)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.AbortWithStatus(http.StatusNoContent)
+}
+
+type PurgeClientHandler interface {
+	Handle(ctx context.Context, clientUUID uuid.UUID) error
+}
+
+// PurgeClient godoc
+// @Summary Purge a deleted client
+// @Description Permanently removes a soft deleted client identified by the provided client ID. The client has to be deleted first.
+// @Tags Client
+// @Produce json
+// @Param id path string true "Client ID" example("123e4567-e89b-12d3-a456-426614174000")
+// @Param X-Actor header string false "Actor of the change recorded in the client history" example(john.doe@whalebone.io)
+// @Param X-Request-ID header string false "Request ID recorded in the client history" example(8e03978e-40d5-43e8-bc93-6894a57f9324)
+// @Success 204 {string} string "No Content"
+// @Failure 404 {object} pkghttp.Problem "client_not_found, or not_found for a malformed client id"
+// @Failure 500 {object} pkghttp.Problem "internal_server_error"
+// @Router /v1/client/{id}/purge [delete]
+func (c *Controller) PurgeClient(ctx *gin.Context) {
+	clientUUID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		pkghttp.AbortWithError(ctx, pkghttp.NewError(pkghttp.NotFoundCode, "client id has to be a UUID"))
+		return
+	}
+
+	err = c.purgeClientHandler.Handle(auditContext(ctx), clientUUID)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.AbortWithStatus(http.StatusNoContent)
+}
+
+type ClientHistoryHandler interface {
+	Handle(ctx context.Context, q handler.ListClientHistoryQuery) (handler.ClientHistoryDTO, error)
+}
+
+type ClientHistoryReq struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=500"`
+}
+
+type ClientHistoryEntryResponse struct {
+	Action    string          `json:"action" example:"update" enums:"create,update,delete,restore,purge"`
+	Before    json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After     json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	Actor     string          `json:"actor,omitempty" example:"john.doe@whalebone.io"`
+	RequestID string          `json:"request_id,omitempty" example:"8e03978e-40d5-43e8-bc93-6894a57f9324"`
+	CreatedAt string          `json:"created_at" format:"date-time" example:"2021-01-01T00:00:00Z"`
+}
+
+type ClientHistoryResponse struct {
+	Items      []ClientHistoryEntryResponse `json:"items"`
+	NextCursor string                       `json:"next_cursor,omitempty" example:"MTIz"`
+}
+
+// ClientHistory godoc
+// @Summary Get client change history
+// @Description Lists changes of the client from the oldest with keyset pagination. Every entry contains the client before and after the change, the actor and the request ID.
+// @Tags Client
+// @Produce json
+// @Param id path string true "Client ID" example("123e4567-e89b-12d3-a456-426614174000")
+// @Param cursor query string false "Cursor of the page returned as next_cursor"
+// @Param limit query int false "Page size" default(50) minimum(1) maximum(500)
+// @Success 200 {object} ClientHistoryResponse "Page of client changes"
+// @Failure 400 {object} pkghttp.Problem "invalid_parameters with the violations"
+// @Failure 404 {object} pkghttp.Problem "client_not_found, or not_found for a malformed client id"
+// @Failure 500 {object} pkghttp.Problem "internal_server_error"
+// @Router /v1/client/{id}/history [get]
+func (c *Controller) ClientHistory(ctx *gin.Context) {
+	clientUUID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		pkghttp.AbortWithError(ctx, pkghttp.NewError(pkghttp.NotFoundCode, "client id has to be a UUID"))
+		return
+	}
+
+	var req ClientHistoryReq
+	err = ctx.ShouldBindQuery(&req)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, pkghttp.MapParamBindingError(err))
+		return
+	}
+
+	afterID, err := pkghttp.DecodeCursor(req.Cursor)
+	if err != nil {
+		v := pkghttp.NewInvalidParametersError()
+		v.Add("cursor", pkghttp.ViolationInvalidFormat, "invalid cursor")
+		pkghttp.AbortWithError(ctx, v)
+		return
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultListClientsLimit
+	}
+
+	history, err := c.clientHistoryHandler.Handle(ctx, handler.ListClientHistoryQuery{
+		ClientUUID: clientUUID,
+		AfterID:    afterID,
+		Limit:      min(limit, maxListClientsLimit),
+	})
+	if err != nil {
+		pkghttp.AbortWithError(ctx, err)
+		return
+	}
+
+	response := ClientHistoryResponse{
+		Items:      make([]ClientHistoryEntryResponse, 0, len(history.Entries)),
+		NextCursor: pkghttp.EncodeCursor(history.NextAfterID),
+	}
+	for _, entry := range history.Entries {
+		response.Items = append(response.Items, ClientHistoryEntryResponse{
+			Action:    entry.Action,
+			Before:    entry.Before,
+			After:     entry.After,
+			Actor:     entry.Actor,
+			RequestID: entry.RequestID,
+			CreatedAt: entry.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	ctx.JSON(http.StatusOK, &response)
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	headerETag        = "ETag"
+	headerIfMatch     = "If-Match"
+	headerIfNoneMatch = "If-None-Match"
+	weakETagPrefix    = "W/"
+	anyETag           = "*"
+)
+
+// clientETag is a strong entity tag of the client version
+func clientETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ifMatchVersions parses the If-Match header (RFC 9110) into the client versions, nil stands for any version.
+// Weak and foreign entity tags never match, so they are skipped.
+func ifMatchVersions(header string) []int64 {
+	if strings.TrimSpace(header) == anyETag {
+		return nil
+	}
+
+	versions := make([]int64, 0)
+	for _, tag := range splitETags(header) {
+		if strings.HasPrefix(tag, weakETagPrefix) {
+			continue
+		}
+		version, err := strconv.ParseInt(strings.Trim(tag, `"`), 10, 64)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, version)
+	}
+
+	return versions
+}
+
+// ifNoneMatch uses the weak comparison of the If-None-Match header (RFC 9110) with the current entity tag
+func ifNoneMatch(header string, etag string) bool {
+	if header == "" {
+		return false
+	}
+	if strings.TrimSpace(header) == anyETag {
+		return true
+	}
+
+	for _, tag := range splitETags(header) {
+		if strings.TrimPrefix(tag, weakETagPrefix) == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func splitETags(header string) []string {
+	tags := strings.Split(header, ",")
+	for i, tag := range tags {
+		tags[i] = strings.TrimSpace(tag)
+	}
+
+	return tags
+}
@@ -0,0 +1,215 @@
+package client
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+	pkghttp "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/ui/http"
+)
+
+const (
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+	exportFormatJSON   = "json"
+
+	// exportFlushRows is the number of exported clients sent to the client at once
+	exportFlushRows = 1000
+)
+
+type ExportClientsHandler interface {
+	Handle(ctx context.Context, filter handler.ClientFilter, f func(client handler.GetClientDTO) error) error
+}
+
+type ExportClientsReq struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv ndjson json"`
+	ClientFilterReq
+}
+
+// clientExportWriter writes the exported clients in one of the export formats
+type clientExportWriter interface {
+	begin() error
+	write(client GetClientResponse) error
+	end() error
+}
+
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func (e *csvExportWriter) begin() error {
+	return e.w.Write([]string{"id", "email", "name", "date_of_birth"})
+}
+
+func (e *csvExportWriter) write(client GetClientResponse) error {
+	return e.w.Write([]string{client.ID, client.Email, client.Name, client.DateOfBirth})
+}
+
+func (e *csvExportWriter) end() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type ndjsonExportWriter struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonExportWriter) begin() error {
+	return nil
+}
+
+func (e *ndjsonExportWriter) write(client GetClientResponse) error {
+	return e.enc.Encode(&client)
+}
+
+func (e *ndjsonExportWriter) end() error {
+	return nil
+}
+
+type jsonExportWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonExportWriter) begin() error {
+	_, err := io.WriteString(e.w, "[")
+	return err
+}
+
+func (e *jsonExportWriter) write(client GetClientResponse) error {
+	if e.count > 0 {
+		_, err := io.WriteString(e.w, ",")
+		if err != nil {
+			return err
+		}
+	}
+	e.count++
+
+	b, err := json.Marshal(&client)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(b)
+	return err
+}
+
+func (e *jsonExportWriter) end() error {
+	_, err := io.WriteString(e.w, "]")
+	return err
+}
+
+func newClientExportWriter(format string, w io.Writer) (clientExportWriter, string, string) {
+	switch format {
+	case exportFormatNDJSON:
+		return &ndjsonExportWriter{enc: json.NewEncoder(w)}, ndjsonContentType, "clients.ndjson"
+	case exportFormatJSON:
+		return &jsonExportWriter{w: w}, "application/json", "clients.json"
+	default:
+		return &csvExportWriter{w: csv.NewWriter(w)}, csvContentType, "clients.csv"
+	}
+}
+
+// ExportClients godoc
+// @Summary Export clients
+// @Description Streams all clients ordered by creation as CSV with the header row id,email,name,date_of_birth, as NDJSON with a client on every line or as a JSON array. The export can be imported back with POST /v1/client/import.
+// @Description Filters are the same as for the client listing. When the export fails after it started the connection is closed, so an incomplete export is never mistaken for a complete one.
+// @Tags Client
+// @Produce text/csv,application/x-ndjson,json
+// @Param format query string false "Format of the export" Enums(csv, ndjson, json) default(csv)
+// @Param email_domain query string false "Email domain" example(whalebone.io)
+// @Param name_prefix query string false "Name prefix" example(John)
+// @Param date_of_birth_from query string false "Date of birth from" format(date-time)
+// @Param date_of_birth_to query string false "Date of birth to" format(date-time)
+// @Param created_at_from query string false "Created at from" format(date-time)
+// @Param created_at_to query string false "Created at to" format(date-time)
+// @Success 200 {array} GetClientResponse "Clients"
+// @Header 200 {string} Content-Disposition "attachment; filename=clients.csv"
+// @Failure 400 {object} pkghttp.Problem "invalid_parameters with the violations"
+// @Failure 500 {object} pkghttp.Problem "internal_server_error"
+// @Router /v1/client/export [get]
+func (c *Controller) ExportClients(ctx *gin.Context) {
+	var req ExportClientsReq
+	err := ctx.ShouldBindQuery(&req)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, pkghttp.MapParamBindingError(err))
+		return
+	}
+
+	filter, err := clientFilterFactory(req.ClientFilterReq)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, err)
+		return
+	}
+
+	// the export takes as long as it needs, the client disconnect cancels it through the request context
+	_ = http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{})
+
+	export, contentType, filename := newClientExportWriter(req.Format, ctx.Writer)
+	started := false
+	start := func() error {
+		started = true
+		ctx.Header("Content-Type", contentType)
+		ctx.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+		ctx.Status(http.StatusOK)
+
+		return export.begin()
+	}
+
+	exported := 0
+	err = c.exportClientsHandler.Handle(ctx, filter, func(client handler.GetClientDTO) error {
+		if !started {
+			err := start()
+			if err != nil {
+				return err
+			}
+		}
+
+		err := export.write(GetClientResponse{
+			Name:        client.Name,
+			Email:       client.Email,
+			DateOfBirth: client.DateOfBirth,
+			ID:          client.ClientUUID.String(),
+		})
+		if err != nil {
+			return err
+		}
+
+		exported++
+		if exported%exportFlushRows == 0 {
+			ctx.Writer.Flush()
+		}
+
+		return nil
+	})
+	if err == nil && !started {
+		err = start()
+	}
+	if err == nil {
+		err = export.end()
+	}
+	if err != nil {
+		if !started {
+			pkghttp.AbortWithError(ctx, err)
+			return
+		}
+
+		_ = ctx.Error(err)
+		abortStream(ctx)
+	}
+}
+
+// abortStream closes the connection without finishing the response, so the client sees the response is incomplete
+func abortStream(ctx *gin.Context) {
+	ctx.Abort()
+
+	conn, _, err := ctx.Writer.Hijack()
+	if err != nil {
+		return
+	}
+	_ = conn.Close()
+}
@@ -0,0 +1,301 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+	pkghttp "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/ui/http"
+)
+
+const (
+	csvContentType    = "text/csv"
+	ndjsonContentType = "application/x-ndjson"
+
+	// syncImportMaxRows is the largest import answered with the report, larger imports run as a job
+	syncImportMaxRows = 1000
+	// the rows are decoded in memory and scheduled as one job, the limits keep both within a few tens of MiB
+	maxImportRows     = 50_000
+	maxImportBodySize = 8 << 20
+	maxImportLineSize = 1 << 20
+)
+
+var errTooManyImportRows = fmt.Errorf("import has more than %d rows", maxImportRows)
+
+type ImportClientsHandler interface {
+	Handle(ctx context.Context, rows []handler.ClientImportRow) (handler.ClientImportReportDTO, error)
+}
+
+type ScheduleClientImportHandler interface {
+	Handle(ctx context.Context, rows []handler.ClientImportRow) (handler.ClientImportDTO, error)
+}
+
+type GetClientImportHandler interface {
+	Handle(ctx context.Context, importUUID uuid.UUID) (handler.ClientImportDTO, error)
+}
+
+type ClientImportRowResponse struct {
+	Row    int    `json:"row" example:"1"`
+	ID     string `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Status string `json:"status" example:"accepted" enums:"accepted,duplicate,invalid"`
+	Error  string `json:"error,omitempty" example:"invalid email"`
+}
+
+type ClientImportReportResponse struct {
+	Total     int                       `json:"total" example:"3"`
+	Accepted  int                       `json:"accepted" example:"1"`
+	Duplicate int                       `json:"duplicate" example:"1"`
+	Invalid   int                       `json:"invalid" example:"1"`
+	Rows      []ClientImportRowResponse `json:"rows"`
+}
+
+type ClientImportResponse struct {
+	ID         string `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Status     string `json:"status" example:"completed" enums:"pending,running,completed,failed"`
+	TotalRows  int    `json:"total_rows" example:"3"`
+	CreatedAt  string `json:"created_at" format:"date-time" example:"2021-01-01T00:00:00Z"`
+	FinishedAt string `json:"finished_at,omitempty" format:"date-time" example:"2021-01-01T00:00:00Z"`
+	// Report is set once the import is completed
+	Report *ClientImportReportResponse `json:"report,omitempty"`
+	Error  string                      `json:"error,omitempty" example:"INTERNAL_SERVER_ERROR"`
+}
+
+// importClientRow validates the row the same way as the client creation
+func importClientRow(row int, r CreateClientReq) handler.ClientImportRow {
+	importRow := handler.ClientImportRow{
+		Row: row,
+		ID:  r.ID,
+	}
+
+	clientDTO, err := createClientDTOFactory(r)
+	if err != nil {
+		importRow.Error = err.Error()
+		return importRow
+	}
+
+	importRow.Client = clientDTO
+	return importRow
+}
+
+// parseCSVImport expects a header row with id, email, name and date_of_birth columns in any order
+func parseCSVImport(body io.Reader) ([]handler.ClientImportRow, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, column := range []string{"id", "email", "name", "date_of_birth"} {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("csv header misses the %s column", column)
+		}
+	}
+
+	var rows []handler.ClientImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if len(rows) == maxImportRows {
+			return nil, errTooManyImportRows
+		}
+		if errors.Is(err, csv.ErrFieldCount) {
+			rows = append(rows, handler.ClientImportRow{
+				Row:   len(rows) + 1,
+				Error: "wrong number of fields",
+			})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv: %w", err)
+		}
+
+		rows = append(rows, importClientRow(len(rows)+1, CreateClientReq{
+			Email:       record[columns["email"]],
+			DateOfBirth: record[columns["date_of_birth"]],
+			Name:        record[columns["name"]],
+			ID:          record[columns["id"]],
+		}))
+	}
+}
+
+// parseNDJSONImport expects a client object on every line, blank lines are skipped
+func parseNDJSONImport(body io.Reader) ([]handler.ClientImportRow, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxImportLineSize)
+
+	var rows []handler.ClientImportRow
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if len(rows) == maxImportRows {
+			return nil, errTooManyImportRows
+		}
+
+		var req CreateClientReq
+		err := json.Unmarshal([]byte(line), &req)
+		if err != nil {
+			rows = append(rows, handler.ClientImportRow{
+				Row:   len(rows) + 1,
+				Error: "invalid json",
+			})
+			continue
+		}
+
+		rows = append(rows, importClientRow(len(rows)+1, req))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("invalid ndjson: %w", err)
+	}
+
+	return rows, nil
+}
+
+func clientImportReportResponse(report handler.ClientImportReportDTO) *ClientImportReportResponse {
+	response := ClientImportReportResponse{
+		Total:     report.Total,
+		Accepted:  report.Accepted,
+		Duplicate: report.Duplicate,
+		Invalid:   report.Invalid,
+		Rows:      make([]ClientImportRowResponse, 0, len(report.Rows)),
+	}
+	for _, row := range report.Rows {
+		response.Rows = append(response.Rows, ClientImportRowResponse{
+			Row:    row.Row,
+			ID:     row.ID,
+			Status: row.Status,
+			Error:  row.Error,
+		})
+	}
+
+	return &response
+}
+
+func clientImportResponse(clientImport handler.ClientImportDTO) *ClientImportResponse {
+	response := ClientImportResponse{
+		ID:        clientImport.ImportUUID.String(),
+		Status:    clientImport.Status,
+		TotalRows: clientImport.TotalRows,
+		CreatedAt: clientImport.CreatedAt.Format(time.RFC3339),
+		Error:     clientImport.Error,
+	}
+	if clientImport.FinishedAt != nil {
+		response.FinishedAt = clientImport.FinishedAt.Format(time.RFC3339)
+	}
+	if clientImport.Report != nil {
+		response.Report = clientImportReportResponse(*clientImport.Report)
+	}
+
+	return &response
+}
+
+// ImportClients godoc
+// @Summary Import clients
+// @Description Imports clients from CSV (text/csv) with the header row id,email,name,date_of_birth or from NDJSON (application/x-ndjson) with a client object on every line. Every row is validated the same way as the client creation, rows whose id or email already exists or repeats in the import are duplicates.
+// @Description Imports up to 1000 rows are answered with the per-row report. Larger imports run as a job and are answered with 202 and the Location of the import status. The import is decoded in memory, so it is limited to 50000 rows and a body of 8 MiB, larger imports are rejected with 413 and have to be split.
+// @Tags Client
+// @Accept text/csv,application/x-ndjson
+// @Produce json
+// @Param Content-Type header string true "Content-Type" example(text/csv)
+// @Param X-Actor header string false "Actor of the change recorded in the client history" example(john.doe@whalebone.io)
+// @Param X-Request-ID header string false "Request ID recorded in the client history" example(8e03978e-40d5-43e8-bc93-6894a57f9324)
+// @Param data body string true "Clients in CSV or NDJSON"
+// @Success 200 {object} ClientImportReportResponse "Report of the import"
+// @Success 202 {object} ClientImportResponse "Scheduled import"
+// @Header 202 {string} Location "URL of the import status"
+// @Failure 400 {object} pkghttp.Problem "malformed_request"
+// @Failure 413 {object} pkghttp.Problem "payload_too_large for more than 50000 rows or a body over 8 MiB"
+// @Failure 415 {object} pkghttp.Problem "unsupported_media_type"
+// @Failure 500 {object} pkghttp.Problem "internal_server_error"
+// @Router /v1/client/import [post]
+func (c *Controller) ImportClients(ctx *gin.Context) {
+	contentType, _, _ := mime.ParseMediaType(ctx.GetHeader("Content-Type"))
+
+	var parseImport func(body io.Reader) ([]handler.ClientImportRow, error)
+	switch contentType {
+	case csvContentType:
+		parseImport = parseCSVImport
+	case ndjsonContentType:
+		parseImport = parseNDJSONImport
+	default:
+		pkghttp.AbortWithError(ctx, pkghttp.NewError(pkghttp.UnsupportedMediaTypeCode, "content type has to be "+csvContentType+" or "+ndjsonContentType))
+		return
+	}
+
+	rows, err := parseImport(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportBodySize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.Is(err, errTooManyImportRows) || errors.As(err, &maxBytesErr) {
+			pkghttp.AbortWithError(ctx, pkghttp.NewError(pkghttp.PayloadTooLargeCode, err.Error()))
+			return
+		}
+		pkghttp.AbortWithError(ctx, pkghttp.NewError(pkghttp.MalformedRequestCode, err.Error()))
+		return
+	}
+
+	if len(rows) > syncImportMaxRows {
+		clientImport, err := c.scheduleClientImportHandler.Handle(auditContext(ctx), rows)
+		if err != nil {
+			pkghttp.AbortWithError(ctx, err)
+			return
+		}
+
+		ctx.Header("Location", "/v1/client/import/"+clientImport.ImportUUID.String())
+		ctx.JSON(http.StatusAccepted, clientImportResponse(clientImport))
+		return
+	}
+
+	report, err := c.importClientsHandler.Handle(auditContext(ctx), rows)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, clientImportReportResponse(report))
+}
+
+// GetClientImport godoc
+// @Summary Get client import status
+// @Description Retrieves the status of the scheduled client import, the per-row report is returned once the import is completed.
+// @Tags Client
+// @Produce json
+// @Param id path string true "Import ID" example("123e4567-e89b-12d3-a456-426614174000")
+// @Success 200 {object} ClientImportResponse "Client import"
+// @Failure 404 {object} pkghttp.Problem "client_import_not_found, or not_found for a malformed client import id"
+// @Failure 500 {object} pkghttp.Problem "internal_server_error"
+// @Router /v1/client/import/{id} [get]
+func (c *Controller) GetClientImport(ctx *gin.Context) {
+	importUUID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		pkghttp.AbortWithError(ctx, pkghttp.NewError(pkghttp.NotFoundCode, "import id has to be a UUID"))
+		return
+	}
+
+	clientImport, err := c.getClientImportHandler.Handle(ctx, importUUID)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, clientImportResponse(clientImport))
+}
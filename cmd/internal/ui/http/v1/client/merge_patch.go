@@ -0,0 +1,63 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+// applyMergePatch applies a JSON merge patch (RFC 7386) on top of the current
+// state of the resource and validates the merged result.
+func applyMergePatch[T any](current T, patch []byte) (T, error) {
+	var res T
+
+	var patchDoc any
+	if err := json.Unmarshal(patch, &patchDoc); err != nil {
+		return res, err
+	}
+	if _, ok := patchDoc.(map[string]any); !ok {
+		return res, errors.New("merge patch must be a JSON object")
+	}
+
+	currentBytes, err := json.Marshal(current)
+	if err != nil {
+		return res, err
+	}
+	var currentDoc any
+	if err := json.Unmarshal(currentBytes, &currentDoc); err != nil {
+		return res, err
+	}
+
+	merged, err := json.Marshal(mergePatch(currentDoc, patchDoc))
+	if err != nil {
+		return res, err
+	}
+	if err := json.Unmarshal(merged, &res); err != nil {
+		return res, err
+	}
+
+	return res, binding.Validator.ValidateStruct(res)
+}
+
+func mergePatch(target any, patch any) any {
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]any)
+	if !ok {
+		targetObj = map[string]any{}
+	}
+
+	for k, v := range patchObj {
+		if v == nil {
+			delete(targetObj, k)
+			continue
+		}
+		targetObj[k] = mergePatch(targetObj[k], v)
+	}
+
+	return targetObj
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	pkghttp "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/ui/http"
+)
+
+const (
+	minNameLength = 2
+	maxNameLength = 100
+	// maxClientAge bounds the date of birth, an older client is a typo
+	maxClientAge = 150
+)
+
+var (
+	emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+	// nameRegex allows letters of any script with their accents, spaces, apostrophes, dots and hyphens
+	nameRegex = regexp.MustCompile(`^[\p{L}\p{M}' .-]+$`)
+)
+
+// validateEmail returns the parsed address, the client rules are shared by the creation, the replacement,
+// the merge patch and the import
+func validateEmail(v *pkghttp.ValidationError, raw string) string {
+	if raw == "" {
+		v.Add("email", pkghttp.ViolationRequired, "email is required")
+		return ""
+	}
+
+	email, err := mail.ParseAddress(raw)
+	if !emailRegex.MatchString(raw) || err != nil {
+		v.Add("email", pkghttp.ViolationInvalidFormat, "invalid email")
+		return ""
+	}
+
+	return email.String()
+}
+
+func validateName(v *pkghttp.ValidationError, name string) {
+	length := utf8.RuneCountInString(name)
+	switch {
+	case name == "":
+		v.Add("name", pkghttp.ViolationRequired, "name is required")
+	case length < minNameLength:
+		v.Add("name", pkghttp.ViolationTooShort, fmt.Sprintf("name has to have at least %d characters", minNameLength))
+	case length > maxNameLength:
+		v.Add("name", pkghttp.ViolationTooLong, fmt.Sprintf("name has to have at most %d characters", maxNameLength))
+	case !nameRegex.MatchString(name):
+		v.Add("name", pkghttp.ViolationInvalidCharacters, "name can contain only letters, spaces, apostrophes, dots and hyphens")
+	}
+}
+
+func validateDateOfBirth(v *pkghttp.ValidationError, raw string, now time.Time) time.Time {
+	if raw == "" {
+		v.Add("date_of_birth", pkghttp.ViolationRequired, "date of birth is required")
+		return time.Time{}
+	}
+
+	dateOfBirth, err := time.Parse(dateOfBirthLayout, raw)
+	switch {
+	case err != nil:
+		v.Add("date_of_birth", pkghttp.ViolationInvalidFormat, "invalid date of birth")
+	case dateOfBirth.After(now):
+		v.Add("date_of_birth", pkghttp.ViolationInFuture, "date of birth is in the future")
+	case dateOfBirth.Before(now.AddDate(-maxClientAge, 0, 0)):
+		v.Add("date_of_birth", pkghttp.ViolationOutOfRange, fmt.Sprintf("date of birth is more than %d years ago", maxClientAge))
+	}
+
+	return dateOfBirth
+}
+
+func validateClientID(v *pkghttp.ValidationError, raw string) uuid.UUID {
+	if raw == "" {
+		v.Add("id", pkghttp.ViolationRequired, "client id is required")
+		return uuid.Nil
+	}
+
+	clientUUID, err := uuid.Parse(raw)
+	if err != nil {
+		v.Add("id", pkghttp.ViolationInvalidFormat, "invalid client id")
+	}
+
+	return clientUUID
+}
@@ -0,0 +1,437 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+	pkghttp "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/ui/http"
+)
+
+const (
+	minWebhookSecretLength     = 16
+	defaultListDeliveriesLimit = 50
+	maxListDeliveriesLimit     = 500
+)
+
+type CreateWebhookHandler interface {
+	Handle(ctx context.Context, dto handler.CreateWebhookDTO) (handler.WebhookDTO, error)
+}
+
+type GetWebhookHandler interface {
+	Handle(ctx context.Context, webhookUUID uuid.UUID) (handler.WebhookDTO, error)
+}
+
+type ListWebhooksHandler interface {
+	Handle(ctx context.Context) ([]handler.WebhookDTO, error)
+}
+
+type UpdateWebhookHandler interface {
+	Handle(ctx context.Context, dto handler.UpdateWebhookDTO) error
+}
+
+type DeleteWebhookHandler interface {
+	Handle(ctx context.Context, webhookUUID uuid.UUID) error
+}
+
+type ListWebhookDeliveriesHandler interface {
+	Handle(ctx context.Context, q handler.ListWebhookDeliveriesQuery) (handler.WebhookDeliveriesDTO, error)
+}
+
+type Controller struct {
+	createWebhookHandler         CreateWebhookHandler
+	getWebhookHandler            GetWebhookHandler
+	listWebhooksHandler          ListWebhooksHandler
+	updateWebhookHandler         UpdateWebhookHandler
+	deleteWebhookHandler         DeleteWebhookHandler
+	listWebhookDeliveriesHandler ListWebhookDeliveriesHandler
+}
+
+type Handlers struct {
+	CreateWebhook         CreateWebhookHandler
+	GetWebhook            GetWebhookHandler
+	ListWebhooks          ListWebhooksHandler
+	UpdateWebhook         UpdateWebhookHandler
+	DeleteWebhook         DeleteWebhookHandler
+	ListWebhookDeliveries ListWebhookDeliveriesHandler
+}
+
+func NewController(h Handlers) *Controller {
+	return &Controller{
+		createWebhookHandler:         h.CreateWebhook,
+		getWebhookHandler:            h.GetWebhook,
+		listWebhooksHandler:          h.ListWebhooks,
+		updateWebhookHandler:         h.UpdateWebhook,
+		deleteWebhookHandler:         h.DeleteWebhook,
+		listWebhookDeliveriesHandler: h.ListWebhookDeliveries,
+	}
+}
+
+func (c *Controller) Register(ge *gin.Engine) {
+	ge.POST("/v1/webhooks", c.CreateWebhook)
+	ge.GET("/v1/webhooks", c.ListWebhooks)
+	ge.GET("/v1/webhooks/:id", c.GetWebhook)
+	ge.PUT("/v1/webhooks/:id", c.UpdateWebhook)
+	ge.DELETE("/v1/webhooks/:id", c.DeleteWebhook)
+	ge.GET("/v1/webhooks/:id/deliveries", c.ListWebhookDeliveries)
+}
+
+type CreateWebhookReq struct {
+	URL string `json:"url" validate:"required" example:"https://partner.example.com/webhooks/clients"`
+	// Secret is generated when empty
+	Secret     string   `json:"secret" example:"whsec_4f0c9b1e5a7d4c2e8b6a3f1d9e7c5b3a"`
+	EventTypes []string `json:"event_types" example:"client.created,client.updated"`
+	Active     *bool    `json:"active" example:"true"`
+}
+
+type UpdateWebhookReq struct {
+	URL        string   `json:"url" validate:"required" example:"https://partner.example.com/webhooks/clients"`
+	EventTypes []string `json:"event_types" example:"client.created,client.updated"`
+	Active     *bool    `json:"active" example:"true"`
+}
+
+type WebhookResponse struct {
+	ID         string   `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	URL        string   `json:"url" example:"https://partner.example.com/webhooks/clients"`
+	EventTypes []string `json:"event_types" example:"client.created,client.updated"`
+	Active     bool     `json:"active" example:"true"`
+	CreatedAt  string   `json:"created_at" format:"date-time" example:"2021-01-01T00:00:00Z"`
+}
+
+type CreateWebhookResponse struct {
+	WebhookResponse
+	// Secret signs the deliveries, it is not returned anymore
+	Secret string `json:"secret" example:"whsec_4f0c9b1e5a7d4c2e8b6a3f1d9e7c5b3a"`
+}
+
+type ListWebhooksResponse struct {
+	Items []WebhookResponse `json:"items"`
+}
+
+func validateWebhook(v *pkghttp.ValidationError, rawURL string, eventTypes []string) {
+	if rawURL == "" {
+		v.Add("url", pkghttp.ViolationRequired, "url is required")
+	} else if u, err := url.Parse(rawURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.Add("url", pkghttp.ViolationInvalidFormat, "invalid url")
+	}
+
+	for i, eventType := range eventTypes {
+		if !slices.Contains(handler.ClientEventTypes, eventType) {
+			v.Add(fmt.Sprintf("event_types[%d]", i), pkghttp.ViolationNotAllowed, "invalid event type "+eventType)
+		}
+	}
+}
+
+func createWebhookDTOFactory(r CreateWebhookReq) (handler.CreateWebhookDTO, error) {
+	v := pkghttp.NewValidationError()
+	validateWebhook(v, r.URL, r.EventTypes)
+	if r.Secret != "" && len(r.Secret) < minWebhookSecretLength {
+		v.Add("secret", pkghttp.ViolationTooShort, fmt.Sprintf("secret has to have at least %d characters", minWebhookSecretLength))
+	}
+	if err := v.Err(); err != nil {
+		return handler.CreateWebhookDTO{}, err
+	}
+
+	return handler.CreateWebhookDTO{
+		URL:        r.URL,
+		Secret:     r.Secret,
+		EventTypes: r.EventTypes,
+		Active:     r.Active == nil || *r.Active,
+	}, nil
+}
+
+func updateWebhookDTOFactory(webhookUUID uuid.UUID, r UpdateWebhookReq) (handler.UpdateWebhookDTO, error) {
+	v := pkghttp.NewValidationError()
+	validateWebhook(v, r.URL, r.EventTypes)
+	if err := v.Err(); err != nil {
+		return handler.UpdateWebhookDTO{}, err
+	}
+
+	return handler.UpdateWebhookDTO{
+		WebhookUUID: webhookUUID,
+		URL:         r.URL,
+		EventTypes:  r.EventTypes,
+		Active:      r.Active == nil || *r.Active,
+	}, nil
+}
+
+func webhookResponse(w handler.WebhookDTO) WebhookResponse {
+	eventTypes := w.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	return WebhookResponse{
+		ID:         w.WebhookUUID.String(),
+		URL:        w.URL,
+		EventTypes: eventTypes,
+		Active:     w.Active,
+		CreatedAt:  w.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// CreateWebhook godoc
+// @Summary Create a webhook
+// @Description Subscribes the URL to client events, no event types subscribe all of them. Every delivery is a POST of the JSON event signed in the X-Webhook-Signature header as "t=<unix timestamp>,v1=<hex HMAC-SHA256 of "<timestamp>.<body>" with the secret>".
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param data body CreateWebhookReq true "Webhook data"
+// @Success 201 {object} CreateWebhookResponse "Created webhook with its secret"
+// @Failure 400 {object} pkghttp.Problem "malformed_request with the violations"
+// @Failure 422 {object} pkghttp.Problem "validation_failed with the violations"
+// @Failure 500 {object} pkghttp.Problem "internal_server_error"
+// @Router /v1/webhooks [post]
+func (c *Controller) CreateWebhook(ctx *gin.Context) {
+	var req CreateWebhookReq
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, pkghttp.MapBindingError(err))
+		return
+	}
+
+	webhookDTO, err := createWebhookDTOFactory(req)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, err)
+		return
+	}
+
+	webhook, err := c.createWebhookHandler.Handle(ctx, webhookDTO)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, &CreateWebhookResponse{
+		WebhookResponse: webhookResponse(webhook),
+		Secret:          webhook.Secret,
+	})
+}
+
+// ListWebhooks godoc
+// @Summary List webhooks
+// @Description Lists all webhooks.
+// @Tags Webhook
+// @Produce json
+// @Success 200 {object} ListWebhooksResponse "Webhooks"
+// @Failure 500 {object} pkghttp.Problem "internal_server_error"
+// @Router /v1/webhooks [get]
+func (c *Controller) ListWebhooks(ctx *gin.Context) {
+	webhooks, err := c.listWebhooksHandler.Handle(ctx)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, err)
+		return
+	}
+
+	response := ListWebhooksResponse{
+		Items: make([]WebhookResponse, 0, len(webhooks)),
+	}
+	for _, webhook := range webhooks {
+		response.Items = append(response.Items, webhookResponse(webhook))
+	}
+
+	ctx.JSON(http.StatusOK, &response)
+}
+
+// GetWebhook godoc
+// @Summary Get webhook by ID
+// @Description Retrieves the webhook, the secret is not returned.
+// @Tags Webhook
+// @Produce json
+// @Param id path string true "Webhook ID" example("123e4567-e89b-12d3-a456-426614174000")
+// @Success 200 {object} WebhookResponse "Webhook"
+// @Failure 404 {object} pkghttp.Problem "webhook_not_found, or not_found for a malformed webhook id"
+// @Failure 500 {object} pkghttp.Problem "internal_server_error"
+// @Router /v1/webhooks/{id} [get]
+func (c *Controller) GetWebhook(ctx *gin.Context) {
+	webhookUUID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		pkghttp.AbortWithError(ctx, pkghttp.NewError(pkghttp.NotFoundCode, "webhook id has to be a UUID"))
+		return
+	}
+
+	webhook, err := c.getWebhookHandler.Handle(ctx, webhookUUID)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, err)
+		return
+	}
+
+	response := webhookResponse(webhook)
+	ctx.JSON(http.StatusOK, &response)
+}
+
+// UpdateWebhook godoc
+// @Summary Replace a webhook
+// @Description Replaces the URL, event types and active flag of the webhook, the secret is kept. Deliveries of an inactive webhook wait until it is activated again.
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID" example("123e4567-e89b-12d3-a456-426614174000")
+// @Param data body UpdateWebhookReq true "Webhook data"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {object} pkghttp.Problem "malformed_request with the violations"
+// @Failure 404 {object} pkghttp.Problem "webhook_not_found, or not_found for a malformed webhook id"
+// @Failure 422 {object} pkghttp.Problem "validation_failed with the violations"
+// @Failure 500 {object} pkghttp.Problem "internal_server_error"
+// @Router /v1/webhooks/{id} [put]
+func (c *Controller) UpdateWebhook(ctx *gin.Context) {
+	webhookUUID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		pkghttp.AbortWithError(ctx, pkghttp.NewError(pkghttp.NotFoundCode, "webhook id has to be a UUID"))
+		return
+	}
+
+	var req UpdateWebhookReq
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, pkghttp.MapBindingError(err))
+		return
+	}
+
+	webhookDTO, err := updateWebhookDTOFactory(webhookUUID, req)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, err)
+		return
+	}
+
+	err = c.updateWebhookHandler.Handle(ctx, webhookDTO)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.AbortWithStatus(http.StatusNoContent)
+}
+
+// DeleteWebhook godoc
+// @Summary Delete a webhook
+// @Description Deletes the webhook together with its delivery log.
+// @Tags Webhook
+// @Produce json
+// @Param id path string true "Webhook ID" example("123e4567-e89b-12d3-a456-426614174000")
+// @Success 204 {string} string "No Content"
+// @Failure 404 {object} pkghttp.Problem "webhook_not_found, or not_found for a malformed webhook id"
+// @Failure 500 {object} pkghttp.Problem "internal_server_error"
+// @Router /v1/webhooks/{id} [delete]
+func (c *Controller) DeleteWebhook(ctx *gin.Context) {
+	webhookUUID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		pkghttp.AbortWithError(ctx, pkghttp.NewError(pkghttp.NotFoundCode, "webhook id has to be a UUID"))
+		return
+	}
+
+	err = c.deleteWebhookHandler.Handle(ctx, webhookUUID)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.AbortWithStatus(http.StatusNoContent)
+}
+
+type ListWebhookDeliveriesReq struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=500"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID        int64  `json:"id" example:"42"`
+	EventID   int64  `json:"event_id" example:"7"`
+	EventType string `json:"event_type" example:"client.created"`
+	// Status is pending, delivered or dead, dead deliveries are not attempted anymore
+	Status         string `json:"status" example:"delivered" enums:"pending,delivered,dead"`
+	Attempts       int    `json:"attempts" example:"1"`
+	LastStatusCode *int   `json:"last_status_code,omitempty" example:"200"`
+	LastError      string `json:"last_error,omitempty" example:"webhook responded with status 503"`
+	CreatedAt      string `json:"created_at" format:"date-time" example:"2021-01-01T00:00:00Z"`
+	NextAttemptAt  string `json:"next_attempt_at,omitempty" format:"date-time" example:"2021-01-01T00:00:00Z"`
+	DeliveredAt    string `json:"delivered_at,omitempty" format:"date-time" example:"2021-01-01T00:00:00Z"`
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Items      []WebhookDeliveryResponse `json:"items"`
+	NextCursor string                    `json:"next_cursor,omitempty" example:"MTIz"`
+}
+
+// ListWebhookDeliveries godoc
+// @Summary List webhook deliveries
+// @Description Lists the delivery log of the webhook from the newest with keyset pagination. Pass the returned next_cursor as cursor to get the next page.
+// @Tags Webhook
+// @Produce json
+// @Param id path string true "Webhook ID" example("123e4567-e89b-12d3-a456-426614174000")
+// @Param cursor query string false "Cursor of the page returned as next_cursor"
+// @Param limit query int false "Page size" default(50) minimum(1) maximum(500)
+// @Success 200 {object} ListWebhookDeliveriesResponse "Page of deliveries"
+// @Failure 400 {object} pkghttp.Problem "invalid_parameters with the violations"
+// @Failure 404 {object} pkghttp.Problem "webhook_not_found, or not_found for a malformed webhook id"
+// @Failure 500 {object} pkghttp.Problem "internal_server_error"
+// @Router /v1/webhooks/{id}/deliveries [get]
+func (c *Controller) ListWebhookDeliveries(ctx *gin.Context) {
+	webhookUUID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		pkghttp.AbortWithError(ctx, pkghttp.NewError(pkghttp.NotFoundCode, "webhook id has to be a UUID"))
+		return
+	}
+
+	var req ListWebhookDeliveriesReq
+	err = ctx.ShouldBindQuery(&req)
+	if err != nil {
+		pkghttp.AbortWithError(ctx, pkghttp.MapParamBindingError(err))
+		return
+	}
+
+	beforeID, err := pkghttp.DecodeCursor(req.Cursor)
+	if err != nil {
+		v := pkghttp.NewInvalidParametersError()
+		v.Add("cursor", pkghttp.ViolationInvalidFormat, "invalid cursor")
+		pkghttp.AbortWithError(ctx, v)
+		return
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultListDeliveriesLimit
+	}
+
+	deliveries, err := c.listWebhookDeliveriesHandler.Handle(ctx, handler.ListWebhookDeliveriesQuery{
+		WebhookUUID: webhookUUID,
+		BeforeID:    beforeID,
+		Limit:       min(limit, maxListDeliveriesLimit),
+	})
+	if err != nil {
+		pkghttp.AbortWithError(ctx, err)
+		return
+	}
+
+	response := ListWebhookDeliveriesResponse{
+		Items:      make([]WebhookDeliveryResponse, 0, len(deliveries.Deliveries)),
+		NextCursor: pkghttp.EncodeCursor(deliveries.NextBeforeID),
+	}
+	for _, delivery := range deliveries.Deliveries {
+		item := WebhookDeliveryResponse{
+			ID:             delivery.ID,
+			EventID:        delivery.EventID,
+			EventType:      delivery.EventType,
+			Status:         delivery.Status,
+			Attempts:       delivery.Attempts,
+			LastStatusCode: delivery.LastStatusCode,
+			LastError:      delivery.LastError,
+			CreatedAt:      delivery.CreatedAt.Format(time.RFC3339),
+		}
+		if delivery.Status == handler.WebhookDeliveryPending {
+			item.NextAttemptAt = delivery.NextAttemptAt.Format(time.RFC3339)
+		}
+		if delivery.DeliveredAt != nil {
+			item.DeliveredAt = delivery.DeliveredAt.Format(time.RFC3339)
+		}
+		response.Items = append(response.Items, item)
+	}
+
+	ctx.JSON(http.StatusOK, &response)
+}
@@ -0,0 +1,184 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// violation codes are stable, clients may rely on them unlike on the messages
+const (
+	ViolationRequired          = "required"
+	ViolationInvalidFormat     = "invalid_format"
+	ViolationInvalidType       = "invalid_type"
+	ViolationTooShort          = "too_short"
+	ViolationTooLong           = "too_long"
+	ViolationInvalidCharacters = "invalid_characters"
+	ViolationInFuture          = "in_future"
+	ViolationOutOfRange        = "out_of_range"
+	ViolationNotAllowed        = "not_allowed"
+	ViolationMalformed         = "malformed"
+)
+
+func init() {
+	// the violations name the fields the way the client sent them
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(fieldName)
+	}
+}
+
+type FieldViolation struct {
+	// Field is the path of the field in the request, it is empty when the whole request is malformed
+	Field   string `json:"field,omitempty" example:"date_of_birth"`
+	Code    string `json:"code" example:"in_future" enums:"required,invalid_format,invalid_type,too_short,too_long,invalid_characters,in_future,out_of_range,not_allowed,malformed"`
+	Message string `json:"message" example:"date of birth is in the future"`
+}
+
+// ValidationError collects all violations of the request instead of stopping at the first one
+type ValidationError struct {
+	code       string
+	Violations []FieldViolation
+}
+
+// NewValidationError is for a decoded request breaking the rules
+func NewValidationError() *ValidationError {
+	return &ValidationError{
+		code:       ValidationFailedCode,
+		Violations: []FieldViolation{},
+	}
+}
+
+// NewInvalidParametersError is for query parameters and headers breaking the rules
+func NewInvalidParametersError() *ValidationError {
+	return &ValidationError{
+		code:       InvalidParametersCode,
+		Violations: []FieldViolation{},
+	}
+}
+
+func (e *ValidationError) Add(field, code, message string) {
+	e.Violations = append(e.Violations, FieldViolation{
+		Field:   field,
+		Code:    code,
+		Message: message,
+	})
+}
+
+// Err is nil when nothing was violated
+func (e *ValidationError) Err() error {
+	if len(e.Violations) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		messages = append(messages, v.Message)
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Code() string {
+	return e.code
+}
+
+// MapBindingError maps the error of a body binding, a body that can't be decoded is malformed
+// and a decoded body breaking the binding rules fails the validation
+func MapBindingError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		verr := NewValidationError()
+		for _, fe := range validationErrs {
+			addFieldError(verr, fe)
+		}
+		return verr
+	}
+
+	verr := &ValidationError{code: MalformedRequestCode}
+	switch {
+	case errors.As(err, &typeErr):
+		verr.Add(typeErr.Field, ViolationInvalidType, fmt.Sprintf("%s has to be %s", typeErr.Field, jsonTypeName(typeErr.Type)))
+	case errors.As(err, &syntaxErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		verr.Add("", ViolationMalformed, "request body is not valid JSON")
+	default:
+		verr.Add("", ViolationMalformed, err.Error())
+	}
+
+	return verr
+}
+
+// MapParamBindingError maps the error of a query or header binding
+func MapParamBindingError(err error) error {
+	verr := NewInvalidParametersError()
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		verr.Add("", ViolationInvalidFormat, "request parameters can't be parsed")
+		return verr
+	}
+	for _, fe := range validationErrs {
+		addFieldError(verr, fe)
+	}
+
+	return verr
+}
+
+func addFieldError(verr *ValidationError, fe validator.FieldError) {
+	field := fe.Field()
+	switch fe.Tag() {
+	case "required":
+		verr.Add(field, ViolationRequired, field+" is required")
+	case "min", "gte":
+		verr.Add(field, ViolationOutOfRange, fmt.Sprintf("%s has to be at least %s", field, fe.Param()))
+	case "max", "lte":
+		verr.Add(field, ViolationOutOfRange, fmt.Sprintf("%s has to be at most %s", field, fe.Param()))
+	case "oneof":
+		verr.Add(field, ViolationNotAllowed, fmt.Sprintf("%s has to be one of %s", field, strings.ReplaceAll(fe.Param(), " ", ", ")))
+	default:
+		verr.Add(field, ViolationInvalidFormat, "invalid "+field)
+	}
+}
+
+// fieldName prefers the json, form and header names of the field to its Go name
+func fieldName(f reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "header"} {
+		name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+
+	return f.Name
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
@@ -2,17 +2,22 @@ package main
 
 import (
 	"context"
+	"os"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-contrib/pprof"
 	"github.com/gin-gonic/gin"
 	"github.com/jamm3e3333/whalebone-go-test-project/cmd/app/config"
+	migratesetup "github.com/jamm3e3333/whalebone-go-test-project/cmd/app/setup/migrate"
+	outboxsetup "github.com/jamm3e3333/whalebone-go-test-project/cmd/app/setup/outbox"
 	"github.com/jamm3e3333/whalebone-go-test-project/cmd/app/setup/postgres"
 	"github.com/jamm3e3333/whalebone-go-test-project/cmd/app/setup/prometheus"
+	tracingsetup "github.com/jamm3e3333/whalebone-go-test-project/cmd/app/setup/tracing"
 	_ "github.com/jamm3e3333/whalebone-go-test-project/cmd/app/swagger"
 	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal"
 	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/infrastructure/pg"
+	pkghttp "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/ui/http"
 	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/ui/http/health"
 	healthcheck "github.com/jamm3e3333/whalebone-go-test-project/pkg/health"
 	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
@@ -24,12 +29,46 @@ import (
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"github.com/swaggo/swag"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
 )
 
+// untracedPaths are polled by the infrastructure, their traces would be noise
+var untracedPaths = map[string]bool{
+	"/metrics":          true,
+	"/health/liveness":  true,
+	"/health/readiness": true,
+	"/status":           true,
+	"/api/*any":         true,
+}
+
 // Swagger API setup:
 // @title Whalebone Clients API
 // @version 2.0
 // @description API provides endpoints for whalebone clients
+// @description
+// @description Errors are returned as application/problem+json (RFC 7807) with a stable code, the type is urn:problem-type:<code>.
+// @description Malformed requests and validation failures list the violations of the fields.
+// @description
+// @description | Code | Status | Title |
+// @description | --- | --- | --- |
+// @description | malformed_request | 400 | Malformed request |
+// @description | invalid_parameters | 400 | Invalid parameters |
+// @description | idempotency_key_too_long | 400 | Idempotency key is too long |
+// @description | unreadable_body | 400 | Request body can't be read |
+// @description | not_found | 404 | Not found |
+// @description | client_not_found | 404 | Client not found |
+// @description | client_import_not_found | 404 | Client import not found |
+// @description | webhook_not_found | 404 | Webhook not found |
+// @description | idempotency_key_reused | 409 | Idempotency key was reused |
+// @description | idempotency_key_in_progress | 409 | Idempotency key is in progress |
+// @description | client_version_mismatch | 412 | Client version mismatch |
+// @description | payload_too_large | 413 | Payload too large |
+// @description | unsupported_media_type | 415 | Unsupported media type |
+// @description | validation_failed | 422 | Validation failed |
+// @description | client_already_exists | 422 | Client already exists |
+// @description | precondition_required | 428 | Precondition required |
+// @description | internal_server_error | 500 | Internal server error |
 // @contact.name Whalebone
 func main() {
 	ctx := shutdown.SetupShutdownContext()
@@ -38,12 +77,14 @@ func main() {
 		appConfig, errAPPConfig       = config.CreateAPPConfig()
 		loggerConfig, errLoggerConfig = config.CreateLoggerConfig()
 		pgConfig, errPGConfig         = config.CreatePostgresConfig()
+		tracingConfig, errTracing     = config.CreateTracingConfig()
 	)
 
 	for _, err := range []error{
 		errAPPConfig,
 		errLoggerConfig,
 		errPGConfig,
+		errTracing,
 	} {
 		if err != nil {
 			panic(err)
@@ -55,17 +96,84 @@ func main() {
 
 	lg := logger.New(logger.ParseLevel(loggerConfig.Level), loggerConfig.DevMode)
 
+	// whalebone-clients migrate up|down|status|redo|reset runs the embedded migrations instead of the app
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		command := ""
+		if len(os.Args) > 2 {
+			command = os.Args[2]
+		}
+		if err := migratesetup.Run(ctx, pgConfig.ConnectionURL(), command, lg); err != nil {
+			lg.Fatal("migrate %s failed: %s", command, err)
+		}
+
+		return
+	}
+
+	// the publisher is built after the migrate command, which doesn't publish
+	outboxPublisher, closeOutboxPublisher, err := outboxsetup.NewPublisher(
+		appConfig.OutboxPublisher,
+		appConfig.OutboxFilePath,
+		appConfig.OutboxWebhookURL,
+		appConfig.OutboxWebhookTimeout,
+	)
+	if err != nil {
+		panic(err)
+	}
+	defer func() {
+		if err := closeOutboxPublisher(); err != nil {
+			lg.Error("err closing outbox publisher, error: %v", err)
+		}
+	}()
+
+	if pgConfig.AutoMigrate {
+		if err := migratesetup.Up(ctx, pgConfig.ConnectionURL(), lg); err != nil {
+			lg.Fatal("applying migrations failed: %s", err)
+		}
+	}
+
+	tp, err := tracingsetup.NewTracerProvider(ctx, tracingConfig, appConfig.AppName)
+	if err != nil {
+		panic(err)
+	}
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		lg.Error("tracing error, %s", err)
+	}))
+	defer func() {
+		if err := tp.Shutdown(context.Background()); err != nil {
+			lg.Error("err shutting down tracer provider, error: %v", err)
+		}
+	}()
+
 	mm := prometheus.NewMetricsOnce(appConfig.AppName)()
 	pc := postgres.EstablishConnection(ctx, pgx.Config{
-		ConnectionURL:     pgConfig.ConnectionURL(),
-		LogLevel:          pgConfig.LogLevel,
-		MaxConnLifetime:   pgConfig.MaxConnLifetime,
-		MaxConnIdleTime:   pgConfig.MaxConnIdleTIme,
-		QueryTimeout:      pgConfig.QueryTimeout,
-		DefaultMaxConns:   pgConfig.MaxConns,
-		DefaultMinConns:   pgConfig.MinConns,
-		HealthCheckPeriod: pgConfig.HealthCheckPeriod,
+		ConnectionURL:      pgConfig.ConnectionURL(),
+		ApplicationName:    appConfig.AppName,
+		LogLevel:           pgConfig.LogLevel,
+		MaxConnLifetime:    pgConfig.MaxConnLifetime,
+		MaxConnIdleTime:    pgConfig.MaxConnIdleTIme,
+		QueryTimeout:       pgConfig.QueryTimeout,
+		DefaultMaxConns:    pgConfig.MaxConns,
+		DefaultMinConns:    pgConfig.MinConns,
+		HealthCheckPeriod:  pgConfig.HealthCheckPeriod,
+		TxMaxAttempts:      pgConfig.TxMaxAttempts,
+		ReplicaURLs:        pgConfig.ReplicaConnectionURLs(),
+		MaxReplicationLag:  pgConfig.ReplicaMaxLag,
+		ReplicaCheckPeriod: pgConfig.ReplicaCheckPeriod,
+		SlowQueryThreshold: pgConfig.SlowQueryThreshold,
+		LogArgs:            pgConfig.LogQueryArgs,
 	}, lg, mm.Pm)
+	prometheus.RegisterPoolStats(appConfig.AppName, pc)
+
+	migrations, err := migratesetup.Migrations()
+	if err != nil {
+		panic(err)
+	}
+	schemaHI := pg.NewSchemaHealthIndicator(ctx, pc, migrations, lg)
+	if drift, err := schemaHI.Drift(); err != nil {
+		lg.Error("reading the database schema version failed: %s", err)
+	} else if !drift.InSync() {
+		lg.Error("database %s, run `whalebone-clients migrate up` or deploy the binary matching the schema, the app is not ready meanwhile", drift)
+	}
 
 	// Http server
 	lg.Info("Initializing http server...")
@@ -74,18 +182,29 @@ func main() {
 	gin.SetMode(gin.ReleaseMode)
 
 	ge := gin.New()
-	ge.Use(gin.Recovery())
+	// the handlers pass the gin context on, the correlation ids of the request context reach the queries through it
+	ge.ContextWithFallback = true
+	ge.Use(
+		gin.Recovery(),
+		otelgin.Middleware(appConfig.AppName, otelgin.WithGinFilter(func(c *gin.Context) bool {
+			return !untracedPaths[c.FullPath()]
+		})),
+		pkgGin.CorrelationMiddleware(),
+	)
 	pprof.Register(ge)
 
 	// Register logger middleware
 	ge.Use(
 		cors.New(cors.Config{
 			AllowOrigins:     appConfig.AllowedOrigins(),
-			AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+			AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Idempotency-Key", "If-Match", "If-None-Match", "X-Actor", "X-Request-ID", "traceparent"},
+			ExposeHeaders:    []string{"ETag", "Idempotent-Replayed", "Location", "X-Request-ID", "traceparent"},
 			AllowCredentials: true,
 		}),
+		// the export is not logged, the logger would buffer the whole streamed body
 		pkgGin.LoggerMiddleware(pkgGin.NewLoggerMiddlewareConfig(
-			[]string{"/metrics", "/health/liveness", "/health/readiness", "/status", "/api/*any"},
+			[]string{"/metrics", "/health/liveness", "/health/readiness", "/status", "/api/*any", "/v1/client/export"},
 		), lg),
 	)
 
@@ -97,6 +216,10 @@ func main() {
 	}))
 	lg.Info("gin prometheus initialized")
 
+	// Render the errors of the API as problem details
+	ge.Use(pkghttp.ErrorMiddleware(lg))
+	ge.NoRoute(pkghttp.NoRoute)
+
 	// Initialize Swagger
 	gsc := ginSwagger.Config{
 		URL:                      "doc.json",
@@ -116,15 +239,29 @@ func main() {
 	livenessHCh.RegisterIndicator(pg.NewHealthIndicator(ctx, pc, lg))
 
 	readinessHCh := healthcheck.NewHealthCheck(appConfig.HealthCheckTimeout, lg)
+	readinessHCh.RegisterIndicator(schemaHI)
+	for _, replica := range pc.ReplicaNames() {
+		readinessHCh.RegisterIndicator(pg.NewReplicaHealthIndicator(ctx, pc, replica, lg))
+	}
 
 	hc := health.NewController(readinessHCh, livenessHCh, ge)
 	hc.Register(ge)
 	lg.Info("health check controller initialized")
 
-	internal.RegisterModule(ge, internal.ModuleParams{
-		PGConn: pc,
-		Logger: lg,
-		AppENV: appConfig.AppEnv,
+	internal.RegisterModule(ctx, ge, internal.ModuleParams{
+		PGConn:                      pc,
+		Logger:                      lg,
+		AppENV:                      appConfig.AppEnv,
+		DeletedClientsRetention:     appConfig.DeletedClientsRetention(),
+		DeletedClientsPurgeInterval: appConfig.DeletedClientsPurgeInterval,
+		IdempotencyKeyTTL:           appConfig.IdempotencyKeyTTL,
+		IdempotencyKeyPurgeInterval: appConfig.IdempotencyKeyPurgeInterval,
+		ClientImportInterval:        appConfig.ClientImportInterval,
+		OutboxPublisher:             outboxPublisher,
+		OutboxRelayInterval:         appConfig.OutboxRelayInterval,
+		OutboxMetrics:               mm.Om,
+		WebhookDeliveryInterval:     appConfig.WebhookDeliveryInterval,
+		WebhookTimeout:              appConfig.WebhookTimeout,
 	})
 
 	for _, v := range ge.Routes() {
@@ -33,6 +33,9 @@ func (b *BlankLogger) WithFields(_ []logger.Meta) *logger.ZeroLogger {
 func (b *BlankLogger) WithAPM(_ context.Context) logger.Logger {
 	return NewBlankLogger()
 }
+func (b *BlankLogger) WithContext(_ context.Context) logger.Logger {
+	return b
+}
 
 func (b *BlankLogger) TraceWithMetadata(_ string, _ map[string]any) {}
 func (b *BlankLogger) DebugWithMetadata(_ string, _ map[string]any) {}
@@ -0,0 +1,111 @@
+package pg
+
+import (
+	"context"
+	"testing"
+
+	migratesetup "github.com/jamm3e3333/whalebone-go-test-project/cmd/app/setup/migrate"
+	infrapg "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/infrastructure/pg"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/test/helper"
+	healthcheck "github.com/jamm3e3333/whalebone-go-test-project/pkg/health"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/migrate"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/pgx"
+	"github.com/stretchr/testify/suite"
+)
+
+// the test database is migrated with the embedded migrations
+type SchemaHealthIndicatorTestSuite struct {
+	suite.Suite
+
+	lg         logger.Logger
+	pgConn     *pgx.ConnectionPool
+	migrations []migrate.Migration
+}
+
+func (s *SchemaHealthIndicatorTestSuite) SetupSuite() {
+	s.lg = helper.NewBlankLogger()
+	pgCfg := helper.NewPostgresConfig()
+
+	pgConn, err := pgx.NewConnectionPool(context.Background(), pgx.Config{
+		ConnectionURL:     pgCfg.ConnectionURL(),
+		LogLevel:          "info",
+		MaxConnLifetime:   pgCfg.MaxConnLifetime(),
+		MaxConnIdleTime:   pgCfg.MaxConnIdleTime(),
+		QueryTimeout:      pgCfg.QueryTimeout(),
+		DefaultMaxConns:   pgCfg.DefaultMaxConns(),
+		DefaultMinConns:   pgCfg.DefaultMinConns(),
+		HealthCheckPeriod: pgCfg.HealthCheckPeriod(),
+	}, s.lg, helper.NewDummyMetrics())
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	s.pgConn = pgConn
+
+	s.migrations, err = migratesetup.Migrations()
+	if err != nil {
+		s.T().Fatal(err)
+	}
+}
+
+func (s *SchemaHealthIndicatorTestSuite) Test_Status_UpWhenInSync() {
+	status, details := infrapg.NewSchemaHealthIndicator(context.Background(), s.pgConn, s.migrations, s.lg).StatusWithDetails()
+
+	s.Equal(healthcheck.StatusUp, status)
+	s.Nil(details)
+}
+
+func (s *SchemaHealthIndicatorTestSuite) Test_Status_DownWhenBehind() {
+	latest := s.migrations[len(s.migrations)-1].Version
+	migrations := append(s.migrations[:len(s.migrations):len(s.migrations)], migrate.Migration{Version: 99991231235959, Name: "99991231235959_future.sql"})
+
+	status, details := infrapg.NewSchemaHealthIndicator(context.Background(), s.pgConn, migrations, s.lg).StatusWithDetails()
+
+	s.Equal(healthcheck.StatusDown, status)
+	s.Equal(map[string]any{
+		"expected_version": int64(99991231235959),
+		"current_version":  latest,
+		"pending":          []int64{99991231235959},
+		"unknown":          []int64(nil),
+	}, details)
+}
+
+func (s *SchemaHealthIndicatorTestSuite) Test_Status_DownWhenAhead() {
+	latest := s.migrations[len(s.migrations)-1].Version
+	migrations := s.migrations[:len(s.migrations)-1]
+
+	status, details := infrapg.NewSchemaHealthIndicator(context.Background(), s.pgConn, migrations, s.lg).StatusWithDetails()
+
+	s.Equal(healthcheck.StatusDown, status)
+	s.Equal(latest, details["current_version"])
+	s.Equal([]int64{latest}, details["unknown"])
+}
+
+func (s *SchemaHealthIndicatorTestSuite) Test_Status_DownWhenUnreadable_LogsOnce() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	lg := &errorCountingLogger{BlankLogger: helper.NewBlankLogger()}
+	hi := infrapg.NewSchemaHealthIndicator(ctx, s.pgConn, s.migrations, lg)
+
+	for range 3 {
+		status, details := hi.StatusWithDetails()
+
+		s.Equal(healthcheck.StatusDown, status)
+		s.Equal(map[string]any{"error": "schema version unavailable"}, details)
+	}
+	s.Equal(1, lg.errors)
+}
+
+// errorCountingLogger counts the logged errors
+type errorCountingLogger struct {
+	*helper.BlankLogger
+	errors int
+}
+
+func (l *errorCountingLogger) Error(_ any, _ ...any) {
+	l.errors++
+}
+
+func TestSchemaHealthIndicatorSuite(t *testing.T) {
+	suite.Run(t, new(SchemaHealthIndicatorTestSuite))
+}
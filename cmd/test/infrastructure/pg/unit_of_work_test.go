@@ -0,0 +1,199 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/error"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+	infrapg "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/infrastructure/pg"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/infrastructure/pg/operation"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/test/helper"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/pgx"
+	"github.com/stretchr/testify/suite"
+)
+
+// UnitOfWorkTestSuite checks that the operations join the transaction of the context
+type UnitOfWorkTestSuite struct {
+	suite.Suite
+
+	pgConn       *pgx.ConnectionPool
+	uow          *infrapg.UnitOfWork
+	createClient *operation.CreateClient
+	getClient    *operation.GetClient
+}
+
+func (s *UnitOfWorkTestSuite) SetupSuite() {
+	pgCfg := helper.NewPostgresConfig()
+
+	pgConn, err := pgx.NewConnectionPool(context.Background(), pgx.Config{
+		ConnectionURL:     pgCfg.ConnectionURL(),
+		LogLevel:          "info",
+		MaxConnLifetime:   pgCfg.MaxConnLifetime(),
+		MaxConnIdleTime:   pgCfg.MaxConnIdleTime(),
+		QueryTimeout:      pgCfg.QueryTimeout(),
+		DefaultMaxConns:   pgCfg.DefaultMaxConns(),
+		DefaultMinConns:   pgCfg.DefaultMinConns(),
+		HealthCheckPeriod: pgCfg.HealthCheckPeriod(),
+	}, helper.NewBlankLogger(), helper.NewDummyMetrics())
+	if err != nil {
+		s.T().Fatal(err)
+	}
+
+	s.pgConn = pgConn
+	s.uow = infrapg.NewUnitOfWork(pgConn)
+	s.createClient = operation.NewCreateClientOperation(pgConn)
+	s.getClient = operation.NewGetClientOperation(pgConn)
+}
+
+// newClient removes the client with its audit and outbox rows after the test
+func (s *UnitOfWorkTestSuite) newClient(name string) handler.CreateClientDTO {
+	clientUUID := uuid.New()
+	s.T().Cleanup(func() {
+		ctx := context.Background()
+		for _, sql := range []string{
+			"DELETE FROM client WHERE uuid = @uuid::UUID",
+			"DELETE FROM client_audit WHERE client_uuid = @uuid::UUID",
+			"DELETE FROM outbox WHERE aggregate_id = @uuid",
+		} {
+			_, err := s.pgConn.Exec(ctx, "TestDeleteClient", sql, pgx.NamedArgs{"uuid": clientUUID.String()})
+			s.NoError(err)
+		}
+	})
+
+	return handler.CreateClientDTO{
+		Name:        name,
+		Email:       clientUUID.String() + "@uow.test",
+		DateOfBirth: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+		ClientUUID:  clientUUID,
+	}
+}
+
+func (s *UnitOfWorkTestSuite) requireNotFound(clientUUID uuid.UUID) {
+	_, err := s.getClient.GetForUUID(context.Background(), clientUUID)
+	var notFound *apperror.ClientNotFound
+	s.ErrorAs(err, &notFound)
+}
+
+func (s *UnitOfWorkTestSuite) requireFound(clientUUID uuid.UUID) {
+	_, err := s.getClient.GetForUUID(context.Background(), clientUUID)
+	s.NoError(err)
+}
+
+func (s *UnitOfWorkTestSuite) Test_Run_RollsBackOperations() {
+	p := s.newClient("UnitOfWork")
+	errAbort := errors.New("abort")
+
+	err := s.uow.Run(context.Background(), "create-and-abort", func(ctx context.Context) error {
+		err := s.createClient.Create(ctx, p)
+		if err != nil {
+			return err
+		}
+
+		// the client is visible inside of the unit of work
+		_, err = s.getClient.GetForUUID(ctx, p.ClientUUID)
+		if err != nil {
+			return err
+		}
+
+		return errAbort
+	})
+	s.ErrorIs(err, errAbort)
+	s.requireNotFound(p.ClientUUID)
+}
+
+func (s *UnitOfWorkTestSuite) Test_Run_InnerJoinsAmbientTransaction() {
+	outer := s.newClient("Outer")
+	inner := s.newClient("Inner")
+	errAbort := errors.New("abort")
+
+	err := s.uow.Run(context.Background(), "outer", func(ctx context.Context) error {
+		err := s.createClient.Create(ctx, outer)
+		if err != nil {
+			return err
+		}
+
+		err = s.uow.Run(ctx, "inner", func(ctx context.Context) error {
+			s.True(pgx.InTransaction(ctx))
+
+			// the uncommitted client of the outer unit of work is visible
+			_, err := s.getClient.GetForUUID(ctx, outer.ClientUUID)
+			if err != nil {
+				return err
+			}
+
+			return s.createClient.Create(ctx, inner)
+		})
+		if err != nil {
+			return err
+		}
+
+		return errAbort
+	})
+	s.ErrorIs(err, errAbort)
+
+	// the inner unit of work committed only its savepoint, the outer rollback discards it
+	s.requireNotFound(outer.ClientUUID)
+	s.requireNotFound(inner.ClientUUID)
+}
+
+func (s *UnitOfWorkTestSuite) Test_Run_RollsBackInnerToSavepoint() {
+	outer := s.newClient("Outer")
+	inner := s.newClient("Inner")
+	errAbort := errors.New("abort")
+
+	err := s.uow.Run(context.Background(), "outer", func(ctx context.Context) error {
+		err := s.createClient.Create(ctx, outer)
+		if err != nil {
+			return err
+		}
+
+		err = s.uow.Run(ctx, "inner", func(ctx context.Context) error {
+			err := s.createClient.Create(ctx, inner)
+			if err != nil {
+				return err
+			}
+
+			return errAbort
+		})
+		s.ErrorIs(err, errAbort)
+
+		// the outer transaction goes on after the savepoint is rolled back
+		_, err = s.getClient.GetForUUID(ctx, inner.ClientUUID)
+		var notFound *apperror.ClientNotFound
+		s.ErrorAs(err, &notFound)
+
+		return nil
+	})
+	s.NoError(err)
+
+	s.requireFound(outer.ClientUUID)
+	s.requireNotFound(inner.ClientUUID)
+}
+
+func (s *UnitOfWorkTestSuite) Test_Run_CommitsOuterWrites() {
+	outer := s.newClient("Outer")
+	inner := s.newClient("Inner")
+
+	err := s.uow.Run(context.Background(), "outer", func(ctx context.Context) error {
+		err := s.createClient.Create(ctx, outer)
+		if err != nil {
+			return err
+		}
+
+		return s.uow.Run(ctx, "inner", func(ctx context.Context) error {
+			return s.createClient.Create(ctx, inner)
+		})
+	})
+	s.NoError(err)
+
+	s.requireFound(outer.ClientUUID)
+	s.requireFound(inner.ClientUUID)
+}
+
+func TestUnitOfWorkSuite(t *testing.T) {
+	suite.Run(t, new(UnitOfWorkTestSuite))
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/audit"
+	apperror "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/error"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+	"github.com/stretchr/testify/suite"
+)
+
+// ClientImportRepositoryContractTestSuite is run against every handler.ClientImportRepository, the repository
+// may be shared with imports of other tests
+type ClientImportRepositoryContractTestSuite struct {
+	suite.Suite
+
+	newRepository func() handler.ClientImportRepository
+
+	repo handler.ClientImportRepository
+}
+
+func (s *ClientImportRepositoryContractTestSuite) SetupTest() {
+	s.repo = s.newRepository()
+}
+
+func (s *ClientImportRepositoryContractTestSuite) schedule() handler.ClientImportJobDTO {
+	job := handler.ClientImportJobDTO{
+		ImportUUID: uuid.New(),
+		Rows: []handler.ClientImportRow{{
+			Row: 1,
+			ID:  "1",
+			Client: handler.CreateClientDTO{
+				Name:        "alice",
+				Email:       uuid.NewString() + "@import.test",
+				DateOfBirth: time.Date(1990, 1, 2, 3, 4, 5, 0, time.UTC),
+				ClientUUID:  uuid.New(),
+			},
+		}},
+		Meta: audit.Meta{Actor: "john.doe", RequestID: "req-1"},
+	}
+
+	dto, err := s.repo.Create(context.Background(), job)
+	s.Require().NoError(err)
+	s.Equal(handler.ClientImportPending, dto.Status)
+	s.Equal(1, dto.TotalRows)
+
+	return job
+}
+
+// claim claims the imports until it gets the one of the job, the imports of other tests are claimed too
+func (s *ClientImportRepositoryContractTestSuite) claim(job handler.ClientImportJobDTO) handler.ClientImportJobDTO {
+	for {
+		claimed, ok, err := s.repo.Claim(context.Background(), time.Hour)
+		s.Require().NoError(err)
+		s.Require().True(ok, "the import was not claimed")
+		if claimed.ImportUUID == job.ImportUUID {
+			return claimed
+		}
+	}
+}
+
+func (s *ClientImportRepositoryContractTestSuite) Test_Complete() {
+	ctx := context.Background()
+	job := s.schedule()
+
+	claimed := s.claim(job)
+	s.Equal(job, claimed)
+	running, err := s.repo.GetForUUID(ctx, job.ImportUUID)
+	s.Require().NoError(err)
+	s.Equal(handler.ClientImportRunning, running.Status)
+
+	report := handler.ClientImportReportDTO{
+		Total:    1,
+		Accepted: 1,
+		Rows:     []handler.ClientImportRowResultDTO{{Row: 1, ID: "1", Status: "accepted"}},
+	}
+	s.Require().NoError(s.repo.Complete(ctx, job.ImportUUID, report))
+
+	completed, err := s.repo.GetForUUID(ctx, job.ImportUUID)
+	s.Require().NoError(err)
+	s.Equal(handler.ClientImportCompleted, completed.Status)
+	s.Equal(&report, completed.Report)
+	s.NotNil(completed.FinishedAt)
+}
+
+func (s *ClientImportRepositoryContractTestSuite) Test_Fail() {
+	ctx := context.Background()
+	job := s.schedule()
+
+	s.claim(job)
+	s.Require().NoError(s.repo.Fail(ctx, job.ImportUUID, "boom"))
+
+	failed, err := s.repo.GetForUUID(ctx, job.ImportUUID)
+	s.Require().NoError(err)
+	s.Equal(handler.ClientImportFailed, failed.Status)
+	s.Equal("boom", failed.Error)
+	s.Nil(failed.Report)
+}
+
+func (s *ClientImportRepositoryContractTestSuite) Test_UnknownImport() {
+	ctx := context.Background()
+	importUUID := uuid.New()
+
+	_, err := s.repo.GetForUUID(ctx, importUUID)
+	s.ErrorAs(err, new(*apperror.ClientImportNotFound))
+	s.ErrorAs(s.repo.Complete(ctx, importUUID, handler.ClientImportReportDTO{}), new(*apperror.ClientImportNotFound))
+	s.ErrorAs(s.repo.Fail(ctx, importUUID, "boom"), new(*apperror.ClientImportNotFound))
+}
@@ -0,0 +1,350 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/audit"
+	apperror "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/error"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+	"github.com/stretchr/testify/suite"
+)
+
+const dateOfBirthLayout = "2006-01-02T15:04:05-07:00"
+
+// ClientRepositoryContractTestSuite is run against every handler.ClientRepository, the repository may be shared
+// by the tests so every test uses its own email domain and purges its clients
+type ClientRepositoryContractTestSuite struct {
+	suite.Suite
+
+	newRepository func() handler.ClientRepository
+
+	repo    handler.ClientRepository
+	domain  string
+	created []uuid.UUID
+}
+
+func (s *ClientRepositoryContractTestSuite) SetupTest() {
+	s.repo = s.newRepository()
+	s.domain = uuid.NewString() + ".test"
+	s.created = nil
+}
+
+func (s *ClientRepositoryContractTestSuite) TearDownTest() {
+	ctx := context.Background()
+	for _, clientUUID := range s.created {
+		_ = s.repo.Delete(ctx, handler.DeleteClientDTO{ClientUUID: clientUUID})
+		_ = s.repo.PurgeForUUID(ctx, clientUUID)
+	}
+}
+
+func (s *ClientRepositoryContractTestSuite) newClient(name string) handler.CreateClientDTO {
+	p := handler.CreateClientDTO{
+		Name:        name,
+		Email:       name + "@" + s.domain,
+		DateOfBirth: time.Date(1990, 1, 2, 3, 4, 5, 0, time.UTC),
+		ClientUUID:  uuid.New(),
+	}
+	s.created = append(s.created, p.ClientUUID)
+
+	return p
+}
+
+func (s *ClientRepositoryContractTestSuite) create(name string) handler.CreateClientDTO {
+	p := s.newClient(name)
+	s.Require().NoError(s.repo.Create(context.Background(), p))
+
+	return p
+}
+
+func (s *ClientRepositoryContractTestSuite) get(clientUUID uuid.UUID) handler.GetClientDTO {
+	c, err := s.repo.GetForUUID(context.Background(), clientUUID)
+	s.Require().NoError(err)
+
+	return c
+}
+
+func (s *ClientRepositoryContractTestSuite) Test_Create_Get() {
+	p := s.create("alice")
+
+	c := s.get(p.ClientUUID)
+	s.Equal(p.ClientUUID, c.ClientUUID)
+	s.Equal(p.Name, c.Name)
+	s.Equal(p.Email, c.Email)
+	s.Equal("1990-01-02T03:04:05+00:00", c.DateOfBirth)
+	s.Positive(c.Version)
+}
+
+func (s *ClientRepositoryContractTestSuite) Test_Create_FailUUIDOrEmailTaken() {
+	ctx := context.Background()
+	p := s.create("alice")
+
+	sameUUID := s.newClient("bob")
+	sameUUID.ClientUUID = p.ClientUUID
+	s.ErrorAs(s.repo.Create(ctx, sameUUID), new(*apperror.ClientAlreadyExists))
+
+	sameEmail := s.newClient("carol")
+	sameEmail.Email = p.Email
+	s.ErrorAs(s.repo.Create(ctx, sameEmail), new(*apperror.ClientAlreadyExists))
+}
+
+func (s *ClientRepositoryContractTestSuite) Test_Create_ConcurrentlyWithSameEmail() {
+	ctx := context.Background()
+	email := "same@" + s.domain
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		created int
+	)
+	for i := range 10 {
+		p := s.newClient(fmt.Sprintf("client%d", i))
+		p.Email = email
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if s.repo.Create(ctx, p) == nil {
+				mu.Lock()
+				created++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	s.Equal(1, created)
+}
+
+func (s *ClientRepositoryContractTestSuite) Test_Get_FailNotFound() {
+	_, err := s.repo.GetForUUID(context.Background(), uuid.New())
+
+	s.ErrorAs(err, new(*apperror.ClientNotFound))
+}
+
+func (s *ClientRepositoryContractTestSuite) Test_Update() {
+	ctx := context.Background()
+	p := s.create("alice")
+	version := s.get(p.ClientUUID).Version
+
+	newVersion, err := s.repo.Update(ctx, handler.UpdateClientDTO{
+		Name:             "alicia",
+		Email:            "alicia@" + s.domain,
+		DateOfBirth:      time.Date(1991, 2, 3, 0, 0, 0, 0, time.UTC),
+		ClientUUID:       p.ClientUUID,
+		ExpectedVersions: []int64{version},
+	})
+	s.Require().NoError(err)
+	s.Greater(newVersion, version)
+
+	c := s.get(p.ClientUUID)
+	s.Equal("alicia", c.Name)
+	s.Equal("alicia@"+s.domain, c.Email)
+	s.Equal("1991-02-03T00:00:00+00:00", c.DateOfBirth)
+	s.Equal(newVersion, c.Version)
+
+	_, err = s.repo.Update(ctx, handler.UpdateClientDTO{
+		Name:             "alice",
+		Email:            p.Email,
+		DateOfBirth:      p.DateOfBirth,
+		ClientUUID:       p.ClientUUID,
+		ExpectedVersions: []int64{version},
+	})
+	s.ErrorAs(err, new(*apperror.ClientVersionMismatch))
+}
+
+func (s *ClientRepositoryContractTestSuite) Test_Update_FailNotFoundOrEmailTaken() {
+	ctx := context.Background()
+	alice := s.create("alice")
+	bob := s.create("bob")
+
+	_, err := s.repo.Update(ctx, handler.UpdateClientDTO{
+		Name:        "nobody",
+		Email:       "nobody@" + s.domain,
+		DateOfBirth: alice.DateOfBirth,
+		ClientUUID:  uuid.New(),
+	})
+	s.ErrorAs(err, new(*apperror.ClientNotFound))
+
+	_, err = s.repo.Update(ctx, handler.UpdateClientDTO{
+		Name:        bob.Name,
+		Email:       alice.Email,
+		DateOfBirth: bob.DateOfBirth,
+		ClientUUID:  bob.ClientUUID,
+	})
+	s.ErrorAs(err, new(*apperror.ClientAlreadyExists))
+}
+
+func (s *ClientRepositoryContractTestSuite) Test_Delete_ReleasesEmailOnly() {
+	ctx := context.Background()
+	p := s.create("alice")
+
+	s.ErrorAs(s.repo.Delete(ctx, handler.DeleteClientDTO{
+		ClientUUID:       p.ClientUUID,
+		ExpectedVersions: []int64{-1},
+	}), new(*apperror.ClientVersionMismatch))
+
+	s.Require().NoError(s.repo.Delete(ctx, handler.DeleteClientDTO{ClientUUID: p.ClientUUID}))
+
+	_, err := s.repo.GetForUUID(ctx, p.ClientUUID)
+	s.ErrorAs(err, new(*apperror.ClientNotFound))
+	s.ErrorAs(s.repo.Delete(ctx, handler.DeleteClientDTO{ClientUUID: p.ClientUUID}), new(*apperror.ClientNotFound))
+
+	s.ErrorAs(s.repo.Create(ctx, p), new(*apperror.ClientAlreadyExists))
+	other := s.newClient("bob")
+	other.Email = p.Email
+	s.NoError(s.repo.Create(ctx, other))
+}
+
+func (s *ClientRepositoryContractTestSuite) Test_Restore() {
+	ctx := context.Background()
+	p := s.create("alice")
+
+	s.ErrorAs(s.repo.RestoreForUUID(ctx, p.ClientUUID), new(*apperror.ClientNotFound))
+
+	s.Require().NoError(s.repo.Delete(ctx, handler.DeleteClientDTO{ClientUUID: p.ClientUUID}))
+	s.Require().NoError(s.repo.RestoreForUUID(ctx, p.ClientUUID))
+	s.Equal(p.Email, s.get(p.ClientUUID).Email)
+
+	s.Require().NoError(s.repo.Delete(ctx, handler.DeleteClientDTO{ClientUUID: p.ClientUUID}))
+	other := s.newClient("bob")
+	other.Email = p.Email
+	s.Require().NoError(s.repo.Create(ctx, other))
+	s.ErrorAs(s.repo.RestoreForUUID(ctx, p.ClientUUID), new(*apperror.ClientAlreadyExists))
+}
+
+func (s *ClientRepositoryContractTestSuite) Test_Purge() {
+	ctx := context.Background()
+	p := s.create("alice")
+
+	s.ErrorAs(s.repo.PurgeForUUID(ctx, p.ClientUUID), new(*apperror.ClientNotFound))
+
+	s.Require().NoError(s.repo.Delete(ctx, handler.DeleteClientDTO{ClientUUID: p.ClientUUID}))
+	_, err := s.repo.PurgeDeletedOlderThan(ctx, time.Hour)
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.repo.PurgeForUUID(ctx, p.ClientUUID))
+	s.ErrorAs(s.repo.RestoreForUUID(ctx, p.ClientUUID), new(*apperror.ClientNotFound))
+}
+
+func (s *ClientRepositoryContractTestSuite) Test_List() {
+	ctx := context.Background()
+	alice := s.create("alice")
+	anna := s.create("anna")
+	s.create("bob")
+	deleted := s.create("adam")
+	s.Require().NoError(s.repo.Delete(ctx, handler.DeleteClientDTO{ClientUUID: deleted.ClientUUID}))
+
+	filter := handler.ClientFilter{EmailDomain: s.domain, NamePrefix: "a"}
+	page, err := s.repo.List(ctx, handler.ListClientsQuery{Filter: filter, Limit: 1})
+	s.Require().NoError(err)
+	s.Require().Len(page.Clients, 1)
+	s.Equal(alice.ClientUUID, page.Clients[0].ClientUUID)
+	s.Equal(alice.DateOfBirth.Format(dateOfBirthLayout), page.Clients[0].DateOfBirth)
+	s.NotZero(page.NextAfterID)
+
+	page, err = s.repo.List(ctx, handler.ListClientsQuery{Filter: filter, AfterID: page.NextAfterID, Limit: 1})
+	s.Require().NoError(err)
+	s.Require().Len(page.Clients, 1)
+	s.Equal(anna.ClientUUID, page.Clients[0].ClientUUID)
+	s.Zero(page.NextAfterID)
+}
+
+func (s *ClientRepositoryContractTestSuite) Test_Import_SkipsTakenClients() {
+	ctx := context.Background()
+	existing := s.create("alice")
+
+	sameEmail := s.newClient("alicia")
+	sameEmail.Email = existing.Email
+	sameUUID := s.newClient("anna")
+	sameUUID.ClientUUID = existing.ClientUUID
+	bob := s.newClient("bob")
+
+	imported, err := s.repo.Import(ctx, []handler.CreateClientDTO{sameEmail, bob, sameUUID})
+	s.Require().NoError(err)
+	s.Equal([]uuid.UUID{bob.ClientUUID}, imported)
+	s.Equal(bob.Email, s.get(bob.ClientUUID).Email)
+}
+
+func (s *ClientRepositoryContractTestSuite) Test_ListHistory() {
+	ctx := audit.WithMeta(context.Background(), audit.Meta{Actor: "john.doe", RequestID: "req-1"})
+	p := s.newClient("alice")
+	s.Require().NoError(s.repo.Create(ctx, p))
+	_, err := s.repo.Update(ctx, handler.UpdateClientDTO{
+		Name:        "alicia",
+		Email:       p.Email,
+		DateOfBirth: p.DateOfBirth,
+		ClientUUID:  p.ClientUUID,
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(s.repo.Delete(ctx, handler.DeleteClientDTO{ClientUUID: p.ClientUUID}))
+	s.Require().NoError(s.repo.RestoreForUUID(ctx, p.ClientUUID))
+	s.Require().NoError(s.repo.Delete(ctx, handler.DeleteClientDTO{ClientUUID: p.ClientUUID}))
+	s.Require().NoError(s.repo.PurgeForUUID(ctx, p.ClientUUID))
+
+	page, err := s.repo.ListHistory(ctx, handler.ListClientHistoryQuery{ClientUUID: p.ClientUUID, Limit: 4})
+	s.Require().NoError(err)
+	s.Require().Len(page.Entries, 4)
+	s.NotZero(page.NextAfterID)
+	rest, err := s.repo.ListHistory(ctx, handler.ListClientHistoryQuery{ClientUUID: p.ClientUUID, AfterID: page.NextAfterID, Limit: 4})
+	s.Require().NoError(err)
+	s.Zero(rest.NextAfterID)
+	entries := append(page.Entries, rest.Entries...)
+
+	actions := make([]string, 0, len(entries))
+	for _, e := range entries {
+		actions = append(actions, e.Action)
+		s.Equal("john.doe", e.Actor)
+		s.Equal("req-1", e.RequestID)
+	}
+	s.Equal([]string{"create", "update", "delete", "restore", "delete", "purge"}, actions)
+
+	s.Nil(entries[0].Before)
+	s.Equal("alice", s.snapshot(entries[1].Before)["name"])
+	s.Equal("alicia", s.snapshot(entries[1].After)["name"])
+	s.Nil(s.snapshot(entries[1].After)["deleted_at"])
+	s.NotNil(s.snapshot(entries[2].After)["deleted_at"])
+	s.Equal(p.ClientUUID.String(), s.snapshot(entries[5].Before)["id"])
+	s.Nil(entries[5].After)
+
+	_, err = s.repo.ListHistory(ctx, handler.ListClientHistoryQuery{ClientUUID: uuid.New(), Limit: 4})
+	var notFound *apperror.ClientNotFound
+	s.ErrorAs(err, &notFound)
+
+	// a later page of a known client is just empty
+	empty, err := s.repo.ListHistory(ctx, handler.ListClientHistoryQuery{ClientUUID: p.ClientUUID, AfterID: math.MaxInt64, Limit: 4})
+	s.Require().NoError(err)
+	s.Empty(empty.Entries)
+}
+
+func (s *ClientRepositoryContractTestSuite) snapshot(raw json.RawMessage) map[string]any {
+	var snapshot map[string]any
+	s.Require().NoError(json.Unmarshal(raw, &snapshot))
+
+	return snapshot
+}
+
+func (s *ClientRepositoryContractTestSuite) Test_Export() {
+	ctx := context.Background()
+	alice := s.create("alice")
+	bob := s.create("bob")
+
+	var exported []uuid.UUID
+	err := s.repo.Export(ctx, handler.ClientFilter{EmailDomain: s.domain}, func(c handler.GetClientDTO) error {
+		exported = append(exported, c.ClientUUID)
+		return nil
+	})
+	s.Require().NoError(err)
+	s.Equal([]uuid.UUID{alice.ClientUUID, bob.ClientUUID}, exported)
+
+	stop := fmt.Errorf("stop")
+	err = s.repo.Export(ctx, handler.ClientFilter{EmailDomain: s.domain}, func(c handler.GetClientDTO) error {
+		return stop
+	})
+	s.ErrorIs(err, stop)
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/infrastructure/memory"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestMemoryClientRepositorySuite(t *testing.T) {
+	suite.Run(t, &ClientRepositoryContractTestSuite{
+		newRepository: func() handler.ClientRepository {
+			return memory.NewClientRepository()
+		},
+	})
+}
+
+func TestMemoryClientImportRepositorySuite(t *testing.T) {
+	suite.Run(t, &ClientImportRepositoryContractTestSuite{
+		newRepository: func() handler.ClientImportRepository {
+			return memory.NewClientImportRepository()
+		},
+	})
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/infrastructure/pg"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/infrastructure/pg/operation"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/test/helper"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/pgx"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestPostgresClientRepositorySuite(t *testing.T) {
+	pgCfg := helper.NewPostgresConfig()
+	pgConn, err := pgx.NewConnectionPool(context.Background(), pgx.Config{
+		ConnectionURL:     pgCfg.ConnectionURL(),
+		LogLevel:          "info",
+		MaxConnLifetime:   pgCfg.MaxConnLifetime(),
+		MaxConnIdleTime:   pgCfg.MaxConnIdleTime(),
+		QueryTimeout:      pgCfg.QueryTimeout(),
+		DefaultMaxConns:   pgCfg.DefaultMaxConns(),
+		DefaultMinConns:   pgCfg.DefaultMinConns(),
+		HealthCheckPeriod: pgCfg.HealthCheckPeriod(),
+	}, helper.NewBlankLogger(), helper.NewDummyMetrics())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := pg.NewClientRepository(pgConn)
+	suite.Run(t, &ClientRepositoryContractTestSuite{
+		newRepository: func() handler.ClientRepository {
+			return repo
+		},
+	})
+
+	importRepo := operation.NewClientImportOperation(pgConn)
+	suite.Run(t, &ClientImportRepositoryContractTestSuite{
+		newRepository: func() handler.ClientImportRepository {
+			return importRepo
+		},
+	})
+}
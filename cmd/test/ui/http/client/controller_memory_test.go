@@ -0,0 +1,361 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/infrastructure/memory"
+	pkghttp "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/ui/http"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/ui/http/v1/client"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/test/helper"
+	"github.com/stretchr/testify/suite"
+)
+
+// ClientControllerMemoryTestSuite runs the client endpoints against the in-memory repositories, it needs no Postgres
+type ClientControllerMemoryTestSuite struct {
+	suite.Suite
+
+	clientCTRL *client.Controller
+}
+
+func (s *ClientControllerMemoryTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	clients := memory.NewClientRepository()
+	clientImports := memory.NewClientImportRepository()
+	s.clientCTRL = client.NewController(client.Handlers{
+		CreateClient:  handler.NewCreateClientHandler(clients),
+		GetClient:     handler.NewGetClientHandler(clients),
+		UpdateClient:  handler.NewUpdateClientHandler(clients),
+		DeleteClient:  handler.NewDeleteClientHandler(clients),
+		ListClients:   handler.NewListClientsHandler(clients),
+		RestoreClient: handler.NewRestoreClientHandler(clients),
+		PurgeClient:   handler.NewPurgeClientHandler(clients),
+		ClientHistory: handler.NewListClientHistoryHandler(clients),
+
+		ImportClients:        handler.NewImportClientsHandler(clients),
+		ScheduleClientImport: handler.NewScheduleClientImportHandler(clientImports),
+		GetClientImport:      handler.NewGetClientImportHandler(clientImports),
+		ExportClients:        handler.NewExportClientsHandler(clients),
+	})
+}
+
+func (s *ClientControllerMemoryTestSuite) serve(method, route, path string, h gin.HandlerFunc, body any, headers map[string]string) *httptest.ResponseRecorder {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			s.T().Fatal(err)
+		}
+		reqBody = bytes.NewBuffer(b)
+	}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest(method, path, reqBody)
+	r.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+
+	_, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(helper.NewBlankLogger()))
+	engine.Handle(method, route, h)
+	engine.ServeHTTP(w, r)
+
+	return w
+}
+
+func (s *ClientControllerMemoryTestSuite) createClient(clientUUID uuid.UUID, email string) {
+	w := s.serve(http.MethodPost, "/v1/client", "/v1/client", s.clientCTRL.CreateClient, map[string]string{
+		"email":         email,
+		"name":          "MyMan",
+		"date_of_birth": "2020-01-01T12:12:34+00:00",
+		"id":            clientUUID.String(),
+	}, nil)
+	s.Require().Equal(http.StatusCreated, w.Code)
+}
+
+func (s *ClientControllerMemoryTestSuite) getClient(clientUUID uuid.UUID) *httptest.ResponseRecorder {
+	return s.serve(http.MethodGet, "/v1/client/:id", "/v1/client/"+clientUUID.String(), s.clientCTRL.GetClient, nil, nil)
+}
+
+func (s *ClientControllerMemoryTestSuite) deleteClient(clientUUID uuid.UUID) *httptest.ResponseRecorder {
+	return s.serve(http.MethodDelete, "/v1/client/:id", "/v1/client/"+clientUUID.String(), s.clientCTRL.DeleteClient, nil, map[string]string{
+		"If-Match": "*",
+	})
+}
+
+func (s *ClientControllerMemoryTestSuite) Test_CreateAndGetClient() {
+	clientUUID := uuid.New()
+	s.createClient(clientUUID, "myman@myman.cz")
+
+	w := s.getClient(clientUUID)
+	s.Equal(http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	s.Equal("MyMan", response["name"])
+	s.Equal("<myman@myman.cz>", response["email"])
+	s.Equal("2020-01-01T12:12:34+00:00", response["date_of_birth"])
+	s.Equal(clientUUID.String(), response["id"])
+	s.NotEmpty(w.Header().Get("ETag"))
+}
+
+func (s *ClientControllerMemoryTestSuite) Test_CreateClient_FailClientAlreadyExist() {
+	s.createClient(uuid.New(), "myman@myman.cz")
+
+	w := s.serve(http.MethodPost, "/v1/client", "/v1/client", s.clientCTRL.CreateClient, map[string]string{
+		"email":         "myman@myman.cz",
+		"name":          "MyMan",
+		"date_of_birth": "2020-01-01T12:12:34+00:00",
+		"id":            uuid.NewString(),
+	}, nil)
+
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+}
+
+func (s *ClientControllerMemoryTestSuite) Test_CreateClient_FailValidation() {
+	futureDateOfBirth := time.Now().AddDate(1, 0, 0).Format(dateOfBirthLayout)
+
+	for _, tc := range []struct {
+		name       string
+		body       any
+		statusCode int
+		code       string
+		violations []pkghttp.FieldViolation
+	}{
+		{
+			name: "all fields invalid",
+			body: map[string]string{
+				"email":         "myman",
+				"name":          "M",
+				"date_of_birth": futureDateOfBirth,
+				"id":            "not-a-uuid",
+			},
+			statusCode: http.StatusUnprocessableEntity,
+			code:       pkghttp.ValidationFailedCode,
+			violations: []pkghttp.FieldViolation{
+				{Field: "email", Code: pkghttp.ViolationInvalidFormat, Message: "invalid email"},
+				{Field: "name", Code: pkghttp.ViolationTooShort, Message: "name has to have at least 2 characters"},
+				{Field: "date_of_birth", Code: pkghttp.ViolationInFuture, Message: "date of birth is in the future"},
+				{Field: "id", Code: pkghttp.ViolationInvalidFormat, Message: "invalid client id"},
+			},
+		},
+		{
+			name: "name characters and too old",
+			body: map[string]string{
+				"email":         "myman@myman.cz",
+				"name":          "MyMan <script>",
+				"date_of_birth": "1800-01-01T00:00:00+00:00",
+				"id":            uuid.NewString(),
+			},
+			statusCode: http.StatusUnprocessableEntity,
+			code:       pkghttp.ValidationFailedCode,
+			violations: []pkghttp.FieldViolation{
+				{Field: "name", Code: pkghttp.ViolationInvalidCharacters, Message: "name can contain only letters, spaces, apostrophes, dots and hyphens"},
+				{Field: "date_of_birth", Code: pkghttp.ViolationOutOfRange, Message: "date of birth is more than 150 years ago"},
+			},
+		},
+		{
+			name: "too long name and invalid date",
+			body: map[string]string{
+				"email":         "myman@myman.cz",
+				"name":          strings.Repeat("Ž", 101),
+				"date_of_birth": "2020-01-01",
+				"id":            uuid.NewString(),
+			},
+			statusCode: http.StatusUnprocessableEntity,
+			code:       pkghttp.ValidationFailedCode,
+			violations: []pkghttp.FieldViolation{
+				{Field: "name", Code: pkghttp.ViolationTooLong, Message: "name has to have at most 100 characters"},
+				{Field: "date_of_birth", Code: pkghttp.ViolationInvalidFormat, Message: "invalid date of birth"},
+			},
+		},
+		{
+			name:       "wrong type",
+			body:       map[string]any{"email": 42},
+			statusCode: http.StatusBadRequest,
+			code:       pkghttp.MalformedRequestCode,
+			violations: []pkghttp.FieldViolation{
+				{Field: "email", Code: pkghttp.ViolationInvalidType, Message: "email has to be a string"},
+			},
+		},
+	} {
+		s.Run(tc.name, func() {
+			w := s.serve(http.MethodPost, "/v1/client", "/v1/client", s.clientCTRL.CreateClient, tc.body, nil)
+			s.Equal(tc.statusCode, w.Code)
+
+			var response pkghttp.Problem
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			if err != nil {
+				s.T().Fatal(err)
+			}
+			s.Equal(tc.code, response.Code)
+			s.Equal(tc.statusCode, response.Status)
+			s.Equal(tc.violations, response.Violations)
+		})
+	}
+}
+
+func (s *ClientControllerMemoryTestSuite) Test_CreateClient_AcceptsAccentedName() {
+	clientUUID := uuid.New()
+	w := s.serve(http.MethodPost, "/v1/client", "/v1/client", s.clientCTRL.CreateClient, map[string]string{
+		"email":         "zofie@myman.cz",
+		"name":          "Žofie O'Brien-Dvořáková Jr.",
+		"date_of_birth": "1990-01-01T00:00:00+02:00",
+		"id":            clientUUID.String(),
+	}, nil)
+	s.Equal(http.StatusCreated, w.Code)
+}
+
+func (s *ClientControllerMemoryTestSuite) Test_UpdateClient_IfMatch() {
+	clientUUID := uuid.New()
+	s.createClient(clientUUID, "myman@myman.cz")
+	etag := s.getClient(clientUUID).Header().Get("ETag")
+
+	update := map[string]string{
+		"email":         "myman.updated@myman.cz",
+		"name":          "MyMan Updated",
+		"date_of_birth": "2021-02-02T10:10:10+00:00",
+	}
+	w := s.serve(http.MethodPut, "/v1/client/:id", "/v1/client/"+clientUUID.String(), s.clientCTRL.UpdateClient, update, map[string]string{
+		"If-Match": etag,
+	})
+	s.Equal(http.StatusNoContent, w.Code)
+
+	w = s.serve(http.MethodPut, "/v1/client/:id", "/v1/client/"+clientUUID.String(), s.clientCTRL.UpdateClient, update, map[string]string{
+		"If-Match": etag,
+	})
+	s.Equal(http.StatusPreconditionFailed, w.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(s.getClient(clientUUID).Body.Bytes(), &response)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	s.Equal("<myman.updated@myman.cz>", response["email"])
+}
+
+func (s *ClientControllerMemoryTestSuite) Test_DeleteRestorePurgeClient() {
+	clientUUID := uuid.New()
+	s.createClient(clientUUID, "myman@myman.cz")
+
+	s.Equal(http.StatusNoContent, s.deleteClient(clientUUID).Code)
+	s.Equal(http.StatusNotFound, s.getClient(clientUUID).Code)
+	s.Equal(http.StatusNotFound, s.deleteClient(clientUUID).Code)
+
+	w := s.serve(http.MethodPost, "/v1/client/:id/restore", "/v1/client/"+clientUUID.String()+"/restore", s.clientCTRL.RestoreClient, nil, nil)
+	s.Equal(http.StatusNoContent, w.Code)
+	s.Equal(http.StatusOK, s.getClient(clientUUID).Code)
+
+	w = s.serve(http.MethodDelete, "/v1/client/:id/purge", "/v1/client/"+clientUUID.String()+"/purge", s.clientCTRL.PurgeClient, nil, nil)
+	s.Equal(http.StatusNotFound, w.Code)
+
+	s.Equal(http.StatusNoContent, s.deleteClient(clientUUID).Code)
+	w = s.serve(http.MethodDelete, "/v1/client/:id/purge", "/v1/client/"+clientUUID.String()+"/purge", s.clientCTRL.PurgeClient, nil, nil)
+	s.Equal(http.StatusNoContent, w.Code)
+}
+
+func (s *ClientControllerMemoryTestSuite) Test_ClientHistory() {
+	clientUUID := uuid.New()
+	s.createClient(clientUUID, "myman@myman.cz")
+	s.Equal(http.StatusNoContent, s.deleteClient(clientUUID).Code)
+
+	w := s.serve(http.MethodGet, "/v1/client/:id/history", "/v1/client/"+clientUUID.String()+"/history", s.clientCTRL.ClientHistory, nil, nil)
+	s.Equal(http.StatusOK, w.Code)
+
+	var response client.ClientHistoryResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	s.Require().Len(response.Items, 2)
+	s.Equal("create", response.Items[0].Action)
+	s.Equal("delete", response.Items[1].Action)
+	s.Empty(response.NextCursor)
+}
+
+func (s *ClientControllerMemoryTestSuite) Test_ClientHistory_FailClientNotFound() {
+	w := s.serve(http.MethodGet, "/v1/client/:id/history", "/v1/client/"+uuid.NewString()+"/history", s.clientCTRL.ClientHistory, nil, nil)
+	s.Equal(http.StatusNotFound, w.Code)
+
+	var response map[string]any
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	s.Equal("client_not_found", response["code"])
+}
+
+func (s *ClientControllerMemoryTestSuite) Test_ListClients_Pagination() {
+	clientUUIDs := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	for i, clientUUID := range clientUUIDs {
+		s.createClient(clientUUID, fmt.Sprintf("myman%d@listing.myman.cz", i))
+	}
+
+	listedUUIDs := make([]string, 0, len(clientUUIDs))
+	cursor := ""
+	for page := 0; page < len(clientUUIDs); page++ {
+		w := s.serve(http.MethodGet, "/v1/client", "/v1/client?limit=2&cursor="+cursor, s.clientCTRL.ListClients, nil, nil)
+		s.Equal(http.StatusOK, w.Code)
+
+		var response client.ListClientsResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		if err != nil {
+			s.T().Fatal(err)
+		}
+		for _, item := range response.Items {
+			listedUUIDs = append(listedUUIDs, item.ID)
+		}
+
+		cursor = response.NextCursor
+		if cursor == "" {
+			break
+		}
+	}
+
+	s.Equal([]string{clientUUIDs[0].String(), clientUUIDs[1].String(), clientUUIDs[2].String()}, listedUUIDs)
+}
+
+func (s *ClientControllerMemoryTestSuite) importClients(body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest(http.MethodPost, "/v1/client/import", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-ndjson")
+
+	_, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(helper.NewBlankLogger()))
+	engine.Handle(http.MethodPost, "/v1/client/import", s.clientCTRL.ImportClients)
+	engine.ServeHTTP(w, r)
+
+	return w
+}
+
+func (s *ClientControllerMemoryTestSuite) Test_ImportClients_Fail_TooManyRows() {
+	w := s.importClients(strings.Repeat("{}\n", 50_001))
+	s.Equal(http.StatusRequestEntityTooLarge, w.Code)
+	s.Contains(w.Body.String(), "payload_too_large")
+}
+
+func (s *ClientControllerMemoryTestSuite) Test_ImportClients_Fail_BodyTooLarge() {
+	line := `{"name":"` + strings.Repeat("a", 1000) + `"}` + "\n"
+	w := s.importClients(strings.Repeat(line, 9000))
+	s.Equal(http.StatusRequestEntityTooLarge, w.Code)
+	s.Contains(w.Body.String(), "payload_too_large")
+}
+
+func TestClientControllerMemorySuite(t *testing.T) {
+	suite.Run(t, new(ClientControllerMemoryTestSuite))
+}
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/mail"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,9 +16,11 @@ import (
 	"github.com/google/uuid"
 	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
 	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/infrastructure/pg/operation"
+	pkghttp "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/ui/http"
 	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/ui/http/v1/client"
 	"github.com/jamm3e3333/whalebone-go-test-project/cmd/test/helper"
 	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
+	pkgGin "github.com/jamm3e3333/whalebone-go-test-project/pkg/net/http/gin"
 	"github.com/jamm3e3333/whalebone-go-test-project/pkg/pgx"
 	"github.com/stretchr/testify/suite"
 )
@@ -28,16 +32,19 @@ type ClientControllerTestSuite struct {
 
 	lg logger.Logger
 
-	clientCTRL *client.Controller
-	pgConn     pgx.Connection
+	clientCTRL  *client.Controller
+	idempotency gin.HandlerFunc
+	pgConn      pgx.Connection
 }
 
 type clientResultRow struct {
-	ID          int64     `db:"id"`
-	Email       string    `db:"email"`
-	Name        string    `db:"name"`
-	UUID        string    `db:"uuid"`
-	DateOfBirth time.Time `db:"date_of_birth"`
+	ID          int64      `db:"id"`
+	Email       string     `db:"email"`
+	Name        string     `db:"name"`
+	UUID        string     `db:"uuid"`
+	DateOfBirth time.Time  `db:"date_of_birth"`
+	DeletedAt   *time.Time `db:"deleted_at"`
+	Version     int64      `db:"version"`
 }
 
 func (s *ClientControllerTestSuite) SetupSuite() {
@@ -61,10 +68,30 @@ func (s *ClientControllerTestSuite) SetupSuite() {
 
 	createClient := operation.NewCreateClientOperation(s.pgConn)
 	getClient := operation.NewGetClientOperation(s.pgConn)
-	createClientHan := handler.NewCreateClientHandler(createClient)
-	getClientHan := handler.NewGetClientHandler(getClient)
+	updateClient := operation.NewUpdateClientOperation(s.pgConn)
+	deleteClient := operation.NewDeleteClientOperation(s.pgConn)
+	listClients := operation.NewListClientsOperation(s.pgConn)
+	restoreClient := operation.NewRestoreClientOperation(s.pgConn)
+	purgeClient := operation.NewPurgeClientOperation(s.pgConn)
+	listClientHistory := operation.NewListClientHistoryOperation(s.pgConn)
+	clientImport := operation.NewClientImportOperation(s.pgConn)
 
-	s.clientCTRL = client.NewController(createClientHan, getClientHan)
+	s.clientCTRL = client.NewController(client.Handlers{
+		CreateClient:  handler.NewCreateClientHandler(createClient),
+		GetClient:     handler.NewGetClientHandler(getClient),
+		UpdateClient:  handler.NewUpdateClientHandler(updateClient),
+		DeleteClient:  handler.NewDeleteClientHandler(deleteClient),
+		ListClients:   handler.NewListClientsHandler(listClients),
+		RestoreClient: handler.NewRestoreClientHandler(restoreClient),
+		PurgeClient:   handler.NewPurgeClientHandler(purgeClient),
+		ClientHistory: handler.NewListClientHistoryHandler(listClientHistory),
+
+		ImportClients:        handler.NewImportClientsHandler(operation.NewImportClientsOperation(s.pgConn)),
+		ScheduleClientImport: handler.NewScheduleClientImportHandler(clientImport),
+		GetClientImport:      handler.NewGetClientImportHandler(clientImport),
+		ExportClients:        handler.NewExportClientsHandler(operation.NewExportClientsOperation(s.pgConn)),
+	})
+	s.idempotency = pkgGin.IdempotencyMiddleware(operation.NewIdempotencyKeyOperation(s.pgConn), time.Hour, s.lg)
 }
 
 func (s *ClientControllerTestSuite) TearDownSuite() {
@@ -101,6 +128,7 @@ func (s *ClientControllerTestSuite) Test_CreateClient_Success() {
 	r.Header.Set("Content-Type", "application/json")
 
 	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
 	ctx.Request = r
 
 	engine.Handle("POST", "/v1/client", s.clientCTRL.CreateClient)
@@ -190,6 +218,7 @@ func (s *ClientControllerTestSuite) Test_GetClient_Success() {
 	r.Header.Set("Content-Type", "application/json")
 
 	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
 	ctx.Request = r
 	ctx.AddParam("id", clientUUID.String())
 
@@ -276,6 +305,7 @@ func (s *ClientControllerTestSuite) Test_CreateClient_FailClientAlreadyExist() {
 	r.Header.Set("Content-Type", "application/json")
 
 	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
 	ctx.Request = r
 
 	engine.Handle("POST", "/v1/client", s.clientCTRL.CreateClient)
@@ -285,7 +315,7 @@ func (s *ClientControllerTestSuite) Test_CreateClient_FailClientAlreadyExist() {
 	err = json.Unmarshal(w.Body.Bytes(), &response)
 
 	s.Equal(http.StatusUnprocessableEntity, w.Code)
-	s.Equal("client already exists", response["error"])
+	s.Equal("client_already_exists", response["code"])
 
 	s.T().Cleanup(func() {
 		r, cancel, err := s.pgConn.Query(
@@ -325,12 +355,25 @@ func (s *ClientControllerTestSuite) Test_CreateClient_Fail_InvalidEmail() {
 	r.Header.Set("Content-Type", "application/json")
 
 	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
 	ctx.Request = r
 
 	engine.Handle("POST", "/v1/client", s.clientCTRL.CreateClient)
 	engine.HandleContext(ctx)
 
-	s.Equal(http.StatusBadRequest, w.Code)
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+
+	var response pkghttp.Problem
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	s.Equal([]pkghttp.FieldViolation{
+		{Field: "email", Code: pkghttp.ViolationInvalidFormat, Message: "invalid email"},
+		{Field: "name", Code: pkghttp.ViolationRequired, Message: "name is required"},
+		{Field: "date_of_birth", Code: pkghttp.ViolationRequired, Message: "date of birth is required"},
+		{Field: "id", Code: pkghttp.ViolationRequired, Message: "client id is required"},
+	}, response.Violations)
 }
 
 func (s *ClientControllerTestSuite) Test_GetClient_FailClientNotFound() {
@@ -342,6 +385,7 @@ func (s *ClientControllerTestSuite) Test_GetClient_FailClientNotFound() {
 	r.Header.Set("Content-Type", "application/json")
 
 	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
 	ctx.Request = r
 	ctx.AddParam("id", clientUUID.String())
 
@@ -355,7 +399,1205 @@ func (s *ClientControllerTestSuite) Test_GetClient_FailClientNotFound() {
 	}
 
 	s.Equal(http.StatusNotFound, w.Code)
-	s.Equal("client not found", response["error"])
+	s.Equal("client_not_found", response["code"])
+}
+
+func (s *ClientControllerTestSuite) insertClient(clientUUID uuid.UUID, email, name, dateOfBirth string) {
+	r, cancel, err := s.pgConn.Query(
+		context.Background(),
+		"TestCreateClient",
+		"INSERT INTO client ( email, name, uuid, date_of_birth) VALUES (@email, @name, @uuid, @dateOfBirth);",
+		pgx.NamedArgs{
+			"email":       email,
+			"name":        name,
+			"uuid":        clientUUID.String(),
+			"dateOfBirth": dateOfBirth,
+		},
+	)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	defer cancel()
+
+	if err := (*r).Err(); err != nil {
+		s.T().Fatal(err)
+	}
+
+	s.T().Cleanup(func() {
+		r, cancel, err := s.pgConn.Query(
+			context.Background(),
+			"TestDeleteClient",
+			"DELETE FROM client WHERE uuid = @clientUUID",
+			pgx.NamedArgs{"clientUUID": clientUUID.String()},
+		)
+		if err != nil {
+			s.T().Fatal(err)
+		}
+		defer cancel()
+
+		if err := (*r).Err(); err != nil {
+			s.T().Fatal(err)
+		}
+	})
+}
+
+func (s *ClientControllerTestSuite) selectClient(clientUUID uuid.UUID) (clientResultRow, error) {
+	row, cancel := s.pgConn.QueryRow(
+		context.Background(),
+		"TestGetClient",
+		"SELECT id, email, name, uuid, date_of_birth, deleted_at, version FROM client WHERE uuid = @uuid ORDER BY id DESC LIMIT 1;",
+		pgx.NamedArgs{"uuid": clientUUID.String()},
+	)
+	defer cancel()
+
+	clientRow := clientResultRow{}
+	err := (*row).Scan(
+		&clientRow.ID,
+		&clientRow.Email,
+		&clientRow.Name,
+		&clientRow.UUID,
+		&clientRow.DateOfBirth,
+		&clientRow.DeletedAt,
+		&clientRow.Version,
+	)
+
+	return clientRow, err
+}
+
+func (s *ClientControllerTestSuite) Test_UpdateClient_Success() {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	clientUUID := uuid.New()
+
+	const (
+		clientEmail       = "myman@myman.cz"
+		clientName        = "MyMan"
+		clientDateOfBirth = "2020-01-01T12:12:34+00:00"
+
+		updatedEmail       = "myman.updated@myman.cz"
+		updatedName        = "MyMan Updated"
+		updatedDateOfBirth = "2021-02-02T10:10:10+00:00"
+	)
+	s.insertClient(clientUUID, clientEmail, clientName, clientDateOfBirth)
+
+	body, err := json.Marshal(map[string]string{
+		"email":         updatedEmail,
+		"name":          updatedName,
+		"date_of_birth": updatedDateOfBirth,
+	})
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	r, _ := http.NewRequest(http.MethodPut, "/v1/client/"+clientUUID.String(), bytes.NewBuffer(body))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("If-Match", "*")
+
+	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
+	ctx.Request = r
+	ctx.AddParam("id", clientUUID.String())
+
+	engine.Handle("PUT", "/v1/client/:id", s.clientCTRL.UpdateClient)
+	engine.HandleContext(ctx)
+
+	s.Equal(http.StatusNoContent, w.Code)
+
+	clientRow, err := s.selectClient(clientUUID)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	s.Equal(updatedEmail, clientRow.Email)
+	s.Equal(updatedName, clientRow.Name)
+	s.Equal(updatedDateOfBirth, clientRow.DateOfBirth.Format(dateOfBirthLayout))
+}
+
+func (s *ClientControllerTestSuite) Test_UpdateClient_FailClientNotFound() {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	clientUUID := uuid.New()
+
+	body, err := json.Marshal(map[string]string{
+		"email":         "myman@myman.cz",
+		"name":          "MyMan",
+		"date_of_birth": "2020-01-01T12:12:34+00:00",
+	})
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	r, _ := http.NewRequest(http.MethodPut, "/v1/client/"+clientUUID.String(), bytes.NewBuffer(body))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("If-Match", "*")
+
+	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
+	ctx.Request = r
+	ctx.AddParam("id", clientUUID.String())
+
+	engine.Handle("PUT", "/v1/client/:id", s.clientCTRL.UpdateClient)
+	engine.HandleContext(ctx)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+
+	s.Equal(http.StatusNotFound, w.Code)
+	s.Equal("client_not_found", response["code"])
+}
+
+func (s *ClientControllerTestSuite) Test_UpdateClient_FailClientAlreadyExist() {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	clientUUID := uuid.New()
+	otherClientUUID := uuid.New()
+
+	const (
+		clientEmail       = "myman@myman.cz"
+		otherClientEmail  = "myotherman@myman.cz"
+		clientName        = "MyMan"
+		clientDateOfBirth = "2020-01-01T12:12:34+00:00"
+	)
+	s.insertClient(clientUUID, clientEmail, clientName, clientDateOfBirth)
+	s.insertClient(otherClientUUID, otherClientEmail, clientName, clientDateOfBirth)
+
+	body, err := json.Marshal(map[string]string{
+		"email":         otherClientEmail,
+		"name":          clientName,
+		"date_of_birth": clientDateOfBirth,
+	})
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	r, _ := http.NewRequest(http.MethodPut, "/v1/client/"+clientUUID.String(), bytes.NewBuffer(body))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("If-Match", "*")
+
+	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
+	ctx.Request = r
+	ctx.AddParam("id", clientUUID.String())
+
+	engine.Handle("PUT", "/v1/client/:id", s.clientCTRL.UpdateClient)
+	engine.HandleContext(ctx)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+	s.Equal("client_already_exists", response["code"])
+}
+
+func (s *ClientControllerTestSuite) Test_PatchClient_Success() {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	clientUUID := uuid.New()
+
+	const (
+		clientEmail       = "myman@myman.cz"
+		clientName        = "MyMan"
+		clientDateOfBirth = "2020-01-01T12:12:34+00:00"
+
+		patchedName = "MyMan Patched"
+	)
+	s.insertClient(clientUUID, clientEmail, clientName, clientDateOfBirth)
+
+	body, err := json.Marshal(map[string]string{
+		"name": patchedName,
+	})
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	r, _ := http.NewRequest(http.MethodPatch, "/v1/client/"+clientUUID.String(), bytes.NewBuffer(body))
+	r.Header.Set("Content-Type", "application/merge-patch+json")
+	r.Header.Set("If-Match", "*")
+
+	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
+	ctx.Request = r
+	ctx.AddParam("id", clientUUID.String())
+
+	engine.Handle("PATCH", "/v1/client/:id", s.clientCTRL.PatchClient)
+	engine.HandleContext(ctx)
+
+	s.Equal(http.StatusNoContent, w.Code)
+
+	clientRow, err := s.selectClient(clientUUID)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	s.Equal(clientEmail, clientRow.Email)
+	s.Equal(patchedName, clientRow.Name)
+	s.Equal(clientDateOfBirth, clientRow.DateOfBirth.Format(dateOfBirthLayout))
+}
+
+func (s *ClientControllerTestSuite) Test_PatchClient_Fail_RemoveRequiredField() {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	clientUUID := uuid.New()
+
+	s.insertClient(clientUUID, "myman@myman.cz", "MyMan", "2020-01-01T12:12:34+00:00")
+
+	r, _ := http.NewRequest(http.MethodPatch, "/v1/client/"+clientUUID.String(), bytes.NewBufferString(`{"name": null}`))
+	r.Header.Set("Content-Type", "application/merge-patch+json")
+	r.Header.Set("If-Match", "*")
+
+	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
+	ctx.Request = r
+	ctx.AddParam("id", clientUUID.String())
+
+	engine.Handle("PATCH", "/v1/client/:id", s.clientCTRL.PatchClient)
+	engine.HandleContext(ctx)
+
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+}
+
+func (s *ClientControllerTestSuite) Test_DeleteClient_Success() {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	clientUUID := uuid.New()
+
+	s.insertClient(clientUUID, "myman@myman.cz", "MyMan", "2020-01-01T12:12:34+00:00")
+
+	r, _ := http.NewRequest(http.MethodDelete, "/v1/client/"+clientUUID.String(), nil)
+	r.Header.Set("If-Match", "*")
+
+	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
+	ctx.Request = r
+	ctx.AddParam("id", clientUUID.String())
+
+	engine.Handle("DELETE", "/v1/client/:id", s.clientCTRL.DeleteClient)
+	engine.HandleContext(ctx)
+
+	s.Equal(http.StatusNoContent, w.Code)
+
+	clientRow, err := s.selectClient(clientUUID)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	s.NotNil(clientRow.DeletedAt)
+}
+
+func (s *ClientControllerTestSuite) Test_DeleteClient_FailClientNotFound() {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	clientUUID := uuid.New()
+
+	r, _ := http.NewRequest(http.MethodDelete, "/v1/client/"+clientUUID.String(), nil)
+	r.Header.Set("If-Match", "*")
+
+	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
+	ctx.Request = r
+	ctx.AddParam("id", clientUUID.String())
+
+	engine.Handle("DELETE", "/v1/client/:id", s.clientCTRL.DeleteClient)
+	engine.HandleContext(ctx)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+
+	s.Equal(http.StatusNotFound, w.Code)
+	s.Equal("client_not_found", response["code"])
+}
+
+func (s *ClientControllerTestSuite) Test_ListClients_Pagination() {
+	gin.SetMode(gin.TestMode)
+
+	const (
+		clientName        = "MyMan"
+		clientDateOfBirth = "2020-01-01T12:12:34+00:00"
+	)
+	clientUUIDs := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	for i, clientUUID := range clientUUIDs {
+		s.insertClient(clientUUID, fmt.Sprintf("myman%d@listing.myman.cz", i), clientName, clientDateOfBirth)
+	}
+
+	listedUUIDs := make([]string, 0, len(clientUUIDs))
+	cursor := ""
+	for page := 0; page < len(clientUUIDs); page++ {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/v1/client?email_domain=listing.myman.cz&limit=2&cursor="+cursor, nil)
+
+		ctx, engine := gin.CreateTestContext(w)
+		engine.Use(pkghttp.ErrorMiddleware(s.lg))
+		ctx.Request = r
+
+		engine.Handle("GET", "/v1/client", s.clientCTRL.ListClients)
+		engine.HandleContext(ctx)
+
+		s.Equal(http.StatusOK, w.Code)
+
+		var response client.ListClientsResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		if err != nil {
+			s.T().Fatal(err)
+		}
+		for _, item := range response.Items {
+			listedUUIDs = append(listedUUIDs, item.ID)
+		}
+
+		cursor = response.NextCursor
+		if cursor == "" {
+			break
+		}
+	}
+
+	s.Equal([]string{clientUUIDs[0].String(), clientUUIDs[1].String(), clientUUIDs[2].String()}, listedUUIDs)
+}
+
+func (s *ClientControllerTestSuite) Test_ListClients_Filter() {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	matchingUUID := uuid.New()
+
+	s.insertClient(matchingUUID, "john@filter.myman.cz", "John Doe", "1990-05-05T10:00:00+00:00")
+	s.insertClient(uuid.New(), "jane@filter.myman.cz", "Jane Doe", "1990-05-05T10:00:00+00:00")
+	s.insertClient(uuid.New(), "johnny@filter.myman.cz", "Johnny Doe", "2010-05-05T10:00:00+00:00")
+
+	r, _ := http.NewRequest(
+		http.MethodGet,
+		"/v1/client?email_domain=filter.myman.cz&name_prefix=John&date_of_birth_from=1990-01-01T00:00:00Z&date_of_birth_to=2000-01-01T00:00:00Z",
+		nil,
+	)
+
+	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
+	ctx.Request = r
+
+	engine.Handle("GET", "/v1/client", s.clientCTRL.ListClients)
+	engine.HandleContext(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+
+	var response client.ListClientsResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+
+	s.Len(response.Items, 1)
+	s.Equal(matchingUUID.String(), response.Items[0].ID)
+	s.Empty(response.NextCursor)
+}
+
+func (s *ClientControllerTestSuite) Test_ListClients_Fail_InvalidCursor() {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+
+	r, _ := http.NewRequest(http.MethodGet, "/v1/client?cursor=not-a-cursor", nil)
+
+	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
+	ctx.Request = r
+
+	engine.Handle("GET", "/v1/client", s.clientCTRL.ListClients)
+	engine.HandleContext(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *ClientControllerTestSuite) deleteClient(clientUUID uuid.UUID) {
+	r, cancel, err := s.pgConn.Query(
+		context.Background(),
+		"TestSoftDeleteClient",
+		"UPDATE client SET deleted_at = NOW() WHERE uuid = @uuid AND deleted_at IS NULL;",
+		pgx.NamedArgs{"uuid": clientUUID.String()},
+	)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	defer cancel()
+
+	if err := (*r).Err(); err != nil {
+		s.T().Fatal(err)
+	}
+}
+
+func (s *ClientControllerTestSuite) Test_GetClient_FailClientDeleted() {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	clientUUID := uuid.New()
+
+	s.insertClient(clientUUID, "myman@myman.cz", "MyMan", "2020-01-01T12:12:34+00:00")
+	s.deleteClient(clientUUID)
+
+	r, _ := http.NewRequest(http.MethodGet, "/v1/client/"+clientUUID.String(), nil)
+
+	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
+	ctx.Request = r
+	ctx.AddParam("id", clientUUID.String())
+
+	engine.Handle("GET", "/v1/client/:id", s.clientCTRL.GetClient)
+	engine.HandleContext(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *ClientControllerTestSuite) Test_CreateClient_ReuseEmailOfDeletedClient() {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	deletedClientUUID := uuid.New()
+	clientUUID := uuid.New()
+
+	const clientEmail = "myman@myman.cz"
+
+	s.insertClient(deletedClientUUID, clientEmail, "MyMan", "2020-01-01T12:12:34+00:00")
+	s.deleteClient(deletedClientUUID)
+
+	body, err := json.Marshal(map[string]string{
+		"email":         clientEmail,
+		"name":          "MyMan",
+		"date_of_birth": "2020-01-01T12:12:34+00:00",
+		"id":            clientUUID.String(),
+	})
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	r, _ := http.NewRequest(http.MethodPost, "/v1/client", bytes.NewBuffer(body))
+	r.Header.Set("Content-Type", "application/json")
+
+	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
+	ctx.Request = r
+
+	engine.Handle("POST", "/v1/client", s.clientCTRL.CreateClient)
+	engine.HandleContext(ctx)
+
+	s.T().Cleanup(func() {
+		r, cancel, err := s.pgConn.Query(
+			context.Background(),
+			"TestDeleteClient",
+			"DELETE FROM client WHERE uuid = @clientUUID",
+			pgx.NamedArgs{"clientUUID": clientUUID.String()},
+		)
+		if err != nil {
+			s.T().Fatal(err)
+		}
+		defer cancel()
+
+		if err := (*r).Err(); err != nil {
+			s.T().Fatal(err)
+		}
+	})
+
+	s.Equal(http.StatusCreated, w.Code)
+}
+
+func (s *ClientControllerTestSuite) Test_RestoreClient_Success() {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	clientUUID := uuid.New()
+
+	s.insertClient(clientUUID, "myman@myman.cz", "MyMan", "2020-01-01T12:12:34+00:00")
+	s.deleteClient(clientUUID)
+
+	r, _ := http.NewRequest(http.MethodPost, "/v1/client/"+clientUUID.String()+"/restore", nil)
+
+	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
+	ctx.Request = r
+	ctx.AddParam("id", clientUUID.String())
+
+	engine.Handle("POST", "/v1/client/:id/restore", s.clientCTRL.RestoreClient)
+	engine.HandleContext(ctx)
+
+	s.Equal(http.StatusNoContent, w.Code)
+
+	clientRow, err := s.selectClient(clientUUID)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	s.Nil(clientRow.DeletedAt)
+}
+
+func (s *ClientControllerTestSuite) Test_RestoreClient_FailClientAlreadyExist() {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	deletedClientUUID := uuid.New()
+
+	const clientEmail = "myman@myman.cz"
+
+	s.insertClient(deletedClientUUID, clientEmail, "MyMan", "2020-01-01T12:12:34+00:00")
+	s.deleteClient(deletedClientUUID)
+	s.insertClient(uuid.New(), clientEmail, "MyMan", "2020-01-01T12:12:34+00:00")
+
+	r, _ := http.NewRequest(http.MethodPost, "/v1/client/"+deletedClientUUID.String()+"/restore", nil)
+
+	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
+	ctx.Request = r
+	ctx.AddParam("id", deletedClientUUID.String())
+
+	engine.Handle("POST", "/v1/client/:id/restore", s.clientCTRL.RestoreClient)
+	engine.HandleContext(ctx)
+
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+}
+
+func (s *ClientControllerTestSuite) Test_PurgeClient_Success() {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	clientUUID := uuid.New()
+
+	s.insertClient(clientUUID, "myman@myman.cz", "MyMan", "2020-01-01T12:12:34+00:00")
+	s.deleteClient(clientUUID)
+
+	r, _ := http.NewRequest(http.MethodDelete, "/v1/client/"+clientUUID.String()+"/purge", nil)
+
+	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
+	ctx.Request = r
+	ctx.AddParam("id", clientUUID.String())
+
+	engine.Handle("DELETE", "/v1/client/:id/purge", s.clientCTRL.PurgeClient)
+	engine.HandleContext(ctx)
+
+	s.Equal(http.StatusNoContent, w.Code)
+
+	_, err := s.selectClient(clientUUID)
+	s.ErrorIs(err, pgx.ErrNoRows)
+}
+
+func (s *ClientControllerTestSuite) Test_PurgeClient_FailClientNotDeleted() {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	clientUUID := uuid.New()
+
+	s.insertClient(clientUUID, "myman@myman.cz", "MyMan", "2020-01-01T12:12:34+00:00")
+
+	r, _ := http.NewRequest(http.MethodDelete, "/v1/client/"+clientUUID.String()+"/purge", nil)
+
+	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
+	ctx.Request = r
+	ctx.AddParam("id", clientUUID.String())
+
+	engine.Handle("DELETE", "/v1/client/:id/purge", s.clientCTRL.PurgeClient)
+	engine.HandleContext(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *ClientControllerTestSuite) createClientIdempotently(idempotencyKey string, body []byte) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+
+	r, _ := http.NewRequest(http.MethodPost, "/v1/client", bytes.NewBuffer(body))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set(pkgGin.IdempotencyKeyHeader, idempotencyKey)
+
+	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
+	ctx.Request = r
+
+	engine.Handle("POST", "/v1/client", s.idempotency, pkghttp.ErrorMiddleware(s.lg), s.clientCTRL.CreateClient)
+	engine.HandleContext(ctx)
+
+	return w
+}
+
+func (s *ClientControllerTestSuite) cleanupIdempotencyKey(idempotencyKey string) {
+	s.T().Cleanup(func() {
+		r, cancel, err := s.pgConn.Query(
+			context.Background(),
+			"TestDeleteIdempotencyKey",
+			"DELETE FROM idempotency_key WHERE key = @key",
+			pgx.NamedArgs{"key": idempotencyKey},
+		)
+		if err != nil {
+			s.T().Fatal(err)
+		}
+		defer cancel()
+
+		if err := (*r).Err(); err != nil {
+			s.T().Fatal(err)
+		}
+	})
+}
+
+func (s *ClientControllerTestSuite) Test_CreateClient_IdempotentRetry() {
+	gin.SetMode(gin.TestMode)
+	clientUUID := uuid.New()
+	idempotencyKey := uuid.NewString()
+	s.cleanupIdempotencyKey(idempotencyKey)
+
+	body, err := json.Marshal(map[string]string{
+		"email":         "myman@myman.cz",
+		"name":          "MyMan",
+		"date_of_birth": "2020-01-01T12:12:34+00:00",
+		"id":            clientUUID.String(),
+	})
+	if err != nil {
+		s.T().Fatal(err)
+	}
+
+	first := s.createClientIdempotently(idempotencyKey, body)
+	s.T().Cleanup(func() {
+		r, cancel, err := s.pgConn.Query(
+			context.Background(),
+			"TestDeleteClient",
+			"DELETE FROM client WHERE uuid = @clientUUID",
+			pgx.NamedArgs{"clientUUID": clientUUID.String()},
+		)
+		if err != nil {
+			s.T().Fatal(err)
+		}
+		defer cancel()
+
+		if err := (*r).Err(); err != nil {
+			s.T().Fatal(err)
+		}
+	})
+	second := s.createClientIdempotently(idempotencyKey, body)
+
+	s.Equal(http.StatusCreated, first.Code)
+	s.Equal(http.StatusCreated, second.Code)
+	s.Equal("true", second.Header().Get(pkgGin.IdempotentReplayedHeader))
+}
+
+func (s *ClientControllerTestSuite) Test_CreateClient_IdempotencyKeyReusedWithDifferentBody() {
+	gin.SetMode(gin.TestMode)
+	clientUUID := uuid.New()
+	idempotencyKey := uuid.NewString()
+	s.cleanupIdempotencyKey(idempotencyKey)
+
+	s.insertClient(clientUUID, "myman@myman.cz", "MyMan", "2020-01-01T12:12:34+00:00")
+
+	body, err := json.Marshal(map[string]string{
+		"email":         "myman@myman.cz",
+		"name":          "MyMan",
+		"date_of_birth": "2020-01-01T12:12:34+00:00",
+		"id":            clientUUID.String(),
+	})
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	otherBody, err := json.Marshal(map[string]string{
+		"email":         "myotherman@myman.cz",
+		"name":          "MyOtherMan",
+		"date_of_birth": "2020-01-01T12:12:34+00:00",
+		"id":            uuid.NewString(),
+	})
+	if err != nil {
+		s.T().Fatal(err)
+	}
+
+	first := s.createClientIdempotently(idempotencyKey, body)
+	second := s.createClientIdempotently(idempotencyKey, otherBody)
+
+	s.Equal(http.StatusUnprocessableEntity, first.Code)
+	s.Equal(http.StatusConflict, second.Code)
+}
+
+func (s *ClientControllerTestSuite) Test_GetClient_ETag() {
+	gin.SetMode(gin.TestMode)
+	clientUUID := uuid.New()
+
+	s.insertClient(clientUUID, "myman@myman.cz", "MyMan", "2020-01-01T12:12:34+00:00")
+	clientRow, err := s.selectClient(clientUUID)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	etag := fmt.Sprintf(`"%d"`, clientRow.Version)
+
+	for _, tc := range []struct {
+		ifNoneMatch  string
+		expectedCode int
+	}{
+		{"", http.StatusOK},
+		{`"0"`, http.StatusOK},
+		{etag, http.StatusNotModified},
+		{`"0", W/` + etag, http.StatusNotModified},
+		{"*", http.StatusNotModified},
+	} {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/v1/client/"+clientUUID.String(), nil)
+		if tc.ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", tc.ifNoneMatch)
+		}
+
+		ctx, engine := gin.CreateTestContext(w)
+		engine.Use(pkghttp.ErrorMiddleware(s.lg))
+		ctx.Request = r
+		ctx.AddParam("id", clientUUID.String())
+
+		engine.Handle("GET", "/v1/client/:id", s.clientCTRL.GetClient)
+		engine.HandleContext(ctx)
+
+		s.Equal(tc.expectedCode, w.Code, tc.ifNoneMatch)
+		s.Equal(etag, w.Header().Get("ETag"))
+	}
+}
+
+func (s *ClientControllerTestSuite) Test_UpdateClient_IfMatch() {
+	gin.SetMode(gin.TestMode)
+	clientUUID := uuid.New()
+
+	s.insertClient(clientUUID, "myman@myman.cz", "MyMan", "2020-01-01T12:12:34+00:00")
+	clientRow, err := s.selectClient(clientUUID)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	etag := fmt.Sprintf(`"%d"`, clientRow.Version)
+
+	body, err := json.Marshal(map[string]string{
+		"email":         "myman@myman.cz",
+		"name":          "MyMan Updated",
+		"date_of_birth": "2020-01-01T12:12:34+00:00",
+	})
+	if err != nil {
+		s.T().Fatal(err)
+	}
+
+	update := func(ifMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPut, "/v1/client/"+clientUUID.String(), bytes.NewBuffer(body))
+		r.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			r.Header.Set("If-Match", ifMatch)
+		}
+
+		ctx, engine := gin.CreateTestContext(w)
+		engine.Use(pkghttp.ErrorMiddleware(s.lg))
+		ctx.Request = r
+		ctx.AddParam("id", clientUUID.String())
+
+		engine.Handle("PUT", "/v1/client/:id", s.clientCTRL.UpdateClient)
+		engine.HandleContext(ctx)
+
+		return w
+	}
+
+	s.Equal(http.StatusPreconditionRequired, update("").Code)
+	s.Equal(http.StatusPreconditionFailed, update(`W/`+etag).Code)
+
+	w := update(etag)
+	s.Equal(http.StatusNoContent, w.Code)
+	s.NotEqual(etag, w.Header().Get("ETag"))
+
+	// the first editor's tag is stale now
+	s.Equal(http.StatusPreconditionFailed, update(etag).Code)
+	s.Equal(http.StatusNoContent, update(w.Header().Get("ETag")).Code)
+}
+
+func (s *ClientControllerTestSuite) Test_DeleteClient_FailPreconditionFailed() {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	clientUUID := uuid.New()
+
+	s.insertClient(clientUUID, "myman@myman.cz", "MyMan", "2020-01-01T12:12:34+00:00")
+
+	r, _ := http.NewRequest(http.MethodDelete, "/v1/client/"+clientUUID.String(), nil)
+	r.Header.Set("If-Match", `"0"`)
+
+	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
+	ctx.Request = r
+	ctx.AddParam("id", clientUUID.String())
+
+	engine.Handle("DELETE", "/v1/client/:id", s.clientCTRL.DeleteClient)
+	engine.HandleContext(ctx)
+
+	s.Equal(http.StatusPreconditionFailed, w.Code)
+
+	clientRow, err := s.selectClient(clientUUID)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	s.Nil(clientRow.DeletedAt)
+}
+
+func (s *ClientControllerTestSuite) Test_ClientHistory_Success() {
+	gin.SetMode(gin.TestMode)
+	clientUUID := uuid.New()
+	s.T().Cleanup(func() {
+		r, cancel, err := s.pgConn.Query(
+			context.Background(),
+			"TestDeleteClientAudit",
+			"DELETE FROM client_audit WHERE client_uuid = @clientUUID",
+			pgx.NamedArgs{"clientUUID": clientUUID.String()},
+		)
+		if err != nil {
+			s.T().Fatal(err)
+		}
+		defer cancel()
+
+		if err := (*r).Err(); err != nil {
+			s.T().Fatal(err)
+		}
+	})
+
+	s.insertClient(clientUUID, "myman@myman.cz", "MyMan", "2020-01-01T12:12:34+00:00")
+
+	body, err := json.Marshal(map[string]string{
+		"email":         "myman.updated@myman.cz",
+		"name":          "MyMan",
+		"date_of_birth": "2020-01-01T12:12:34+00:00",
+	})
+	if err != nil {
+		s.T().Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest(http.MethodPut, "/v1/client/"+clientUUID.String(), bytes.NewBuffer(body))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("If-Match", "*")
+	r.Header.Set("X-Actor", "john.doe@whalebone.io")
+	r.Header.Set("X-Request-ID", "request-1")
+
+	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
+	ctx.Request = r
+	ctx.AddParam("id", clientUUID.String())
+
+	engine.Handle("PUT", "/v1/client/:id", s.clientCTRL.UpdateClient)
+	engine.HandleContext(ctx)
+
+	s.Equal(http.StatusNoContent, w.Code)
+
+	history := func(cursor string) client.ClientHistoryResponse {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/v1/client/"+clientUUID.String()+"/history?limit=1&cursor="+cursor, nil)
+
+		ctx, engine := gin.CreateTestContext(w)
+		engine.Use(pkghttp.ErrorMiddleware(s.lg))
+		ctx.Request = r
+		ctx.AddParam("id", clientUUID.String())
+
+		engine.Handle("GET", "/v1/client/:id/history", s.clientCTRL.ClientHistory)
+		engine.HandleContext(ctx)
+
+		s.Equal(http.StatusOK, w.Code)
+
+		var res client.ClientHistoryResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			s.T().Fatal(err)
+		}
+
+		return res
+	}
+
+	first := history("")
+	s.Len(first.Items, 1)
+	s.Equal("create", first.Items[0].Action)
+	s.Nil(first.Items[0].Before)
+	s.NotEmpty(first.NextCursor)
+
+	second := history(first.NextCursor)
+	s.Len(second.Items, 1)
+	s.Equal("update", second.Items[0].Action)
+	s.Equal("john.doe@whalebone.io", second.Items[0].Actor)
+	s.Equal("request-1", second.Items[0].RequestID)
+	s.Contains(string(second.Items[0].Before), `"email":"myman@myman.cz"`)
+	s.Contains(string(second.Items[0].After), `"email":"myman.updated@myman.cz"`)
+	s.Empty(second.NextCursor)
+}
+
+func (s *ClientControllerTestSuite) Test_ClientHistory_FailClientNotFound() {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	clientUUID := uuid.New()
+
+	r, _ := http.NewRequest(http.MethodGet, "/v1/client/"+clientUUID.String()+"/history", nil)
+
+	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
+	ctx.Request = r
+	ctx.AddParam("id", clientUUID.String())
+
+	engine.Handle("GET", "/v1/client/:id/history", s.clientCTRL.ClientHistory)
+	engine.HandleContext(ctx)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+
+	s.Equal(http.StatusNotFound, w.Code)
+	s.Equal("client_not_found", response["code"])
+}
+
+func (s *ClientControllerTestSuite) Test_DeleteClient_WritesOutboxMessage() {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	clientUUID := uuid.New()
+	s.T().Cleanup(func() {
+		r, cancel, err := s.pgConn.Query(
+			context.Background(),
+			"TestDeleteOutboxMessages",
+			"DELETE FROM outbox WHERE aggregate_id = @aggregateID",
+			pgx.NamedArgs{"aggregateID": clientUUID.String()},
+		)
+		if err != nil {
+			s.T().Fatal(err)
+		}
+		defer cancel()
+
+		if err := (*r).Err(); err != nil {
+			s.T().Fatal(err)
+		}
+	})
+
+	s.insertClient(clientUUID, "myman@myman.cz", "MyMan", "2020-01-01T12:12:34+00:00")
+
+	r, _ := http.NewRequest(http.MethodDelete, "/v1/client/"+clientUUID.String(), nil)
+	r.Header.Set("If-Match", "*")
+
+	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
+	ctx.Request = r
+	ctx.AddParam("id", clientUUID.String())
+
+	engine.Handle("DELETE", "/v1/client/:id", s.clientCTRL.DeleteClient)
+	engine.HandleContext(ctx)
+
+	s.Equal(http.StatusNoContent, w.Code)
+
+	row, cancel := s.pgConn.QueryRow(
+		context.Background(),
+		"TestGetOutboxMessage",
+		"SELECT event_type, payload->>'id' FROM outbox WHERE aggregate_id = @aggregateID ORDER BY id DESC LIMIT 1;",
+		pgx.NamedArgs{"aggregateID": clientUUID.String()},
+	)
+	defer cancel()
+
+	var eventType, payloadID string
+	err := (*row).Scan(
+		&eventType,
+		&payloadID,
+	)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+
+	s.Equal(handler.ClientDeletedEvent, eventType)
+	s.Equal(clientUUID.String(), payloadID)
+}
+
+func (s *ClientControllerTestSuite) cleanupClientsByEmail(emails ...string) {
+	s.T().Cleanup(func() {
+		r, cancel, err := s.pgConn.Query(
+			context.Background(),
+			"TestDeleteClients",
+			"DELETE FROM client WHERE email = ANY(@emails)",
+			pgx.NamedArgs{"emails": emails},
+		)
+		if err != nil {
+			s.T().Fatal(err)
+		}
+		defer cancel()
+
+		if err := (*r).Err(); err != nil {
+			s.T().Fatal(err)
+		}
+	})
+}
+
+func (s *ClientControllerTestSuite) importClients(contentType string, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+
+	r, _ := http.NewRequest(http.MethodPost, "/v1/client/import", bytes.NewBufferString(body))
+	r.Header.Set("Content-Type", contentType)
+
+	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
+	ctx.Request = r
+
+	engine.Handle("POST", "/v1/client/import", s.clientCTRL.ImportClients)
+	engine.HandleContext(ctx)
+
+	return w
+}
+
+func (s *ClientControllerTestSuite) Test_ImportClients_CSV() {
+	existingUUID := uuid.New()
+	importedUUID := uuid.New()
+	s.insertClient(existingUUID, "existing@myman.cz", "Existing", "2020-01-01T12:12:34+00:00")
+	s.cleanupClientsByEmail("imported@myman.cz")
+
+	body := "id,email,name,date_of_birth\n" +
+		importedUUID.String() + ",imported@myman.cz,Imported,2020-01-01T12:12:34+00:00\n" +
+		uuid.NewString() + ",existing@myman.cz,Existing,2020-01-01T12:12:34+00:00\n" +
+		uuid.NewString() + ",imported@myman.cz,Repeated,2020-01-01T12:12:34+00:00\n" +
+		uuid.NewString() + ",invalid,Invalid,2020-01-01T12:12:34+00:00\n"
+
+	w := s.importClients("text/csv", body)
+	s.Equal(http.StatusOK, w.Code)
+
+	var report client.ClientImportReportResponse
+	err := json.Unmarshal(w.Body.Bytes(), &report)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+
+	s.Equal(4, report.Total)
+	s.Equal(1, report.Accepted)
+	s.Equal(2, report.Duplicate)
+	s.Equal(1, report.Invalid)
+	s.Equal([]string{
+		handler.ClientImportRowAccepted,
+		handler.ClientImportRowDuplicate,
+		handler.ClientImportRowDuplicate,
+		handler.ClientImportRowInvalid,
+	}, []string{report.Rows[0].Status, report.Rows[1].Status, report.Rows[2].Status, report.Rows[3].Status})
+	s.Equal("invalid email", report.Rows[3].Error)
+
+	clientRow, err := s.selectClient(importedUUID)
+	s.NoError(err)
+	s.Equal("Imported", clientRow.Name)
+}
+
+func (s *ClientControllerTestSuite) Test_ImportClients_NDJSON() {
+	importedUUID := uuid.New()
+	s.cleanupClientsByEmail("ndjson@myman.cz")
+
+	body := `{"id":"` + importedUUID.String() + `","email":"ndjson@myman.cz","name":"NDJSON","date_of_birth":"2020-01-01T12:12:34+00:00"}` + "\n" +
+		"\n" +
+		`{"id":"` + uuid.NewString() + `","email":"missing-name@myman.cz","date_of_birth":"2020-01-01T12:12:34+00:00"}` + "\n" +
+		`not json` + "\n"
+
+	w := s.importClients("application/x-ndjson", body)
+	s.Equal(http.StatusOK, w.Code)
+
+	var report client.ClientImportReportResponse
+	err := json.Unmarshal(w.Body.Bytes(), &report)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+
+	s.Equal(3, report.Total)
+	s.Equal(1, report.Accepted)
+	s.Equal(2, report.Invalid)
+	s.Equal(importedUUID.String(), report.Rows[0].ID)
+
+	_, err = s.selectClient(importedUUID)
+	s.NoError(err)
+}
+
+func (s *ClientControllerTestSuite) Test_ImportClients_ScheduledForLargeImport() {
+	var body strings.Builder
+	body.WriteString("id,email,name,date_of_birth\n")
+	for i := 0; i <= 1000; i++ {
+		body.WriteString(uuid.NewString() + ",invalid,Invalid,2020-01-01T12:12:34+00:00\n")
+	}
+
+	w := s.importClients("text/csv", body.String())
+	s.Equal(http.StatusAccepted, w.Code)
+
+	var scheduled client.ClientImportResponse
+	err := json.Unmarshal(w.Body.Bytes(), &scheduled)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	s.T().Cleanup(func() {
+		r, cancel, err := s.pgConn.Query(
+			context.Background(),
+			"TestDeleteClientImport",
+			"DELETE FROM client_import WHERE uuid = @uuid",
+			pgx.NamedArgs{"uuid": scheduled.ID},
+		)
+		if err != nil {
+			s.T().Fatal(err)
+		}
+		defer cancel()
+
+		if err := (*r).Err(); err != nil {
+			s.T().Fatal(err)
+		}
+	})
+
+	s.Equal(handler.ClientImportPending, scheduled.Status)
+	s.Equal(1001, scheduled.TotalRows)
+	s.Equal("/v1/client/import/"+scheduled.ID, w.Header().Get("Location"))
+
+	w = httptest.NewRecorder()
+	r, _ := http.NewRequest(http.MethodGet, "/v1/client/import/"+scheduled.ID, nil)
+	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
+	ctx.Request = r
+	ctx.AddParam("id", scheduled.ID)
+
+	engine.Handle("GET", "/v1/client/import/:id", s.clientCTRL.GetClientImport)
+	engine.HandleContext(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+}
+
+func (s *ClientControllerTestSuite) Test_ImportClients_Fail_UnsupportedMediaType() {
+	w := s.importClients("application/json", "[]")
+	s.Equal(http.StatusUnsupportedMediaType, w.Code)
+}
+
+func (s *ClientControllerTestSuite) exportClients(query string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+
+	r, _ := http.NewRequest(http.MethodGet, "/v1/client/export?"+query, nil)
+
+	ctx, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
+	ctx.Request = r
+
+	engine.Handle("GET", "/v1/client/export", s.clientCTRL.ExportClients)
+	engine.HandleContext(ctx)
+
+	return w
+}
+
+func (s *ClientControllerTestSuite) Test_ExportClients_Formats() {
+	firstUUID := uuid.New()
+	secondUUID := uuid.New()
+	s.insertClient(firstUUID, "first@export.test", "First", "2020-01-01T12:12:34+00:00")
+	s.insertClient(secondUUID, "second@export.test", "Second", "2020-01-01T12:12:34+00:00")
+	s.insertClient(uuid.New(), "other@myman.cz", "Other", "2020-01-01T12:12:34+00:00")
+
+	w := s.exportClients("format=csv&email_domain=export.test")
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("text/csv", w.Header().Get("Content-Type"))
+	s.Equal("id,email,name,date_of_birth\n"+
+		firstUUID.String()+",first@export.test,First,2020-01-01T12:12:34+00:00\n"+
+		secondUUID.String()+",second@export.test,Second,2020-01-01T12:12:34+00:00\n", w.Body.String())
+
+	w = s.exportClients("format=ndjson&email_domain=export.test")
+	s.Equal(http.StatusOK, w.Code)
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	s.Len(lines, 2)
+	var first client.GetClientResponse
+	err := json.Unmarshal([]byte(lines[0]), &first)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	s.Equal(firstUUID.String(), first.ID)
+
+	w = s.exportClients("format=json&email_domain=export.test&name_prefix=Sec")
+	s.Equal(http.StatusOK, w.Code)
+	var clients []client.GetClientResponse
+	err = json.Unmarshal(w.Body.Bytes(), &clients)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	s.Len(clients, 1)
+	s.Equal(secondUUID.String(), clients[0].ID)
+
+	w = s.exportClients("format=json&email_domain=nobody.test")
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("[]", w.Body.String())
+}
+
+func (s *ClientControllerTestSuite) Test_ExportClients_Fail_InvalidFormat() {
+	w := s.exportClients("format=parquet")
+	s.Equal(http.StatusBadRequest, w.Code)
 }
 
 func TestClientControllerSuite(t *testing.T) {
@@ -0,0 +1,154 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/app/swagger"
+	apperror "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/error"
+	pkghttp "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/ui/http"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/test/helper"
+	pkgGin "github.com/jamm3e3333/whalebone-go-test-project/pkg/net/http/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type ErrorMiddlewareTestSuite struct {
+	suite.Suite
+}
+
+func (s *ErrorMiddlewareTestSuite) serve(path string, h gin.HandlerFunc) (*httptest.ResponseRecorder, pkghttp.Problem) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest(http.MethodGet, path, nil)
+	r.Header.Set(pkgGin.RequestIDHeader, "8e03978e-40d5-43e8-bc93-6894a57f9324")
+
+	_, engine := gin.CreateTestContext(w)
+	engine.Use(pkghttp.ErrorMiddleware(helper.NewBlankLogger()))
+	engine.NoRoute(pkghttp.NoRoute)
+	engine.GET("/resource", h)
+	engine.ServeHTTP(w, r)
+
+	var problem pkghttp.Problem
+	if w.Header().Get("Content-Type") == pkgGin.ProblemContentType {
+		err := json.Unmarshal(w.Body.Bytes(), &problem)
+		if err != nil {
+			s.T().Fatal(err)
+		}
+	}
+
+	return w, problem
+}
+
+func (s *ErrorMiddlewareTestSuite) Test_ApplicationError() {
+	w, problem := s.serve("/resource", func(ctx *gin.Context) {
+		pkghttp.AbortWithError(ctx, apperror.NewClientNotFound())
+	})
+
+	s.Equal(http.StatusNotFound, w.Code)
+	s.Equal(pkgGin.ProblemContentType, w.Header().Get("Content-Type"))
+	s.Equal(pkgGin.Problem{
+		Type:      "urn:problem-type:client_not_found",
+		Title:     "Client not found",
+		Status:    http.StatusNotFound,
+		Detail:    "client not found",
+		Instance:  "/resource",
+		Code:      apperror.ClientNotFoundCode,
+		RequestID: "8e03978e-40d5-43e8-bc93-6894a57f9324",
+	}, problem.Problem)
+	s.Empty(problem.Violations)
+}
+
+func (s *ErrorMiddlewareTestSuite) Test_ValidationError() {
+	w, problem := s.serve("/resource", func(ctx *gin.Context) {
+		v := pkghttp.NewValidationError()
+		v.Add("name", pkghttp.ViolationRequired, "name is required")
+		v.Add("email", pkghttp.ViolationInvalidFormat, "invalid email")
+		pkghttp.AbortWithError(ctx, v)
+	})
+
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+	s.Equal(pkghttp.ValidationFailedCode, problem.Code)
+	s.Equal("name is required; invalid email", problem.Detail)
+	s.Equal([]pkghttp.FieldViolation{
+		{Field: "name", Code: pkghttp.ViolationRequired, Message: "name is required"},
+		{Field: "email", Code: pkghttp.ViolationInvalidFormat, Message: "invalid email"},
+	}, problem.Violations)
+}
+
+func (s *ErrorMiddlewareTestSuite) Test_UnknownErrorIsHidden() {
+	w, problem := s.serve("/resource", func(ctx *gin.Context) {
+		pkghttp.AbortWithError(ctx, errors.New("connection refused"))
+	})
+
+	s.Equal(http.StatusInternalServerError, w.Code)
+	s.Equal(pkghttp.InternalServerErrorCode, problem.Code)
+	s.Equal("internal server error", problem.Detail)
+	s.NotContains(w.Body.String(), "connection refused")
+}
+
+func (s *ErrorMiddlewareTestSuite) Test_WrittenResponseIsKept() {
+	w, _ := s.serve("/resource", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "partial")
+		_ = ctx.Error(errors.New("stream failed"))
+	})
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("partial", w.Body.String())
+}
+
+func (s *ErrorMiddlewareTestSuite) Test_NoRoute() {
+	w, problem := s.serve("/unknown", nil)
+
+	s.Equal(http.StatusNotFound, w.Code)
+	s.Equal(pkghttp.NotFoundCode, problem.Code)
+	s.Equal("/unknown", problem.Instance)
+}
+
+func (s *ErrorMiddlewareTestSuite) Test_CatalogCodesAreUnique() {
+	codes := map[string]bool{}
+	for _, t := range pkghttp.ProblemTypes {
+		s.False(codes[t.Code], t.Code)
+		codes[t.Code] = true
+		s.NotEmpty(t.Title)
+		s.GreaterOrEqual(t.Status, http.StatusBadRequest)
+	}
+}
+
+// Test_CatalogIsPublishedInDocs keeps the error table of the API description in cmd/main.go in sync with the catalog,
+// the docs have to be generated again after the table is changed
+func (s *ErrorMiddlewareTestSuite) Test_CatalogIsPublishedInDocs() {
+	swaggerJSON, err := os.ReadFile("../../../../app/swagger/swagger.json")
+	s.Require().NoError(err)
+	var spec struct {
+		Info struct {
+			Description string `json:"description"`
+		} `json:"info"`
+	}
+	s.Require().NoError(json.Unmarshal(swaggerJSON, &spec))
+
+	for _, description := range []string{swagger.SwaggerInfo.Description, spec.Info.Description} {
+		var rows []string
+		for _, line := range strings.Split(description, "\n") {
+			if strings.HasPrefix(line, "| ") && !strings.HasPrefix(line, "| Code ") && !strings.HasPrefix(line, "| --- ") {
+				rows = append(rows, line)
+			}
+		}
+
+		expected := make([]string, 0, len(pkghttp.ProblemTypes))
+		for _, t := range pkghttp.ProblemTypes {
+			expected = append(expected, fmt.Sprintf("| %s | %d | %s |", t.Code, t.Status, t.Title))
+		}
+		s.Equal(expected, rows)
+	}
+}
+
+func TestErrorMiddlewareSuite(t *testing.T) {
+	suite.Run(t, new(ErrorMiddlewareTestSuite))
+}
@@ -0,0 +1,117 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/infrastructure/memory"
+	pkghttp "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/ui/http"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/ui/http/v1/client"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
+	pkgGin "github.com/jamm3e3333/whalebone-go-test-project/pkg/net/http/gin"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// TracingTestSuite serves the client endpoints against the in-memory repository with the tracing of cmd/main.go
+type TracingTestSuite struct {
+	suite.Suite
+
+	exporter *tracetest.InMemoryExporter
+	logs     *bytes.Buffer
+	engine   *gin.Engine
+}
+
+func (s *TracingTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	s.exporter = tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(s.exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	s.logs = &bytes.Buffer{}
+	lg := logger.New(logger.InfoLevel, false, s.logs)
+
+	clients := memory.NewClientRepository()
+	clientCTRL := client.NewController(client.Handlers{
+		CreateClient: handler.NewCreateClientHandler(clients),
+		GetClient:    handler.NewGetClientHandler(clients),
+	})
+
+	s.engine = gin.New()
+	s.engine.ContextWithFallback = true
+	s.engine.Use(
+		otelgin.Middleware("whalebone_clients"),
+		pkgGin.CorrelationMiddleware(),
+		pkgGin.LoggerMiddleware(pkgGin.NewLoggerMiddlewareConfig(nil), lg),
+		pkghttp.ErrorMiddleware(lg),
+	)
+	s.engine.GET("/v1/client/:id", clientCTRL.GetClient)
+}
+
+func (s *TracingTestSuite) TearDownTest() {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+}
+
+func (s *TracingTestSuite) spansByName() map[string]tracetest.SpanStub {
+	spans := map[string]tracetest.SpanStub{}
+	for _, span := range s.exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+
+	return spans
+}
+
+func (s *TracingTestSuite) Test_HandlerSpanIsChildOfRequestSpan() {
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest(http.MethodGet, "/v1/client/"+uuid.NewString(), nil)
+	r.Header.Set(pkgGin.TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.Header.Set(pkgGin.RequestIDHeader, "request-1")
+	s.engine.ServeHTTP(w, r)
+
+	s.Equal(http.StatusNotFound, w.Code)
+
+	spans := s.spansByName()
+	requestSpan, ok := spans["/v1/client/:id"]
+	s.Require().True(ok)
+	handlerSpan, ok := spans["handler.GetClient"]
+	s.Require().True(ok)
+
+	s.Equal("4bf92f3577b34da6a3ce929d0e0e4736", requestSpan.SpanContext.TraceID().String())
+	s.Equal("00f067aa0ba902b7", requestSpan.Parent.SpanID().String())
+	s.Equal(requestSpan.SpanContext.SpanID(), handlerSpan.Parent.SpanID())
+	s.Equal(codes.Error, handlerSpan.Status.Code)
+
+	s.Equal("00-4bf92f3577b34da6a3ce929d0e0e4736-"+requestSpan.SpanContext.SpanID().String()+"-01", w.Header().Get(pkgGin.TraceParentHeader))
+	s.Equal("request-1", w.Header().Get(pkgGin.RequestIDHeader))
+}
+
+func (s *TracingTestSuite) Test_LogsCarryTraceIDs() {
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest(http.MethodGet, "/v1/client/"+uuid.NewString(), nil)
+	s.engine.ServeHTTP(w, r)
+
+	requestSpan := s.spansByName()["/v1/client/:id"]
+
+	var line map[string]any
+	s.Require().NoError(json.Unmarshal(s.logs.Bytes(), &line))
+	s.Equal("HTTP Request / Response", line["message"])
+	s.Equal(requestSpan.SpanContext.TraceID().String(), line["trace_id"])
+	s.Equal(requestSpan.SpanContext.SpanID().String(), line["span_id"])
+	s.Equal(w.Header().Get(pkgGin.RequestIDHeader), line["request_id"])
+}
+
+func TestTracingSuite(t *testing.T) {
+	suite.Run(t, new(TracingTestSuite))
+}
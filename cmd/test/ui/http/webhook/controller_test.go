@@ -0,0 +1,258 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/application/handler"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/infrastructure/pg/operation"
+	pkghttp "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/ui/http"
+	webhookCTRL "github.com/jamm3e3333/whalebone-go-test-project/cmd/internal/ui/http/v1/webhook"
+	"github.com/jamm3e3333/whalebone-go-test-project/cmd/test/helper"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/outbox"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/pgx"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/webhook"
+	"github.com/stretchr/testify/suite"
+)
+
+type WebhookControllerTestSuite struct {
+	suite.Suite
+
+	lg logger.Logger
+
+	webhookCTRL     *webhookCTRL.Controller
+	webhookDelivery *operation.WebhookDelivery
+	pgConn          pgx.Connection
+}
+
+func (s *WebhookControllerTestSuite) SetupSuite() {
+	s.lg = helper.NewBlankLogger()
+
+	pgCfg := helper.NewPostgresConfig()
+	pgConn, err := pgx.NewConnectionPool(context.Background(), pgx.Config{
+		ConnectionURL:     pgCfg.ConnectionURL(),
+		LogLevel:          "info",
+		MaxConnLifetime:   pgCfg.MaxConnLifetime(),
+		MaxConnIdleTime:   pgCfg.MaxConnIdleTime(),
+		QueryTimeout:      pgCfg.QueryTimeout(),
+		DefaultMaxConns:   pgCfg.DefaultMaxConns(),
+		DefaultMinConns:   pgCfg.DefaultMinConns(),
+		HealthCheckPeriod: pgCfg.HealthCheckPeriod(),
+	}, s.lg, helper.NewDummyMetrics())
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	s.pgConn = pgConn
+
+	webhookSubscription := operation.NewWebhookSubscriptionOperation(s.pgConn)
+	s.webhookDelivery = operation.NewWebhookDeliveryOperation(s.pgConn)
+
+	s.webhookCTRL = webhookCTRL.NewController(webhookCTRL.Handlers{
+		CreateWebhook:         handler.NewCreateWebhookHandler(webhookSubscription),
+		GetWebhook:            handler.NewGetWebhookHandler(webhookSubscription),
+		ListWebhooks:          handler.NewListWebhooksHandler(webhookSubscription),
+		UpdateWebhook:         handler.NewUpdateWebhookHandler(webhookSubscription),
+		DeleteWebhook:         handler.NewDeleteWebhookHandler(webhookSubscription),
+		ListWebhookDeliveries: handler.NewListWebhookDeliveriesHandler(webhookSubscription, s.webhookDelivery),
+	})
+}
+
+func (s *WebhookControllerTestSuite) TearDownSuite() {
+
+}
+
+func (s *WebhookControllerTestSuite) serve(method string, path string, body any) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			s.T().Fatal(err)
+		}
+		reqBody = bytes.NewBuffer(b)
+	}
+	r, _ := http.NewRequest(method, path, reqBody)
+	r.Header.Set("Content-Type", "application/json")
+
+	engine := gin.New()
+	engine.Use(pkghttp.ErrorMiddleware(s.lg))
+	s.webhookCTRL.Register(engine)
+	engine.ServeHTTP(w, r)
+
+	return w
+}
+
+func (s *WebhookControllerTestSuite) createWebhook(url string, eventTypes []string) webhookCTRL.CreateWebhookResponse {
+	w := s.serve(http.MethodPost, "/v1/webhooks", map[string]any{
+		"url":         url,
+		"event_types": eventTypes,
+	})
+	s.Require().Equal(http.StatusCreated, w.Code)
+
+	var res webhookCTRL.CreateWebhookResponse
+	err := json.Unmarshal(w.Body.Bytes(), &res)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	s.T().Cleanup(func() {
+		s.serve(http.MethodDelete, "/v1/webhooks/"+res.ID, nil)
+	})
+
+	return res
+}
+
+func (s *WebhookControllerTestSuite) Test_CreateWebhook_Success() {
+	created := s.createWebhook("https://partner.example.com/hook", []string{handler.ClientCreatedEvent})
+
+	s.NotEmpty(created.ID)
+	s.NotEmpty(created.Secret)
+	s.True(created.Active)
+
+	w := s.serve(http.MethodGet, "/v1/webhooks/"+created.ID, nil)
+	s.Equal(http.StatusOK, w.Code)
+
+	var res webhookCTRL.WebhookResponse
+	err := json.Unmarshal(w.Body.Bytes(), &res)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	s.Equal(created.WebhookResponse, res)
+	s.NotContains(w.Body.String(), created.Secret)
+}
+
+func (s *WebhookControllerTestSuite) Test_CreateWebhook_Invalid() {
+	w := s.serve(http.MethodPost, "/v1/webhooks", map[string]any{"url": "ftp://partner.example.com/hook"})
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+
+	w = s.serve(http.MethodPost, "/v1/webhooks", map[string]any{
+		"url":         "https://partner.example.com/hook",
+		"event_types": []string{"client.unknown"},
+	})
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+
+	w = s.serve(http.MethodPost, "/v1/webhooks", map[string]any{
+		"event_types": []string{"client.created", "client.unknown"},
+		"secret":      "short",
+	})
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+
+	var res pkghttp.Problem
+	err := json.Unmarshal(w.Body.Bytes(), &res)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	s.Equal([]string{"url", "event_types[1]", "secret"}, []string{res.Violations[0].Field, res.Violations[1].Field, res.Violations[2].Field})
+
+	w = s.serve(http.MethodPost, "/v1/webhooks", map[string]any{"url": 42})
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *WebhookControllerTestSuite) Test_UpdateAndDeleteWebhook_Success() {
+	created := s.createWebhook("https://partner.example.com/hook", nil)
+
+	w := s.serve(http.MethodPut, "/v1/webhooks/"+created.ID, map[string]any{
+		"url":         "https://partner.example.com/other",
+		"event_types": []string{handler.ClientDeletedEvent},
+		"active":      false,
+	})
+	s.Equal(http.StatusNoContent, w.Code)
+
+	w = s.serve(http.MethodGet, "/v1/webhooks/"+created.ID, nil)
+	var res webhookCTRL.WebhookResponse
+	err := json.Unmarshal(w.Body.Bytes(), &res)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	s.Equal("https://partner.example.com/other", res.URL)
+	s.Equal([]string{handler.ClientDeletedEvent}, res.EventTypes)
+	s.False(res.Active)
+
+	w = s.serve(http.MethodDelete, "/v1/webhooks/"+created.ID, nil)
+	s.Equal(http.StatusNoContent, w.Code)
+
+	w = s.serve(http.MethodGet, "/v1/webhooks/"+created.ID, nil)
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *WebhookControllerTestSuite) Test_DeliverWebhook_Success() {
+	received := make(chan *http.Request, 1)
+	var receivedBody []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedBody, _ = io.ReadAll(r.Body)
+		received <- r
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	created := s.createWebhook(receiver.URL, []string{handler.ClientCreatedEvent})
+
+	message := outbox.Message{
+		ID:          time.Now().UnixNano(),
+		AggregateID: "test-aggregate",
+		EventType:   handler.ClientCreatedEvent,
+		Payload:     json.RawMessage(`{"id":"test-aggregate"}`),
+		CreatedAt:   time.Now(),
+	}
+	err := s.webhookDelivery.Publish(context.Background(), message)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	// the second publish of the same message is ignored
+	err = s.webhookDelivery.Publish(context.Background(), message)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dispatcher := webhook.NewDispatcher(s.webhookDelivery, &http.Client{Timeout: time.Second}, webhook.DispatcherConfig{
+		Interval:    10 * time.Millisecond,
+		BatchSize:   10,
+		Lease:       time.Minute,
+		MinBackoff:  time.Second,
+		MaxBackoff:  time.Minute,
+		MaxAttempts: 3,
+	}, s.lg)
+	go dispatcher.Run(ctx)
+
+	var r *http.Request
+	select {
+	case r = <-received:
+	case <-time.After(5 * time.Second):
+		s.T().Fatal("webhook was not delivered")
+	}
+
+	s.Equal(handler.ClientCreatedEvent, r.Header.Get(webhook.EventTypeHeader))
+	s.NoError(webhook.Verify(created.Secret, r.Header.Get(webhook.SignatureHeader), receivedBody, time.Minute, time.Now()))
+
+	var deliveries webhookCTRL.ListWebhookDeliveriesResponse
+	s.Eventually(func() bool {
+		w := s.serve(http.MethodGet, "/v1/webhooks/"+created.ID+"/deliveries", nil)
+		if w.Code != http.StatusOK {
+			return false
+		}
+		err := json.Unmarshal(w.Body.Bytes(), &deliveries)
+		if err != nil {
+			return false
+		}
+
+		return len(deliveries.Items) == 1 && deliveries.Items[0].Status == handler.WebhookDeliveryDelivered
+	}, 5*time.Second, 50*time.Millisecond)
+
+	s.Equal(message.ID, deliveries.Items[0].EventID)
+	s.Equal(1, deliveries.Items[0].Attempts)
+}
+
+func TestWebhookControllerSuite(t *testing.T) {
+	suite.Run(t, new(WebhookControllerTestSuite))
+}
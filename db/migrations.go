@@ -0,0 +1,10 @@
+package db
+
+import "embed"
+
+// Migrations are the goose SQL migrations of the migrations directory embedded in the binary
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
+
+const MigrationsDir = "migrations"
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE client ADD COLUMN deleted_at TIMESTAMP NULL;
+
+ALTER TABLE client DROP CONSTRAINT client_email_key;
+
+CREATE UNIQUE INDEX idx_client_email_not_deleted ON client (email) WHERE deleted_at IS NULL;
+CREATE INDEX idx_client_deleted_at ON client (deleted_at) WHERE deleted_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DO $$
+BEGIN
+	IF EXISTS (SELECT 1 FROM client WHERE deleted_at IS NOT NULL) THEN
+		RAISE EXCEPTION 'client table has soft deleted clients, restore or purge them before migrating down';
+	END IF;
+END
+$$;
+
+DROP INDEX IF EXISTS idx_client_deleted_at;
+DROP INDEX IF EXISTS idx_client_email_not_deleted;
+
+ALTER TABLE client ADD CONSTRAINT client_email_key UNIQUE (email);
+
+ALTER TABLE client DROP COLUMN deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE SEQUENCE client_version_seq;
+
+ALTER TABLE client ADD COLUMN version BIGINT NOT NULL DEFAULT nextval('client_version_seq');
+ALTER SEQUENCE client_version_seq OWNED BY client.version;
+
+CREATE OR REPLACE FUNCTION on_update_version ()
+    RETURNS TRIGGER
+AS $$
+BEGIN
+    NEW.version = nextval(pg_get_serial_sequence(TG_TABLE_NAME, 'version'));
+    RETURN NEW;
+END;
+$$
+    LANGUAGE plpgsql;
+
+CREATE TRIGGER client_version
+    BEFORE UPDATE ON client
+    FOR EACH ROW
+EXECUTE PROCEDURE on_update_version ();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS client_version ON client;
+DROP FUNCTION IF EXISTS on_update_version ();
+ALTER TABLE client DROP COLUMN IF EXISTS version;
+-- +goose StatementEnd
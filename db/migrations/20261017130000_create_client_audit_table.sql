@@ -0,0 +1,83 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE client_audit (
+    id BIGSERIAL PRIMARY KEY,
+    client_id BIGINT NOT NULL,
+    client_uuid UUID NOT NULL,
+    action TEXT NOT NULL,
+    before JSONB NULL,
+    after JSONB NULL,
+    actor TEXT NULL,
+    request_id TEXT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_client_audit_client_uuid ON client_audit (client_uuid, id);
+
+CREATE OR REPLACE FUNCTION client_audit_snapshot (c client)
+    RETURNS JSONB
+AS $$
+BEGIN
+    RETURN jsonb_build_object(
+        'id', c.uuid,
+        'name', c.name,
+        'email', c.email,
+        'date_of_birth', c.date_of_birth,
+        'deleted_at', c.deleted_at
+    );
+END;
+$$
+    LANGUAGE plpgsql;
+
+-- actor and request id are set by the application with set_config for the current transaction
+CREATE OR REPLACE FUNCTION on_client_change_audit ()
+    RETURNS TRIGGER
+AS $$
+DECLARE
+    audit_action TEXT;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        audit_action = 'create';
+    ELSIF TG_OP = 'DELETE' THEN
+        audit_action = 'purge';
+    ELSIF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+        audit_action = 'delete';
+    ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+        audit_action = 'restore';
+    ELSE
+        audit_action = 'update';
+    END IF;
+
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO client_audit (client_id, client_uuid, action, after, actor, request_id)
+            VALUES (NEW.id, NEW.uuid, audit_action, client_audit_snapshot(NEW),
+                NULLIF(current_setting('audit.actor', TRUE), ''), NULLIF(current_setting('audit.request_id', TRUE), ''));
+        RETURN NEW;
+    ELSIF TG_OP = 'DELETE' THEN
+        INSERT INTO client_audit (client_id, client_uuid, action, before, actor, request_id)
+            VALUES (OLD.id, OLD.uuid, audit_action, client_audit_snapshot(OLD),
+                NULLIF(current_setting('audit.actor', TRUE), ''), NULLIF(current_setting('audit.request_id', TRUE), ''));
+        RETURN OLD;
+    END IF;
+
+    INSERT INTO client_audit (client_id, client_uuid, action, before, after, actor, request_id)
+        VALUES (NEW.id, NEW.uuid, audit_action, client_audit_snapshot(OLD), client_audit_snapshot(NEW),
+            NULLIF(current_setting('audit.actor', TRUE), ''), NULLIF(current_setting('audit.request_id', TRUE), ''));
+    RETURN NEW;
+END;
+$$
+    LANGUAGE plpgsql;
+
+CREATE TRIGGER client_audit
+    AFTER INSERT OR UPDATE OR DELETE ON client
+    FOR EACH ROW
+EXECUTE PROCEDURE on_client_change_audit ();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS client_audit ON client;
+DROP FUNCTION IF EXISTS on_client_change_audit ();
+DROP FUNCTION IF EXISTS client_audit_snapshot (client);
+DROP TABLE IF EXISTS client_audit;
+-- +goose StatementEnd
@@ -0,0 +1,161 @@
+package correlation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxRequestIDLength bounds the accepted request ids, a longer one is replaced
+const maxRequestIDLength = 128
+
+// IDs tie together the logs, the queries and the responses of the same request
+type IDs struct {
+	RequestID   string
+	TraceParent TraceParent
+}
+
+type idsKey struct{}
+
+func WithIDs(ctx context.Context, ids IDs) context.Context {
+	return context.WithValue(ctx, idsKey{}, ids)
+}
+
+// FromContext returns empty IDs when the context carries none
+func FromContext(ctx context.Context) IDs {
+	ids, _ := ctx.Value(idsKey{}).(IDs)
+	return ids
+}
+
+// RequestID keeps the id sent by the caller when it is valid, otherwise a new one is generated
+func RequestID(id string) string {
+	if !validRequestID(id) {
+		return uuid.NewString()
+	}
+
+	return id
+}
+
+// validRequestID accepts printable ASCII without spaces, so the id is safe to log and to pass to postgres
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// TraceParent is the W3C trace context of the request, https://www.w3.org/TR/trace-context/#traceparent-header
+type TraceParent struct {
+	TraceID  string
+	ParentID string
+	Flags    string
+}
+
+const (
+	traceParentVersion = "00"
+	traceIDLength      = 32
+	parentIDLength     = 16
+	flagsLength        = 2
+	// notSampledFlags are the flags of a trace started by the service, nothing samples it yet
+	notSampledFlags = "00"
+)
+
+// ParseTraceParent accepts the traceparent of any version which is not the invalid ff, the fields following
+// the known ones are ignored as the specification requires
+func ParseTraceParent(header string) (TraceParent, bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || !isHex(parts[0]) {
+		return TraceParent{}, false
+	}
+	if parts[0] == traceParentVersion && len(parts) != 4 {
+		return TraceParent{}, false
+	}
+
+	tp := TraceParent{TraceID: parts[1], ParentID: parts[2], Flags: parts[3]}
+	if len(tp.TraceID) != traceIDLength || !isHex(tp.TraceID) || isZero(tp.TraceID) ||
+		len(tp.ParentID) != parentIDLength || !isHex(tp.ParentID) || isZero(tp.ParentID) ||
+		len(tp.Flags) != flagsLength || !isHex(tp.Flags) {
+		return TraceParent{}, false
+	}
+
+	return tp, true
+}
+
+// ContinueTrace starts the span of the service in the trace of the caller or in a new trace
+// when the caller sent no valid traceparent
+func ContinueTrace(header string) TraceParent {
+	tp, ok := ParseTraceParent(header)
+	if !ok {
+		return TraceParent{
+			TraceID:  randomHex(traceIDLength),
+			ParentID: randomHex(parentIDLength),
+			Flags:    notSampledFlags,
+		}
+	}
+
+	tp.ParentID = randomHex(parentIDLength)
+	return tp
+}
+
+// SpanTraceParent is the trace parent of the current span of the context, the spans of the callers
+// are continued by it
+func SpanTraceParent(ctx context.Context) (TraceParent, bool) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return TraceParent{}, false
+	}
+
+	return TraceParent{
+		TraceID:  sc.TraceID().String(),
+		ParentID: sc.SpanID().String(),
+		Flags:    sc.TraceFlags().String(),
+	}, true
+}
+
+// IsZero tells whether the trace parent is missing
+func (tp TraceParent) IsZero() bool {
+	return tp.TraceID == ""
+}
+
+func (tp TraceParent) String() string {
+	if tp.IsZero() {
+		return ""
+	}
+
+	return traceParentVersion + "-" + tp.TraceID + "-" + tp.ParentID + "-" + tp.Flags
+}
+
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if (s[i] < '0' || s[i] > '9') && (s[i] < 'a' || s[i] > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isZero(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
+
+func randomHex(length int) string {
+	b := make([]byte, length/2)
+	for {
+		_, _ = rand.Read(b)
+		if id := hex.EncodeToString(b); !isZero(id) {
+			return id
+		}
+	}
+}
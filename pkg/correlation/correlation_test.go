@@ -0,0 +1,62 @@
+package correlation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	assert.Equal(t, "8e03978e-40d5-43e8-bc93-6894a57f9324", RequestID("8e03978e-40d5-43e8-bc93-6894a57f9324"))
+	assert.Equal(t, "request-1", RequestID("request-1"))
+
+	for _, invalid := range []string{"", "with space", "new\nline", "čau", strings.Repeat("a", maxRequestIDLength+1)} {
+		id := RequestID(invalid)
+		assert.NotEqual(t, invalid, id)
+		assert.Len(t, id, 36, invalid)
+	}
+}
+
+func TestParseTraceParent(t *testing.T) {
+	tp, ok := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.True(t, ok)
+	assert.Equal(t, TraceParent{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", ParentID: "00f067aa0ba902b7", Flags: "01"}, tp)
+
+	_, ok = ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future")
+	assert.True(t, ok)
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+	} {
+		_, ok := ParseTraceParent(invalid)
+		assert.False(t, ok, invalid)
+	}
+}
+
+func TestContinueTrace(t *testing.T) {
+	tp := ContinueTrace("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", tp.TraceID)
+	assert.NotEqual(t, "00f067aa0ba902b7", tp.ParentID)
+	assert.Equal(t, "01", tp.Flags)
+
+	started := ContinueTrace("invalid")
+	parsed, ok := ParseTraceParent(started.String())
+	assert.True(t, ok)
+	assert.Equal(t, started, parsed)
+	assert.Equal(t, notSampledFlags, started.Flags)
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Equal(t, IDs{}, FromContext(context.Background()))
+
+	ids := IDs{RequestID: "request-1", TraceParent: ContinueTrace("")}
+	assert.Equal(t, ids, FromContext(WithIDs(context.Background(), ids)))
+}
@@ -12,6 +12,12 @@ type Indicator interface {
 	Status() Status
 }
 
+// DetailedIndicator explains its status, the details are reported with the status of the component
+type DetailedIndicator interface {
+	Indicator
+	StatusWithDetails() (Status, map[string]any)
+}
+
 type Health struct {
 	Indicators []Indicator
 	timeout    time.Duration
@@ -24,8 +30,9 @@ type Result struct {
 }
 
 type ComponentStatus struct {
-	ComponentName string `json:"component" example:"main"`
-	Status        Status `json:"status" example:"up" enums:"up,down"`
+	ComponentName string         `json:"component" example:"main"`
+	Status        Status         `json:"status" example:"up" enums:"up,down"`
+	Details       map[string]any `json:"details,omitempty"`
 }
 
 func NewHealthCheck(timeout time.Duration, logger logger.Logger) *Health {
@@ -58,7 +65,15 @@ func (h *Health) Handle() *Result {
 				wg.Done()
 			}()
 
-			s := i.Status()
+			var (
+				s       Status
+				details map[string]any
+			)
+			if di, ok := i.(DetailedIndicator); ok {
+				s, details = di.StatusWithDetails()
+			} else {
+				s = i.Status()
+			}
 
 			if s != StatusUp {
 				once.Do(func() {
@@ -70,6 +85,7 @@ func (h *Health) Handle() *Result {
 			componentsStatuses = append(componentsStatuses, &ComponentStatus{
 				ComponentName: i.ComponentName(),
 				Status:        s,
+				Details:       details,
 			})
 		}(i)
 	}
@@ -196,3 +196,41 @@ func Test_HealthCheck_With_Multiple_Indicators_Handle_Down(t *testing.T) {
 
 	assert.Equal(t, StatusDown, hcr.Status)
 }
+
+type DetailedHealthIndicatorMock struct {
+	HealthIndicatorMock
+	Details map[string]any
+}
+
+func (i *DetailedHealthIndicatorMock) StatusWithDetails() (Status, map[string]any) {
+	return i.StatusFunc(), i.Details
+}
+
+func Test_HealthCheck_Handle_Down_With_Details(t *testing.T) {
+	lg := logger.New(logger.ParseLevel("debug"), false)
+
+	timeout := 500 * time.Millisecond
+	hc := NewHealthCheck(timeout, lg)
+
+	hi := DetailedHealthIndicatorMock{
+		HealthIndicatorMock: HealthIndicatorMock{
+			Name: "test",
+			StatusFunc: func() Status {
+				return StatusDown
+			},
+		},
+		Details: map[string]any{"reason": "behind"},
+	}
+	hc.RegisterIndicator(&hi)
+
+	hcr := hc.Handle()
+
+	expectedComponentsResult := []*ComponentStatus{{
+		ComponentName: "test",
+		Status:        StatusDown,
+		Details:       map[string]any{"reason": "behind"},
+	}}
+	assert.Equal(t, expectedComponentsResult, hcr.Components)
+
+	assert.Equal(t, StatusDown, hcr.Status)
+}
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -25,6 +26,7 @@ type Logger interface {
 	Error(message any, args ...any)
 	Fatal(message any, args ...any)
 	WithFields(meta []Meta) *ZeroLogger
+	WithContext(ctx context.Context) Logger
 	DebugWithMetadata(message string, metadata map[string]any)
 	InfoWithMetadata(message string, metadata map[string]any)
 	WarnWithMetadata(message string, metadata map[string]any)
@@ -1,13 +1,20 @@
 package logger
 
 import (
+	"context"
+
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/correlation"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
-	uuidField    = "uuid"
-	packageField = "package"
-	funcField    = "func"
+	uuidField      = "uuid"
+	packageField   = "package"
+	funcField      = "func"
+	requestIDField = "request_id"
+	traceIDField   = "trace_id"
+	spanIDField    = "span_id"
 )
 
 func (l *ZeroLogger) WithUUID(uuid string) *ZeroLogger {
@@ -59,6 +66,24 @@ func (l *ZeroLogger) WithFields(meta []Meta) *ZeroLogger {
 	}
 }
 
+// WithContext adds the correlation ids of the context to every log line, the trace and span ids
+// are the ones of the current span when there is one
+func (l *ZeroLogger) WithContext(ctx context.Context) Logger {
+	ids := correlation.FromContext(ctx)
+
+	var meta []Meta
+	if ids.RequestID != "" {
+		meta = append(meta, NewMeta(requestIDField, ids.RequestID))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		meta = append(meta, NewMeta(traceIDField, sc.TraceID().String()), NewMeta(spanIDField, sc.SpanID().String()))
+	} else if !ids.TraceParent.IsZero() {
+		meta = append(meta, NewMeta(traceIDField, ids.TraceParent.TraceID), NewMeta(spanIDField, ids.TraceParent.ParentID))
+	}
+
+	return l.WithFields(meta)
+}
+
 func (l *ZeroLogger) logWithMetadata(severity zerolog.Level, message string, metadata map[string]any) {
 	event := l.logger.WithLevel(severity)
 	flatten("", metadata, func(key string, value any) {
@@ -0,0 +1,78 @@
+package migrate
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Drift compares the migrations the binary was built with to the versions applied to the database
+type Drift struct {
+	// Expected is the latest migration of the binary
+	Expected int64
+	// Current is the latest version applied to the database
+	Current int64
+	// Pending are the migrations of the binary which are not applied
+	Pending []int64
+	// Unknown are the applied versions the binary has no migration for, the database was migrated by a newer binary
+	Unknown []int64
+}
+
+// Compare computes the drift of the applied versions, the goose version zero is ignored
+func Compare(migrations []Migration, applied []int64) Drift {
+	var d Drift
+
+	isApplied := make(map[int64]bool, len(applied))
+	for _, v := range applied {
+		if v == 0 {
+			continue
+		}
+		isApplied[v] = true
+		d.Current = max(d.Current, v)
+	}
+
+	known := make(map[int64]bool, len(migrations))
+	for _, m := range migrations {
+		known[m.Version] = true
+		d.Expected = max(d.Expected, m.Version)
+		if !isApplied[m.Version] {
+			d.Pending = append(d.Pending, m.Version)
+		}
+	}
+
+	for v := range isApplied {
+		if !known[v] {
+			d.Unknown = append(d.Unknown, v)
+		}
+	}
+	slices.Sort(d.Pending)
+	slices.Sort(d.Unknown)
+
+	return d
+}
+
+// Behind tells whether some migrations of the binary are not applied
+func (d Drift) Behind() bool {
+	return len(d.Pending) > 0
+}
+
+// Ahead tells whether the database has versions the binary doesn't know
+func (d Drift) Ahead() bool {
+	return len(d.Unknown) > 0
+}
+
+func (d Drift) InSync() bool {
+	return !d.Behind() && !d.Ahead()
+}
+
+func (d Drift) String() string {
+	switch {
+	case d.Behind() && d.Ahead():
+		return fmt.Sprintf("schema version %d diverged from the expected %d, pending %v, unknown %v", d.Current, d.Expected, d.Pending, d.Unknown)
+	case d.Behind():
+		return fmt.Sprintf("schema version %d is behind the expected %d, pending %v", d.Current, d.Expected, d.Pending)
+	case d.Ahead():
+		return fmt.Sprintf("schema version %d is ahead of the expected %d, unknown %v", d.Current, d.Expected, d.Unknown)
+	default:
+		return fmt.Sprintf("schema version %d is up to date", d.Current)
+	}
+}
@@ -0,0 +1,48 @@
+package migrate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	migrations := []Migration{{Version: 1}, {Version: 2}, {Version: 3}}
+
+	for name, tc := range map[string]struct {
+		applied  []int64
+		expected Drift
+		inSync   bool
+	}{
+		"in sync": {
+			applied:  []int64{0, 1, 2, 3},
+			expected: Drift{Expected: 3, Current: 3},
+			inSync:   true,
+		},
+		"behind": {
+			applied:  []int64{0, 1},
+			expected: Drift{Expected: 3, Current: 1, Pending: []int64{2, 3}},
+		},
+		"missing table": {
+			expected: Drift{Expected: 3, Pending: []int64{1, 2, 3}},
+		},
+		"ahead": {
+			applied:  []int64{0, 1, 2, 3, 5, 4},
+			expected: Drift{Expected: 3, Current: 5, Unknown: []int64{4, 5}},
+		},
+		"diverged": {
+			applied:  []int64{1, 3, 4},
+			expected: Drift{Expected: 3, Current: 4, Pending: []int64{2}, Unknown: []int64{4}},
+		},
+	} {
+		d := Compare(migrations, tc.applied)
+		assert.Equal(t, tc.expected, d, name)
+		assert.Equal(t, tc.inSync, d.InSync(), name)
+	}
+}
+
+func TestDrift_String(t *testing.T) {
+	assert.Equal(t, "schema version 1 is behind the expected 3, pending [2 3]", Drift{Expected: 3, Current: 1, Pending: []int64{2, 3}}.String())
+	assert.Equal(t, "schema version 4 is ahead of the expected 3, unknown [4]", Drift{Expected: 3, Current: 4, Unknown: []int64{4}}.String())
+	assert.Equal(t, "schema version 3 is up to date", Drift{Expected: 3, Current: 3}.String())
+}
@@ -0,0 +1,50 @@
+package migrate
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+
+	"github.com/pressly/goose/v3"
+)
+
+// Migration is a goose migration the binary was built with
+type Migration struct {
+	Version int64
+	Name    string
+}
+
+// Load lists the <version>_<name>.sql migrations of the directory ordered by version, goose parses and runs them,
+// see Migrator
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	paths, err := fs.Glob(fsys, path.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(paths))
+	versions := make(map[int64]string, len(paths))
+	for _, p := range paths {
+		name := path.Base(p)
+		version, err := goose.NumericComponent(name)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", name, err)
+		}
+		if other, ok := versions[version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s have the same version", other, name)
+		}
+		versions[version] = name
+
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    name,
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
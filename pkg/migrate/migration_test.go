@@ -0,0 +1,52 @@
+package migrate
+
+import (
+	"io/fs"
+	"path"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jamm3e3333/whalebone-go-test-project/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load(fstest.MapFS{
+		"migrations/2_b.sql":  {Data: []byte("-- +goose Up\nSELECT 2;\n")},
+		"migrations/1_a.sql":  {Data: []byte("-- +goose Up\nSELECT 1;\n")},
+		"migrations/notes.md": {Data: []byte("not a migration")},
+	}, "migrations")
+	require.NoError(t, err)
+
+	require.Len(t, migrations, 2)
+	assert.Equal(t, int64(1), migrations[0].Version)
+	assert.Equal(t, "1_a.sql", migrations[0].Name)
+	assert.Equal(t, int64(2), migrations[1].Version)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	for name, fsys := range map[string]fstest.MapFS{
+		"no version":  {"migrations/a.sql": {Data: []byte("-- +goose Up\n")}},
+		"bad version": {"migrations/x_a.sql": {Data: []byte("-- +goose Up\n")}},
+		"duplicate version": {
+			"migrations/1_a.sql": {Data: []byte("-- +goose Up\n")},
+			"migrations/1_b.sql": {Data: []byte("-- +goose Up\n")},
+		},
+	} {
+		_, err := Load(fsys, "migrations")
+		assert.Error(t, err, name)
+	}
+}
+
+func TestLoad_EmbeddedMigrations(t *testing.T) {
+	migrations, err := Load(db.Migrations, db.MigrationsDir)
+	require.NoError(t, err)
+
+	paths, err := fs.Glob(db.Migrations, db.MigrationsDir+"/*.sql")
+	require.NoError(t, err)
+	require.Len(t, migrations, len(paths))
+	for i, m := range migrations {
+		assert.Equal(t, path.Base(paths[i]), m.Name)
+	}
+}
@@ -0,0 +1,171 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io/fs"
+	"path"
+	"time"
+
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+// lockID is the advisory lock held while migrating, instances migrating at the same time wait for each other
+const lockID int64 = 4_857_136_290_117_305_621
+
+var ErrNoAppliedMigration = errors.New("no applied migration")
+
+type Status struct {
+	Version int64
+	Name    string
+	// AppliedAt is nil for a pending migration
+	AppliedAt *time.Time
+}
+
+// Migrator applies the goose migrations of fsys and records them in the goose_db_version table
+type Migrator struct {
+	db       *sql.DB
+	locker   lock.SessionLocker
+	provider *goose.Provider
+	// unlocked runs the steps of a command which holds the lock itself
+	unlocked *goose.Provider
+	log      logger.Logger
+}
+
+// New takes the migrations from the root of fsys
+func New(db *sql.DB, fsys fs.FS, log logger.Logger) (*Migrator, error) {
+	locker, err := lock.NewPostgresSessionLocker(lock.WithLockID(lockID))
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, fsys, goose.WithSessionLocker(locker))
+	if err != nil {
+		return nil, err
+	}
+
+	unlocked, err := goose.NewProvider(goose.DialectPostgres, db, fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:       db,
+		locker:   locker,
+		provider: provider,
+		unlocked: unlocked,
+		log:      log,
+	}, nil
+}
+
+// Up applies the pending migrations ordered by version and returns how many were applied
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	results, err := m.provider.Up(ctx)
+	if err != nil {
+		return 0, err
+	}
+	m.logResults(results...)
+
+	return len(results), nil
+}
+
+// Down rolls back the latest applied migration
+func (m *Migrator) Down(ctx context.Context) error {
+	result, err := down(ctx, m.provider)
+	if err != nil {
+		return err
+	}
+	m.logResults(result)
+
+	return nil
+}
+
+// Redo rolls back the latest applied migration and applies it again, the lock is held across both steps
+// so that another instance can't migrate in between
+func (m *Migrator) Redo(ctx context.Context) (err error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	err = m.locker.SessionLock(ctx, conn)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		unlockErr := m.locker.SessionUnlock(context.WithoutCancel(ctx), conn)
+		if err == nil {
+			err = unlockErr
+		}
+	}()
+
+	result, err := down(ctx, m.unlocked)
+	if err != nil {
+		return err
+	}
+	m.logResults(result)
+
+	up, err := m.unlocked.ApplyVersion(ctx, result.Source.Version, true)
+	if err != nil {
+		return err
+	}
+	m.logResults(up)
+
+	return nil
+}
+
+// Reset rolls back all applied migrations, the latest first
+func (m *Migrator) Reset(ctx context.Context) error {
+	results, err := m.provider.DownTo(ctx, 0)
+	if err != nil {
+		return err
+	}
+	m.logResults(results...)
+
+	return nil
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	migrationStatuses, err := m.provider.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(migrationStatuses))
+	for _, ms := range migrationStatuses {
+		s := Status{
+			Version: ms.Source.Version,
+			Name:    path.Base(ms.Source.Path),
+		}
+		if ms.State == goose.StateApplied {
+			appliedAt := ms.AppliedAt
+			s.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, s)
+	}
+
+	return statuses, nil
+}
+
+func down(ctx context.Context, provider *goose.Provider) (*goose.MigrationResult, error) {
+	result, err := provider.Down(ctx)
+	if errors.Is(err, goose.ErrNoNextVersion) {
+		return nil, ErrNoAppliedMigration
+	}
+
+	return result, err
+}
+
+func (m *Migrator) logResults(results ...*goose.MigrationResult) {
+	for _, r := range results {
+		m.log.InfoWithMetadata("migration applied", map[string]any{
+			"name":        path.Base(r.Source.Path),
+			"direction":   r.Direction,
+			"duration_ms": r.Duration.Milliseconds(),
+		})
+	}
+}
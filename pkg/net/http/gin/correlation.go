@@ -0,0 +1,36 @@
+package gin
+
+import (
+	ginpkg "github.com/gin-gonic/gin"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/correlation"
+)
+
+const (
+	RequestIDHeader   = "X-Request-ID"
+	TraceParentHeader = "traceparent"
+)
+
+// CorrelationMiddleware accepts the request id and the traceparent of the caller or generates them, stores them
+// in the request context and echoes them in the response. The traceparent is the one of the request span
+// when the request is traced. The request id header is replaced by the accepted id,
+// so the handlers reading it see the same id as the logs. The handlers passing the gin context on need
+// the engine's ContextWithFallback for the ids to reach the queries.
+func CorrelationMiddleware() ginpkg.HandlerFunc {
+	return func(c *ginpkg.Context) {
+		tp, ok := correlation.SpanTraceParent(c.Request.Context())
+		if !ok {
+			tp = correlation.ContinueTrace(c.GetHeader(TraceParentHeader))
+		}
+		ids := correlation.IDs{
+			RequestID:   correlation.RequestID(c.GetHeader(RequestIDHeader)),
+			TraceParent: tp,
+		}
+
+		c.Request.Header.Set(RequestIDHeader, ids.RequestID)
+		c.Request = c.Request.WithContext(correlation.WithIDs(c.Request.Context(), ids))
+		c.Header(RequestIDHeader, ids.RequestID)
+		c.Header(TraceParentHeader, ids.TraceParent.String())
+
+		c.Next()
+	}
+}
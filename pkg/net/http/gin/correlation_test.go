@@ -0,0 +1,86 @@
+package gin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ginpkg "github.com/gin-gonic/gin"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/correlation"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func serveCorrelated(r *http.Request, middlewares ...ginpkg.HandlerFunc) (*httptest.ResponseRecorder, correlation.IDs, string) {
+	ginpkg.SetMode(ginpkg.TestMode)
+	engine := ginpkg.New()
+	engine.Use(middlewares...)
+	engine.Use(CorrelationMiddleware())
+
+	var ids correlation.IDs
+	var header string
+	engine.GET("/", func(c *ginpkg.Context) {
+		ids = correlation.FromContext(c.Request.Context())
+		header = c.GetHeader(RequestIDHeader)
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, r)
+
+	return w, ids, header
+}
+
+func TestCorrelationMiddleware_AcceptsCallerIDs(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(RequestIDHeader, "request-1")
+	r.Header.Set(TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	w, ids, header := serveCorrelated(r)
+
+	assert.Equal(t, "request-1", ids.RequestID)
+	assert.Equal(t, "request-1", header)
+	assert.Equal(t, "request-1", w.Header().Get(RequestIDHeader))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", ids.TraceParent.TraceID)
+	assert.Equal(t, ids.TraceParent.String(), w.Header().Get(TraceParentHeader))
+}
+
+func TestCorrelationMiddleware_GeneratesIDs(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(RequestIDHeader, "not valid")
+	r.Header.Set(TraceParentHeader, "garbage")
+
+	w, ids, header := serveCorrelated(r)
+
+	assert.NotEqual(t, "not valid", ids.RequestID)
+	assert.NotEmpty(t, ids.RequestID)
+	assert.Equal(t, ids.RequestID, header)
+	assert.Equal(t, ids.RequestID, w.Header().Get(RequestIDHeader))
+	_, ok := correlation.ParseTraceParent(w.Header().Get(TraceParentHeader))
+	assert.True(t, ok)
+}
+
+func TestCorrelationMiddleware_UsesRequestSpan(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	w, ids, _ := serveCorrelated(r, func(c *ginpkg.Context) {
+		ctx := propagation.TraceContext{}.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := tp.Tracer("test").Start(ctx, "request")
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	})
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", ids.TraceParent.TraceID)
+	assert.Equal(t, spans[0].SpanContext.SpanID().String(), ids.TraceParent.ParentID)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-"+spans[0].SpanContext.SpanID().String()+"-01", w.Header().Get(TraceParentHeader))
+}
@@ -0,0 +1,147 @@
+package gin
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	ginpkg "github.com/gin-gonic/gin"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
+)
+
+// codes of the problems of the idempotency middleware
+const (
+	IdempotencyKeyTooLongCode    = "idempotency_key_too_long"
+	IdempotencyKeyReusedCode     = "idempotency_key_reused"
+	IdempotencyKeyInProgressCode = "idempotency_key_in_progress"
+	UnreadableBodyCode           = "unreadable_body"
+	InternalServerErrorCode      = "internal_server_error"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotencyFingerprintSep = "\n"
+)
+
+type IdempotencyRecord struct {
+	Fingerprint string
+	// Completed is false while the first request with the key is still being processed
+	Completed   bool
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+type IdempotencyStore interface {
+	// Claim reserves the key for the request, when the key is already taken and not expired it returns the stored record
+	Claim(ctx context.Context, key string, fingerprint string, ttl time.Duration) (bool, IdempotencyRecord, error)
+	Complete(ctx context.Context, key string, statusCode int, contentType string, body []byte) error
+	Release(ctx context.Context, key string) error
+}
+
+// IdempotencyMiddleware replays the stored response for requests retried with the same Idempotency-Key header.
+// Server errors are not stored, so the request can be retried with the same key.
+func IdempotencyMiddleware(store IdempotencyStore, ttl time.Duration, lg logger.Logger) ginpkg.HandlerFunc {
+	return func(c *ginpkg.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			AbortWithProblem(c, http.StatusBadRequest, NewProblem(c, http.StatusBadRequest, IdempotencyKeyTooLongCode,
+				"Idempotency key is too long", fmt.Sprintf("idempotency key has more than %d characters", maxIdempotencyKeyLength)))
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			body, err = io.ReadAll(c.Request.Body)
+			if err != nil {
+				AbortWithProblem(c, http.StatusBadRequest, NewProblem(c, http.StatusBadRequest, UnreadableBodyCode,
+					"Request body can't be read", err.Error()))
+				return
+			}
+		}
+		c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+
+		// the response has to be stored even when the client has already gone away
+		ctx := context.WithoutCancel(c.Request.Context())
+		fingerprint := requestFingerprint(c.Request.Method, c.FullPath(), body)
+
+		claimed, record, err := store.Claim(ctx, key, fingerprint, ttl)
+		if err != nil {
+			lg.ErrorWithMetadata("unable to claim idempotency key", map[string]any{
+				"error": err.Error(),
+				"key":   key,
+			})
+			AbortWithProblem(c, http.StatusInternalServerError, NewProblem(c, http.StatusInternalServerError, InternalServerErrorCode,
+				"Internal server error", ""))
+			return
+		}
+
+		if !claimed {
+			replay(c, fingerprint, record)
+			return
+		}
+
+		responseBodyWriter := &bodyWriter{
+			bodyBuf:        bytes.NewBufferString(""),
+			ResponseWriter: c.Writer,
+		}
+		c.Writer = responseBodyWriter
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if status >= http.StatusInternalServerError {
+			err = store.Release(ctx, key)
+		} else {
+			err = store.Complete(ctx, key, status, c.Writer.Header().Get("Content-Type"), responseBodyWriter.bodyBuf.Bytes())
+		}
+		if err != nil {
+			lg.ErrorWithMetadata("unable to store idempotency key result", map[string]any{
+				"error":  err.Error(),
+				"key":    key,
+				"status": status,
+			})
+		}
+	}
+}
+
+func replay(c *ginpkg.Context, fingerprint string, record IdempotencyRecord) {
+	if record.Fingerprint != fingerprint {
+		AbortWithProblem(c, http.StatusConflict, NewProblem(c, http.StatusConflict, IdempotencyKeyReusedCode,
+			"Idempotency key was reused", "idempotency key was already used for a different request"))
+		return
+	}
+	if !record.Completed {
+		AbortWithProblem(c, http.StatusConflict, NewProblem(c, http.StatusConflict, IdempotencyKeyInProgressCode,
+			"Idempotency key is in progress", "request with the idempotency key is still in progress"))
+		return
+	}
+
+	c.Header(IdempotentReplayedHeader, "true")
+	if record.ContentType != "" {
+		c.Header("Content-Type", record.ContentType)
+	}
+	c.Status(record.StatusCode)
+	_, _ = c.Writer.Write(record.Body)
+	c.Abort()
+}
+
+func requestFingerprint(method string, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + idempotencyFingerprintSep + path + idempotencyFingerprintSep))
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,155 @@
+package gin
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	ginpkg "github.com/gin-gonic/gin"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+type idempotencyStoreMock struct {
+	mu      sync.Mutex
+	records map[string]IdempotencyRecord
+}
+
+func newIdempotencyStoreMock() *idempotencyStoreMock {
+	return &idempotencyStoreMock{records: map[string]IdempotencyRecord{}}
+}
+
+func (s *idempotencyStoreMock) Claim(_ context.Context, key string, fingerprint string, _ time.Duration) (bool, IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r, ok := s.records[key]; ok {
+		return false, r, nil
+	}
+	s.records[key] = IdempotencyRecord{Fingerprint: fingerprint}
+
+	return true, s.records[key], nil
+}
+
+func (s *idempotencyStoreMock) Complete(_ context.Context, key string, statusCode int, contentType string, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := s.records[key]
+	r.Completed = true
+	r.StatusCode = statusCode
+	r.ContentType = contentType
+	r.Body = body
+	s.records[key] = r
+
+	return nil
+}
+
+func (s *idempotencyStoreMock) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+
+	return nil
+}
+
+func newIdempotencyTestEngine(store IdempotencyStore, status *int, calls *int) *ginpkg.Engine {
+	ginpkg.SetMode(ginpkg.TestMode)
+	lg := logger.New(logger.ParseLevel("debug"), false)
+
+	engine := ginpkg.New()
+	engine.POST("/resource", IdempotencyMiddleware(store, time.Hour, lg), func(c *ginpkg.Context) {
+		*calls++
+		c.JSON(*status, ginpkg.H{"calls": *calls})
+	})
+
+	return engine
+}
+
+func doIdempotentRequest(engine *ginpkg.Engine, key string, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest(http.MethodPost, "/resource", bytes.NewBufferString(body))
+	r.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		r.Header.Set(IdempotencyKeyHeader, key)
+	}
+	engine.ServeHTTP(w, r)
+
+	return w
+}
+
+func Test_IdempotencyMiddleware_ReplaysResponse(t *testing.T) {
+	status, calls := http.StatusCreated, 0
+	engine := newIdempotencyTestEngine(newIdempotencyStoreMock(), &status, &calls)
+
+	first := doIdempotentRequest(engine, "key", `{"name":"test"}`)
+	second := doIdempotentRequest(engine, "key", `{"name":"test"}`)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "application/json; charset=utf-8", second.Header().Get("Content-Type"))
+	assert.Equal(t, "true", second.Header().Get(IdempotentReplayedHeader))
+	assert.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+}
+
+func Test_IdempotencyMiddleware_ConflictOnDifferentBody(t *testing.T) {
+	status, calls := http.StatusCreated, 0
+	engine := newIdempotencyTestEngine(newIdempotencyStoreMock(), &status, &calls)
+
+	doIdempotentRequest(engine, "key", `{"name":"test"}`)
+	w := doIdempotentRequest(engine, "key", `{"name":"other"}`)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type": "urn:problem-type:idempotency_key_reused",
+		"title": "Idempotency key was reused",
+		"status": 409,
+		"detail": "idempotency key was already used for a different request",
+		"instance": "/resource",
+		"code": "idempotency_key_reused"
+	}`, w.Body.String())
+}
+
+func Test_IdempotencyMiddleware_ConflictWhileInProgress(t *testing.T) {
+	status, calls := http.StatusCreated, 0
+	store := newIdempotencyStoreMock()
+	engine := newIdempotencyTestEngine(store, &status, &calls)
+
+	_, _, _ = store.Claim(context.Background(), "key", requestFingerprint(http.MethodPost, "/resource", []byte(`{}`)), time.Hour)
+	w := doIdempotentRequest(engine, "key", `{}`)
+
+	assert.Equal(t, 0, calls)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func Test_IdempotencyMiddleware_ServerErrorIsNotStored(t *testing.T) {
+	status, calls := http.StatusInternalServerError, 0
+	engine := newIdempotencyTestEngine(newIdempotencyStoreMock(), &status, &calls)
+
+	first := doIdempotentRequest(engine, "key", `{}`)
+	status = http.StatusCreated
+	second := doIdempotentRequest(engine, "key", `{}`)
+
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, http.StatusInternalServerError, first.Code)
+	assert.Equal(t, http.StatusCreated, second.Code)
+}
+
+func Test_IdempotencyMiddleware_WithoutKey(t *testing.T) {
+	status, calls := http.StatusCreated, 0
+	engine := newIdempotencyTestEngine(newIdempotencyStoreMock(), &status, &calls)
+
+	doIdempotentRequest(engine, "", `{}`)
+	doIdempotentRequest(engine, "", `{}`)
+
+	assert.Equal(t, 2, calls)
+}
@@ -106,7 +106,7 @@ func LoggerMiddleware(config *LoggerMiddlewareConfig, lg logger.Logger) ginpkg.H
 			"status": c.Writer.Status(),
 			"body":   responseBody,
 		}
-		lg.InfoWithMetadata("HTTP Request / Response", map[string]any{
+		lg.WithContext(c.Request.Context()).InfoWithMetadata("HTTP Request / Response", map[string]any{
 			"request":  requestMapMetadata,
 			"response": responseMapMetadata,
 		})
@@ -0,0 +1,46 @@
+package gin
+
+import (
+	ginpkg "github.com/gin-gonic/gin"
+)
+
+const (
+	ProblemContentType = "application/problem+json"
+
+	problemTypeURIPrefix = "urn:problem-type:"
+)
+
+// Problem is the problem details object of RFC 7807, Code is the stable code of the problem type
+type Problem struct {
+	Type      string `json:"type" example:"urn:problem-type:client_not_found"`
+	Title     string `json:"title" example:"Client not found"`
+	Status    int    `json:"status" example:"404"`
+	Detail    string `json:"detail,omitempty" example:"client not found"`
+	Instance  string `json:"instance,omitempty" example:"/v1/client/123e4567-e89b-12d3-a456-426614174000"`
+	Code      string `json:"code" example:"client_not_found"`
+	RequestID string `json:"request_id,omitempty" example:"8e03978e-40d5-43e8-bc93-6894a57f9324"`
+}
+
+// ProblemTypeURI identifies the problem type of the code, the codes are never reused for another problem
+func ProblemTypeURI(code string) string {
+	return problemTypeURIPrefix + code
+}
+
+// NewProblem describes the occurrence of the problem in the request
+func NewProblem(c *ginpkg.Context, status int, code, title, detail string) Problem {
+	return Problem{
+		Type:      ProblemTypeURI(code),
+		Title:     title,
+		Status:    status,
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		Code:      code,
+		RequestID: c.GetHeader(RequestIDHeader),
+	}
+}
+
+// AbortWithProblem renders the problem, it is any so that the problem can be extended with more members
+func AbortWithProblem(c *ginpkg.Context, status int, problem any) {
+	c.Header("Content-Type", ProblemContentType)
+	c.AbortWithStatusJSON(status, problem)
+}
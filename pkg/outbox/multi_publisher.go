@@ -0,0 +1,27 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+)
+
+// MultiPublisher publishes every message to all publishers, the message is published to all of them again
+// when any of them fails
+type MultiPublisher struct {
+	publishers []Publisher
+}
+
+func NewMultiPublisher(publishers ...Publisher) *MultiPublisher {
+	return &MultiPublisher{publishers: publishers}
+}
+
+func (p *MultiPublisher) Publish(ctx context.Context, m Message) error {
+	var errs []error
+	for _, publisher := range p.publishers {
+		if err := publisher.Publish(ctx, m); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
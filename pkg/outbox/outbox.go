@@ -0,0 +1,37 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Message is a domain event stored in the outbox in the same transaction as the change which caused it
+type Message struct {
+	ID int64 `json:"id"`
+	// AggregateID is the ordering key, messages of the same aggregate are published in order
+	AggregateID string          `json:"aggregate_id"`
+	EventType   string          `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	CreatedAt   time.Time       `json:"created_at"`
+	// Attempts is the number of failed publish attempts
+	Attempts int `json:"-"`
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, m Message) error
+}
+
+type Store interface {
+	// Claim leases the oldest unpublished message of up to limit aggregates whose retry is due
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]Message, error)
+	// Complete removes the published message
+	Complete(ctx context.Context, id int64) error
+	// Fail releases the message to be claimed again after the backoff
+	Fail(ctx context.Context, id int64, backoff time.Duration, reason string) error
+}
+
+type Metrics interface {
+	ObserveOutboxLagHistogram(lag float64, labels ...string)
+	IncOutboxMessageCounter(labels ...string)
+}
@@ -0,0 +1,58 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriterPublisher_WritesJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	publisher := NewWriterPublisher(&buf)
+
+	for _, id := range []int64{1, 2} {
+		err := publisher.Publish(context.Background(), Message{
+			ID:          id,
+			AggregateID: "a",
+			EventType:   "client.created",
+			Payload:     json.RawMessage(`{"id":"a"}`),
+		})
+		assert.NoError(t, err)
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 2)
+
+	var m Message
+	assert.NoError(t, json.Unmarshal(lines[1], &m))
+	assert.Equal(t, int64(2), m.ID)
+	assert.JSONEq(t, `{"id":"a"}`, string(m.Payload))
+}
+
+func TestWebhookPublisher(t *testing.T) {
+	status := http.StatusNoContent
+	var received Message
+	var eventType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		eventType = r.Header.Get(EventTypeHeader)
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	publisher := NewWebhookPublisher(server.URL, &http.Client{Timeout: time.Second})
+	m := Message{ID: 7, AggregateID: "a", EventType: "client.updated", Payload: json.RawMessage(`{}`)}
+
+	assert.NoError(t, publisher.Publish(context.Background(), m))
+	assert.Equal(t, int64(7), received.ID)
+	assert.Equal(t, "client.updated", eventType)
+
+	status = http.StatusServiceUnavailable
+	assert.Error(t, publisher.Publish(context.Background(), m))
+}
@@ -0,0 +1,128 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
+)
+
+const (
+	messagePublished = "published"
+	messageFailed    = "failed"
+)
+
+type RelayConfig struct {
+	Interval  time.Duration
+	BatchSize int
+	// Lease is how long a claimed message is hidden from other relays, it has to be longer than publishing takes
+	Lease      time.Duration
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// Relay periodically publishes the outbox messages, failed messages are retried with exponential backoff.
+// Only the oldest message of an aggregate is claimed, so a failing message holds back the later ones of the same aggregate.
+type Relay struct {
+	store     Store
+	publisher Publisher
+	cfg       RelayConfig
+	metrics   Metrics
+	lg        logger.Logger
+}
+
+func NewRelay(store Store, publisher Publisher, cfg RelayConfig, metrics Metrics, lg logger.Logger) *Relay {
+	return &Relay{
+		store:     store,
+		publisher: publisher,
+		cfg:       cfg,
+		metrics:   metrics,
+		lg:        lg,
+	}
+}
+
+// Run blocks until the context is done
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		// a full batch means there are probably more messages waiting
+		for r.relay(ctx) == r.cfg.BatchSize {
+			if ctx.Err() != nil {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// relay publishes one batch of messages and returns its size
+func (r *Relay) relay(ctx context.Context) int {
+	messages, err := r.store.Claim(ctx, r.cfg.BatchSize, r.cfg.Lease)
+	if err != nil {
+		r.lg.ErrorWithMetadata("unable to claim outbox messages", map[string]any{
+			"error": err.Error(),
+		})
+		return 0
+	}
+
+	for _, m := range messages {
+		r.publish(ctx, m)
+	}
+
+	return len(messages)
+}
+
+func (r *Relay) publish(ctx context.Context, m Message) {
+	err := r.publisher.Publish(ctx, m)
+	if err != nil {
+		if r.metrics != nil {
+			r.metrics.IncOutboxMessageCounter(messageFailed, m.EventType)
+		}
+
+		backoff := r.backoff(m.Attempts)
+		r.lg.ErrorWithMetadata("unable to publish outbox message", map[string]any{
+			"error":    err.Error(),
+			"id":       m.ID,
+			"attempts": m.Attempts + 1,
+			"backoff":  backoff.String(),
+		})
+
+		if err := r.store.Fail(ctx, m.ID, backoff, err.Error()); err != nil {
+			r.lg.ErrorWithMetadata("unable to release outbox message", map[string]any{
+				"error": err.Error(),
+				"id":    m.ID,
+			})
+		}
+		return
+	}
+
+	if r.metrics != nil {
+		r.metrics.IncOutboxMessageCounter(messagePublished, m.EventType)
+		r.metrics.ObserveOutboxLagHistogram(time.Since(m.CreatedAt).Seconds(), m.EventType)
+	}
+
+	// the message is published again when it cannot be removed, publishers have to tolerate duplicates
+	if err := r.store.Complete(ctx, m.ID); err != nil {
+		r.lg.ErrorWithMetadata("unable to complete outbox message", map[string]any{
+			"error": err.Error(),
+			"id":    m.ID,
+		})
+	}
+}
+
+// backoff doubles with every failed attempt up to MaxBackoff
+func (r *Relay) backoff(attempts int) time.Duration {
+	backoff := r.cfg.MinBackoff
+	for i := 0; i < attempts && backoff < r.cfg.MaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, r.cfg.MaxBackoff)
+}
@@ -0,0 +1,149 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+type storeMock struct {
+	mu       sync.Mutex
+	messages []Message
+	claimed  map[int64]bool
+	backoffs map[int64]time.Duration
+}
+
+func newStoreMock(messages ...Message) *storeMock {
+	return &storeMock{
+		messages: messages,
+		claimed:  map[int64]bool{},
+		backoffs: map[int64]time.Duration{},
+	}
+}
+
+// Claim returns the oldest message of every aggregate like the postgres store does
+func (s *storeMock) Claim(_ context.Context, limit int, _ time.Duration) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sort.Slice(s.messages, func(i, j int) bool { return s.messages[i].ID < s.messages[j].ID })
+
+	seen := map[string]bool{}
+	claimed := make([]Message, 0, limit)
+	for _, m := range s.messages {
+		if seen[m.AggregateID] {
+			continue
+		}
+		seen[m.AggregateID] = true
+		if s.claimed[m.ID] || len(claimed) == limit {
+			continue
+		}
+		s.claimed[m.ID] = true
+		claimed = append(claimed, m)
+	}
+
+	return claimed, nil
+}
+
+func (s *storeMock) Complete(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, m := range s.messages {
+		if m.ID == id {
+			s.messages = append(s.messages[:i], s.messages[i+1:]...)
+			break
+		}
+	}
+	delete(s.claimed, id)
+
+	return nil
+}
+
+func (s *storeMock) Fail(_ context.Context, id int64, backoff time.Duration, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, m := range s.messages {
+		if m.ID == id {
+			s.messages[i].Attempts++
+		}
+	}
+	delete(s.claimed, id)
+	s.backoffs[id] = backoff
+
+	return nil
+}
+
+type publisherMock struct {
+	published []int64
+	failing   map[int64]bool
+}
+
+func (p *publisherMock) Publish(_ context.Context, m Message) error {
+	if p.failing[m.ID] {
+		return errors.New("unavailable")
+	}
+	p.published = append(p.published, m.ID)
+
+	return nil
+}
+
+func newTestRelay(store Store, publisher Publisher) *Relay {
+	return NewRelay(store, publisher, RelayConfig{
+		Interval:   time.Second,
+		BatchSize:  10,
+		Lease:      time.Minute,
+		MinBackoff: time.Second,
+		MaxBackoff: 5 * time.Second,
+	}, nil, logger.New(logger.ParseLevel("debug"), false))
+}
+
+func TestRelay_PublishesInOrderPerAggregate(t *testing.T) {
+	store := newStoreMock(
+		Message{ID: 1, AggregateID: "a"},
+		Message{ID: 2, AggregateID: "b"},
+		Message{ID: 3, AggregateID: "a"},
+	)
+	publisher := &publisherMock{}
+	relay := newTestRelay(store, publisher)
+
+	assert.Equal(t, 2, relay.relay(context.Background()))
+	assert.Equal(t, 1, relay.relay(context.Background()))
+	assert.Equal(t, 0, relay.relay(context.Background()))
+
+	assert.Equal(t, []int64{1, 2, 3}, publisher.published)
+}
+
+func TestRelay_FailedMessageHoldsBackItsAggregate(t *testing.T) {
+	store := newStoreMock(
+		Message{ID: 1, AggregateID: "a"},
+		Message{ID: 2, AggregateID: "a"},
+		Message{ID: 3, AggregateID: "b"},
+	)
+	publisher := &publisherMock{failing: map[int64]bool{1: true}}
+	relay := newTestRelay(store, publisher)
+
+	relay.relay(context.Background())
+	relay.relay(context.Background())
+
+	assert.Equal(t, []int64{3}, publisher.published)
+	assert.Len(t, store.messages, 2)
+	assert.Equal(t, 2, store.messages[0].Attempts)
+}
+
+func TestRelay_Backoff(t *testing.T) {
+	relay := newTestRelay(newStoreMock(), &publisherMock{})
+
+	assert.Equal(t, time.Second, relay.backoff(0))
+	assert.Equal(t, 2*time.Second, relay.backoff(1))
+	assert.Equal(t, 4*time.Second, relay.backoff(2))
+	assert.Equal(t, 5*time.Second, relay.backoff(3))
+	assert.Equal(t, 5*time.Second, relay.backoff(100))
+}
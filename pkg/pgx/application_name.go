@@ -0,0 +1,47 @@
+package pgx
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/correlation"
+)
+
+const (
+	applicationNameParam = "application_name"
+	// maxApplicationNameLength is NAMEDATALEN - 1, postgres truncates longer names
+	maxApplicationNameLength = 63
+)
+
+// applicationName tags the connection with the request id, the queries of the request can be found
+// in pg_stat_activity by it
+func applicationName(base string, requestID string) string {
+	name := base
+	switch {
+	case requestID == "":
+	case base == "":
+		name = requestID
+	default:
+		name = base + "/" + requestID
+	}
+
+	if len(name) > maxApplicationNameLength {
+		return name[:maxApplicationNameLength]
+	}
+
+	return name
+}
+
+// tagConnection sets the application_name of the acquired connection to the request id of the context. The name
+// is set only when it differs from the current one, an idle connection keeps the name of its last request.
+func tagConnection(base string) func(ctx context.Context, conn *pgx.Conn) bool {
+	return func(ctx context.Context, conn *pgx.Conn) bool {
+		name := applicationName(base, correlation.FromContext(ctx).RequestID)
+		if conn.PgConn().ParameterStatus(applicationNameParam) == name {
+			return true
+		}
+
+		_, err := conn.Exec(ctx, "SELECT set_config('"+applicationNameParam+"', $1, FALSE)", name)
+		return err == nil
+	}
+}
@@ -0,0 +1,19 @@
+package pgx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplicationName(t *testing.T) {
+	assert.Equal(t, "whalebone_clients", applicationName("whalebone_clients", ""))
+	assert.Equal(t, "whalebone_clients/request-1", applicationName("whalebone_clients", "request-1"))
+	assert.Equal(t, "request-1", applicationName("", "request-1"))
+	assert.Equal(t, "", applicationName("", ""))
+
+	long := applicationName("whalebone_clients", strings.Repeat("a", 128))
+	assert.Len(t, long, maxApplicationNameLength)
+	assert.True(t, strings.HasPrefix(long, "whalebone_clients/aaa"))
+}
@@ -3,7 +3,10 @@ package pgx
 import "time"
 
 type Config struct {
-	ConnectionURL     string
+	ConnectionURL string
+	// ApplicationName is the application_name of the connections, the request id of the query is appended to it
+	ApplicationName string
+	// LogLevel is the lowest level of the query logs, successful queries are logged at debug, slow ones at warn
 	LogLevel          string
 	MaxConnLifetime   time.Duration
 	MaxConnIdleTime   time.Duration
@@ -11,4 +14,18 @@ type Config struct {
 	DefaultMaxConns   int32
 	DefaultMinConns   int32
 	HealthCheckPeriod time.Duration
+	// TxMaxAttempts is how many times a transaction failing on a serialization failure or a deadlock is run, at least once
+	TxMaxAttempts int
+	// ReplicaURLs are the read replicas which serve the queries marked by ReadFromReplica and the read only transactions
+	ReplicaURLs []string
+	// MaxReplicationLag stops the routing to a replica lagging behind the primary more, zero disables the check
+	MaxReplicationLag time.Duration
+	// ReplicaCheckPeriod is how often the connection and the lag of the replicas is checked
+	ReplicaCheckPeriod time.Duration
+	// SlowQueryThreshold logs the queries which take longer at warn, zero disables it
+	SlowQueryThreshold time.Duration
+	// LogArgs adds the query arguments to the logs, the arguments matching RedactedArgs are masked
+	LogArgs bool
+	// RedactedArgs are masked in the logged arguments, nil uses DefaultRedactedArgs
+	RedactedArgs []string
 }
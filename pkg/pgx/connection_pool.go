@@ -4,15 +4,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type NamedArgs = pgx.NamedArgs
 
+type Row = pgx.Row
+
 var ErrNoRows = pgx.ErrNoRows
 
 type TxOptions struct {
@@ -20,8 +25,11 @@ type TxOptions struct {
 	AccessMode     TxAccessMode
 	DeferrableMode TxDeferrableMode
 
-	// BeginQuery is for custom tx options
+	// BeginQuery is for custom tx options, it replaces the other options
 	BeginQuery string
+
+	// MaxAttempts overrides Config.TxMaxAttempts when not zero
+	MaxAttempts int
 }
 
 type TxIsoLevel = string
@@ -50,20 +58,29 @@ const (
 const (
 	queryError          = "error"
 	querySuccess        = "success"
+	queryNoRows         = "no_rows"
 	transactionCommit   = "commit"
 	transactionRollback = "rollback"
+	transactionRetry    = "retry"
 )
 
 type ConnectionPool struct {
-	pool         *pgxpool.Pool
-	metrics      MonitoringMetrics
-	log          logger.Logger
-	queryTimeout time.Duration
+	pool              *pgxpool.Pool
+	replicas          []*replica
+	nextReplica       atomic.Uint64
+	maxReplicationLag time.Duration
+	metrics           MonitoringMetrics
+	log               logger.Logger
+	tracer            trace.Tracer
+	queryLog          queryLog
+	queryTimeout      time.Duration
+	txMaxAttempts     int
 }
 
 type RegisterMetricsOptions struct {
 	Qm QueryMetrics
 	Tm TransactionMetrics
+	Rm ReplicaMetrics
 }
 
 func (c *ConnectionPool) RegisterMetrics(rmo RegisterMetricsOptions) {
@@ -74,6 +91,10 @@ func (c *ConnectionPool) RegisterMetrics(rmo RegisterMetricsOptions) {
 	if rmo.Tm != nil {
 		c.metrics.tm = rmo.Tm
 	}
+
+	if rmo.Rm != nil {
+		c.metrics.rm = rmo.Rm
+	}
 }
 
 func afterConnWithMet(cm ConnectionMetrics) func(ctx context.Context, connCfg *pgx.Conn) error {
@@ -95,16 +116,57 @@ func NewConnectionPool(
 	log logger.Logger,
 	cm ConnectionMetrics,
 ) (*ConnectionPool, error) {
-	connConfig, err := pgx.ParseConfig(cfg.ConnectionURL)
+	connPool, err := newPool(ctx, cfg.ConnectionURL, cfg, cm)
+	if err != nil {
+		return nil, err
+	}
+
+	c := ConnectionPool{
+		pool:              connPool,
+		log:               log,
+		tracer:            defaultTracer(),
+		queryLog:          newQueryLog(cfg),
+		queryTimeout:      cfg.QueryTimeout,
+		txMaxAttempts:     max(cfg.TxMaxAttempts, 1),
+		maxReplicationLag: cfg.MaxReplicationLag,
+	}
+
+	for i, url := range cfg.ReplicaURLs {
+		replicaPool, err := newPool(ctx, url, cfg, cm)
+		if err != nil {
+			connPool.Close()
+			c.closeReplicas()
+			return nil, err
+		}
+
+		c.replicas = append(c.replicas, &replica{
+			name: replicaTargetPrefix + strconv.Itoa(i+1),
+			pool: replicaPool,
+		})
+	}
+
+	if len(c.replicas) > 0 {
+		c.checkReplicas(ctx, true)
+		go c.runReplicaChecks(ctx, cfg.ReplicaCheckPeriod)
+	}
+
+	return &c, nil
+}
+
+func newPool(ctx context.Context, connectionURL string, cfg Config, cm ConnectionMetrics) (*pgxpool.Pool, error) {
+	connConfig, err := pgx.ParseConfig(connectionURL)
 	if err != nil {
-		return nil, fmt.Errorf("conn ParseConfig %s : %v", cfg.ConnectionURL, err)
+		return nil, fmt.Errorf("conn ParseConfig %s : %v", connectionURL, err)
 	}
 
 	connConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+	if _, ok := connConfig.RuntimeParams[applicationNameParam]; !ok && cfg.ApplicationName != "" {
+		connConfig.RuntimeParams[applicationNameParam] = cfg.ApplicationName
+	}
 
-	poolCfg, err := pgxpool.ParseConfig(cfg.ConnectionURL)
+	poolCfg, err := pgxpool.ParseConfig(connectionURL)
 	if err != nil {
-		return nil, fmt.Errorf("conn pool ParseConfig %s : %v", cfg.ConnectionURL, err)
+		return nil, fmt.Errorf("conn pool ParseConfig %s : %v", connectionURL, err)
 	}
 	poolCfg.ConnConfig = connConfig
 	poolCfg.MaxConnLifetime = cfg.MaxConnLifetime
@@ -115,74 +177,135 @@ func NewConnectionPool(
 
 	poolCfg.AfterConnect = afterConnWithMet(cm)
 	poolCfg.BeforeClose = beforeCloseWithMet(cm)
+	poolCfg.BeforeAcquire = tagConnection(connConfig.RuntimeParams[applicationNameParam])
 
 	connPool, err := pgxpool.NewWithConfig(ctx, poolCfg)
 	if err != nil {
-		return nil, fmt.Errorf("unable to connect to database %s : %v", cfg.ConnectionURL, err)
+		return nil, fmt.Errorf("unable to connect to database %s : %v", connectionURL, err)
 	}
 
-	c := ConnectionPool{
-		pool:         connPool,
-		log:          log,
-		queryTimeout: cfg.QueryTimeout,
+	return connPool, nil
+}
+
+// WithTransaction runs f in a transaction with the options. The transaction failing on a serialization failure
+// or a deadlock is run again, up to MaxAttempts times, so f must not have side effects outside the transaction.
+// With a transaction in the context f runs in its savepoint, see RunInTransaction.
+func (c *ConnectionPool) WithTransaction(ctx context.Context, name string, txOptions TxOptions, f func(tx ConnectionTx) error) (context.CancelFunc, error) {
+	return c.withTransaction(ctx, name, txOptions, func(_ context.Context, tx *Transaction) error {
+		return f(tx)
+	})
+}
+
+// withTransaction passes f the context of the transaction span, the queries of f are its children
+func (c *ConnectionPool) withTransaction(ctx context.Context, name string, txOptions TxOptions, f func(ctx context.Context, tx *Transaction) error) (cancel context.CancelFunc, err error) {
+	if tx, ok := txFromContext(ctx); ok {
+		return func() {}, tx.withSavepoint(ctx, name, f)
 	}
 
-	return &c, nil
+	ctx, span := c.startTransactionSpan(ctx, name)
+	attempt := 1
+	defer func() {
+		span.SetAttributes(txAttemptsAttr.Int(attempt))
+		endTransactionSpan(span, err)
+	}()
+
+	spanCtx := ctx
+	run := func(tx *Transaction) error {
+		return f(spanCtx, tx)
+	}
+	ctx, cancel = context.WithTimeout(ctx, c.queryTimeout)
+
+	maxAttempts := txOptions.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = c.txMaxAttempts
+	}
+
+	for ; ; attempt++ {
+		err = c.runTransaction(ctx, name, txOptions, run)
+		if err == nil || attempt >= maxAttempts || !isRetryableTxError(err) {
+			return cancel, err
+		}
+
+		if c.metrics.tm != nil {
+			c.metrics.tm.IncTransactionCounter(transactionRetry, name)
+		}
+		span.AddEvent(transactionRetry)
+		c.log.WithContext(ctx).WarnWithMetadata("retrying transaction", map[string]any{
+			"error":   err.Error(),
+			"name":    name,
+			"attempt": attempt,
+		})
+
+		select {
+		case <-ctx.Done():
+			return cancel, err
+		case <-time.After(txRetryBackoff(attempt)):
+		}
+	}
 }
 
-func (c *ConnectionPool) WithTransaction(ctx context.Context, name string, txOptions TxOptions, f func(tx ConnectionTx) error) (context.CancelFunc, error) {
+func (c *ConnectionPool) runTransaction(ctx context.Context, name string, txOptions TxOptions, f func(tx *Transaction) error) error {
 	start := time.Now()
 
-	ctx, cancel := context.WithTimeout(ctx, c.queryTimeout)
-	tx, err := c.pool.BeginTx(ctx, pgx.TxOptions{})
-
+	pool, r := c.txPool(txOptions)
+	tx, err := pool.BeginTx(ctx, pgxTxOptions(txOptions))
+	if err != nil && c.fallBack(ctx, r, err) {
+		r = nil
+		tx, err = c.pool.BeginTx(ctx, pgxTxOptions(txOptions))
+	}
+	c.incQueryTarget(r)
 	if err != nil {
-		c.log.ErrorWithMetadata("unable to start transaction", map[string]any{
+		c.log.WithContext(ctx).ErrorWithMetadata("unable to start transaction", map[string]any{
 			"error": err.Error(),
 			"name":  name,
 		})
-		return cancel, err
+		return err
 	}
 
 	tErr := f(&Transaction{
-		tx: &tx,
-		c:  c,
+		tx:      &tx,
+		c:       c,
+		replica: r,
 	})
 
 	if tErr != nil {
 		if c.metrics.tm != nil {
 			c.metrics.tm.IncTransactionCounter(transactionRollback, name)
 		}
-		c.log.ErrorWithMetadata("unexpected error during rollback", map[string]any{
+		c.log.WithContext(ctx).ErrorWithMetadata("unexpected error during rollback", map[string]any{
 			"error": tErr.Error(),
 			"name":  name,
 		})
 
 		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil && !errors.Is(rollbackErr, pgx.ErrTxClosed) {
-			c.log.ErrorWithMetadata("unexpected error during rollback", map[string]any{
+			c.log.WithContext(ctx).ErrorWithMetadata("unexpected error during rollback", map[string]any{
 				"error": rollbackErr.Error(),
 				"name":  name,
 			})
-			return cancel, rollbackErr
+			return rollbackErr
 		}
 
-		return cancel, tErr
-	} else {
+		return tErr
+	}
+
+	// serializable transactions fail on the commit when they conflict
+	err = tx.Commit(ctx)
+	if err != nil {
 		if c.metrics.tm != nil {
-			c.metrics.tm.IncTransactionCounter(transactionCommit, name)
+			c.metrics.tm.IncTransactionCounter(transactionRollback, name)
 		}
+		return err
 	}
 
-	diff := time.Since(start)
-
 	if c.metrics.tm != nil {
-		c.metrics.tm.ObserveTransactionDurationHistogram(diff.Seconds(), name)
+		c.metrics.tm.IncTransactionCounter(transactionCommit, name)
+		c.metrics.tm.ObserveTransactionDurationHistogram(time.Since(start).Seconds(), name)
 	}
 
-	c.log.InfoWithMetadata("transaction success", map[string]any{
+	c.log.WithContext(ctx).DebugWithMetadata("transaction success", map[string]any{
 		"name": name,
 	})
-	return cancel, tx.Commit(ctx)
+	return nil
 }
 
 func (c *ConnectionPool) Query(
@@ -190,56 +313,32 @@ func (c *ConnectionPool) Query(
 	dbFuncName string,
 	sql string,
 	namedArgs pgx.NamedArgs,
-) (rows *pgx.Rows, cancel context.CancelFunc, err error) {
+) (*pgx.Rows, context.CancelFunc, error) {
 	start := time.Now()
-	defer func() {
-		diff := time.Since(start)
-		if err != nil {
-			if c.metrics.qm != nil {
-				c.metrics.qm.IncQueryCounter(queryError, dbFuncName)
-			}
-			c.log.ErrorWithMetadata("pg query error", map[string]any{
-				"error": err.Error(),
-				"func":  dbFuncName,
-				"sql":   sql,
-			})
-		} else {
-			if c.metrics.qm != nil {
-				c.metrics.qm.ObserveQueryDurationHistogram(diff.Seconds(), dbFuncName)
-				c.metrics.qm.IncQueryCounter(querySuccess, dbFuncName)
-			}
-			c.log.InfoWithMetadata("pg query success", map[string]any{
-				"func": dbFuncName,
-				"sql":  sql,
-			})
-		}
-	}()
-
-	ctx, cancel = context.WithTimeout(ctx, c.queryTimeout)
+	ctx = c.startQuerySpan(ctx, dbFuncName)
 
-	r, err := c.pool.Query(ctx, sql, namedArgs)
-	if err != nil {
-		if c.metrics.qm != nil {
-			c.metrics.qm.IncQueryCounter(queryError, dbFuncName)
-		}
+	ctx, cancel := context.WithTimeout(ctx, c.queryTimeout)
 
-		return nil, cancel, err
+	q, target := c.reader(ctx)
+	r, err := q.Query(ctx, sql, namedArgs)
+	if err != nil && c.fallBack(ctx, target, err) {
+		target = nil
+		r, err = c.pool.Query(ctx, sql, namedArgs)
 	}
-
-	err = r.Err()
-	if err != nil {
-		return
+	c.incQueryTarget(target)
+	if err == nil {
+		err = r.Err()
 	}
+	if err != nil {
+		c.observeQuery(ctx, dbFuncName, sql, namedArgs, start, 0, err)
 
-	diff := time.Since(start)
-	if c.metrics.qm != nil {
-		c.metrics.qm.ObserveQueryDurationHistogram(diff.Seconds(), dbFuncName)
-		c.metrics.qm.IncQueryCounter(querySuccess, dbFuncName)
+		return nil, cancel, err
 	}
 
-	return &r, cancel, nil
+	return c.instrumentRows(ctx, r, dbFuncName, sql, namedArgs, start), cancel, nil
 }
 
+// QueryRow is observed when the row is scanned, a row of a replica which fails on the connection is read from the primary
 func (c *ConnectionPool) QueryRow(
 	ctx context.Context,
 	dbFuncName string,
@@ -247,20 +346,101 @@ func (c *ConnectionPool) QueryRow(
 	namedArgs pgx.NamedArgs,
 ) (*pgx.Row, context.CancelFunc) {
 	start := time.Now()
+	ctx = c.startQuerySpan(ctx, dbFuncName)
 
 	ctx, cancel := context.WithTimeout(ctx, c.queryTimeout)
 
-	r := c.pool.QueryRow(ctx, sql, namedArgs)
+	q, target := c.reader(ctx)
+	r := q.QueryRow(ctx, sql, namedArgs)
+	if target != nil && !InTransaction(ctx) {
+		r = &replicaRow{
+			ctx:     ctx,
+			c:       c,
+			replica: target,
+			row:     r,
+			sql:     sql,
+			args:    namedArgs,
+		}
+	} else {
+		c.incQueryTarget(target)
+	}
+
+	return c.instrumentRow(ctx, r, dbFuncName, sql, namedArgs, start), cancel
+}
+
+// cursorName is unique within the transaction of QueryCursor
+const cursorName = "query_cursor"
 
-	diff := time.Since(start)
-	if c.metrics.qm != nil {
-		c.metrics.qm.ObserveQueryDurationHistogram(diff.Seconds(), dbFuncName)
-		c.metrics.qm.IncQueryCounter(querySuccess, dbFuncName)
+// QueryCursor declares a cursor for the query in a read only transaction, which may run on a replica, or a savepoint of the ambient transaction,
+// and fetches fetchSize rows at a time, so only one batch of rows is held in memory. The query runs until all rows are fetched, f fails or the context is done.
+func (c *ConnectionPool) QueryCursor(
+	ctx context.Context,
+	dbFuncName string,
+	sql string,
+	namedArgs pgx.NamedArgs,
+	fetchSize int,
+	f func(row Row) error,
+) (err error) {
+	start := time.Now()
+	ctx = c.startQuerySpan(ctx, dbFuncName)
+	var fetchedRows int64
+	defer func() {
+		c.observeQuery(ctx, dbFuncName, sql, namedArgs, start, fetchedRows, err)
+	}()
+
+	var tx pgx.Tx
+	if ambient, ok := txFromContext(ctx); ok {
+		tx, err = (*ambient.tx).Begin(ctx)
+	} else {
+		pool, r := c.txPool(TxOptions{AccessMode: ReadOnly})
+		tx, err = pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+		if err != nil && c.fallBack(ctx, r, err) {
+			r = nil
+			tx, err = c.pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+		}
+		c.incQueryTarget(r)
 	}
+	if err != nil {
+		return err
+	}
+	// the cursor only reads, the rollback closes it
+	defer func() {
+		if rollbackErr := tx.Rollback(context.WithoutCancel(ctx)); rollbackErr != nil && !errors.Is(rollbackErr, pgx.ErrTxClosed) {
+			c.log.WithContext(ctx).ErrorWithMetadata("unexpected error during rollback", map[string]any{
+				"error": rollbackErr.Error(),
+				"name":  dbFuncName,
+			})
+		}
+	}()
 
-	c.log.InfoWithMetadata("pg query operation", map[string]any{
-		"func": dbFuncName,
-		"sql":  sql,
-	})
-	return &r, cancel
+	_, err = tx.Exec(ctx, "DECLARE "+cursorName+" NO SCROLL CURSOR FOR "+sql, namedArgs)
+	if err != nil {
+		return err
+	}
+
+	fetchSQL := "FETCH FORWARD " + strconv.Itoa(fetchSize) + " FROM " + cursorName
+	for {
+		rows, err := tx.Query(ctx, fetchSQL)
+		if err != nil {
+			return err
+		}
+
+		fetched := 0
+		for rows.Next() {
+			fetched++
+			fetchedRows++
+			err = f(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if fetched < fetchSize {
+			return nil
+		}
+	}
 }
@@ -0,0 +1,55 @@
+package pgx
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type txKey struct{}
+
+// dbtx is implemented by both the pool and the transaction
+type dbtx interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// withTx stores the ambient transaction, the context must not be used concurrently as the transaction is not safe for it
+func withTx(ctx context.Context, tx *Transaction) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+func txFromContext(ctx context.Context) (*Transaction, bool) {
+	tx, ok := ctx.Value(txKey{}).(*Transaction)
+	return tx, ok
+}
+
+// InTransaction tells whether the context carries a transaction started by RunInTransaction
+func InTransaction(ctx context.Context) bool {
+	_, ok := txFromContext(ctx)
+	return ok
+}
+
+// dbtx returns the ambient transaction of the context or the pool when there is none
+func (c *ConnectionPool) dbtx(ctx context.Context) dbtx {
+	if tx, ok := txFromContext(ctx); ok {
+		return *tx.tx
+	}
+
+	return c.pool
+}
+
+// RunInTransaction runs f as a unit of work, every query of the connection made with the context passed to f
+// joins the transaction. Nested units of work and transactions run in savepoints of the ambient transaction.
+func (c *ConnectionPool) RunInTransaction(ctx context.Context, name string, txOptions TxOptions, f func(ctx context.Context) error) error {
+	cancel, err := c.withTransaction(ctx, name, txOptions, func(ctx context.Context, tx *Transaction) error {
+		return f(withTx(ctx, tx))
+	})
+	defer cancel()
+
+	return err
+}
@@ -0,0 +1,35 @@
+package pgx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTx struct {
+	pgx.Tx
+}
+
+func TestWithTx(t *testing.T) {
+	c := newReplicaTestPool(true)
+	ctx := context.Background()
+	assert.False(t, InTransaction(ctx))
+	assert.Same(t, c.pool, c.dbtx(ctx))
+
+	var pgxTx pgx.Tx = &fakeTx{}
+	tx := &Transaction{tx: &pgxTx, c: c, replica: c.replicas[0]}
+	ctx = withTx(ctx, tx)
+	assert.True(t, InTransaction(ctx))
+	assert.Same(t, pgxTx, c.dbtx(ctx))
+
+	// the queries join the ambient transaction even when they could read from a replica
+	q, r := c.reader(ReadFromReplica(ctx))
+	assert.Same(t, pgxTx, q)
+	assert.Same(t, c.replicas[0], r)
+
+	ambient, ok := txFromContext(ctx)
+	assert.True(t, ok)
+	assert.Same(t, tx, ambient)
+}
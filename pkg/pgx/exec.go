@@ -0,0 +1,115 @@
+package pgx
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type Batch = pgx.Batch
+
+type BatchResults = pgx.BatchResults
+
+// Exec runs a statement which returns no rows and returns the number of affected rows
+func (c *ConnectionPool) Exec(
+	ctx context.Context,
+	dbFuncName string,
+	sql string,
+	namedArgs pgx.NamedArgs,
+) (rowsAffected int64, err error) {
+	start := time.Now()
+	ctx = c.startQuerySpan(ctx, dbFuncName)
+	defer func() {
+		c.observeQuery(ctx, dbFuncName, sql, namedArgs, start, rowsAffected, err)
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, c.queryTimeout)
+	defer cancel()
+
+	tag, err := c.dbtx(ctx).Exec(ctx, sql, namedArgs)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// SendBatch sends the queued statements in one round-trip, f reads their results in the order they were queued.
+// The results f leaves unread are read before SendBatch returns, f may be nil.
+func (c *ConnectionPool) SendBatch(
+	ctx context.Context,
+	dbFuncName string,
+	batch *Batch,
+	f func(br BatchResults) error,
+) (err error) {
+	start := time.Now()
+	ctx = c.startQuerySpan(ctx, dbFuncName)
+	defer func() {
+		c.observeQuery(ctx, dbFuncName, batchSQL(batch), nil, start, unknownRows, err)
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, c.queryTimeout)
+	defer cancel()
+
+	return readBatch(c.dbtx(ctx).SendBatch(ctx, batch), f)
+}
+
+func (t *Transaction) Exec(
+	ctx context.Context,
+	dbFuncName string,
+	sql string,
+	namedArgs pgx.NamedArgs,
+) (rowsAffected int64, err error) {
+	start := time.Now()
+	ctx = t.c.startQuerySpan(ctx, dbFuncName)
+	defer func() {
+		t.c.observeQuery(ctx, dbFuncName, sql, namedArgs, start, rowsAffected, err)
+	}()
+
+	tag, err := (*t.tx).Exec(ctx, sql, namedArgs)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func (t *Transaction) SendBatch(
+	ctx context.Context,
+	dbFuncName string,
+	batch *Batch,
+	f func(br BatchResults) error,
+) (err error) {
+	start := time.Now()
+	ctx = t.c.startQuerySpan(ctx, dbFuncName)
+	defer func() {
+		t.c.observeQuery(ctx, dbFuncName, batchSQL(batch), nil, start, unknownRows, err)
+	}()
+
+	return readBatch((*t.tx).SendBatch(ctx, batch), f)
+}
+
+// readBatch closes the results also when f fails, otherwise the connection stays busy
+func readBatch(br pgx.BatchResults, f func(br BatchResults) error) error {
+	if f != nil {
+		err := f(br)
+		if err != nil {
+			_ = br.Close()
+			return err
+		}
+	}
+
+	return br.Close()
+}
+
+// batchSQL joins the statements of the batch for the log
+func batchSQL(batch *Batch) string {
+	sqls := make([]string, 0, batch.Len())
+	for _, q := range batch.QueuedQueries {
+		sqls = append(sqls, strings.TrimSpace(q.SQL))
+	}
+
+	return strings.Join(sqls, "\n")
+}
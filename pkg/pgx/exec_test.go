@@ -0,0 +1,63 @@
+package pgx
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeBatchResults struct {
+	closed   bool
+	closeErr error
+}
+
+func (b *fakeBatchResults) Exec() (pgconn.CommandTag, error) {
+	return pgconn.NewCommandTag("INSERT 0 1"), nil
+}
+
+func (b *fakeBatchResults) Query() (pgx.Rows, error) {
+	return nil, nil
+}
+
+func (b *fakeBatchResults) QueryRow() pgx.Row {
+	return nil
+}
+
+func (b *fakeBatchResults) Close() error {
+	b.closed = true
+	return b.closeErr
+}
+
+func TestBatchSQL(t *testing.T) {
+	batch := &Batch{}
+	batch.Queue("\nINSERT INTO a (id) VALUES (@id);\n", NamedArgs{"id": 1})
+	batch.Queue("DELETE FROM b;")
+
+	assert.Equal(t, "INSERT INTO a (id) VALUES (@id);\nDELETE FROM b;", batchSQL(batch))
+	assert.Equal(t, "", batchSQL(&Batch{}))
+}
+
+func TestReadBatch(t *testing.T) {
+	br := &fakeBatchResults{}
+	err := readBatch(br, func(br BatchResults) error {
+		tag, err := br.Exec()
+		assert.Equal(t, int64(1), tag.RowsAffected())
+		return err
+	})
+	assert.NoError(t, err)
+	assert.True(t, br.closed)
+
+	fErr := errors.New("read failed")
+	br = &fakeBatchResults{closeErr: errors.New("close failed")}
+	err = readBatch(br, func(br BatchResults) error {
+		return fErr
+	})
+	assert.ErrorIs(t, err, fErr)
+	assert.True(t, br.closed)
+
+	br = &fakeBatchResults{closeErr: errors.New("close failed")}
+	assert.EqualError(t, readBatch(br, nil), "close failed")
+}
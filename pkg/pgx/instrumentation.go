@@ -0,0 +1,204 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
+)
+
+const (
+	// sqlStateSuccess and sqlStateNoData are the SQLSTATE codes of successful_completion and no_data
+	sqlStateSuccess = "00000"
+	sqlStateNoData  = "02000"
+	// sqlStateClient labels the errors which didn't come from the server, such as timeouts and broken connections
+	sqlStateClient = "client"
+)
+
+// instrumentedRow observes the query when it is scanned, only then its result is known
+type instrumentedRow struct {
+	ctx        context.Context
+	row        pgx.Row
+	c          *ConnectionPool
+	dbFuncName string
+	sql        string
+	args       pgx.NamedArgs
+	start      time.Time
+}
+
+func (r *instrumentedRow) Scan(dest ...any) error {
+	err := r.row.Scan(dest...)
+
+	var rows int64
+	if err == nil {
+		rows = 1
+	}
+	r.c.observeQuery(r.ctx, r.dbFuncName, r.sql, r.args, r.start, rows, err)
+
+	return err
+}
+
+// instrumentedRows observes the query once its rows are read or closed, unless the rows are scanned
+// by a helper which observes it with the error of the scan
+type instrumentedRows struct {
+	pgx.Rows
+	ctx        context.Context
+	c          *ConnectionPool
+	dbFuncName string
+	sql        string
+	args       pgx.NamedArgs
+	start      time.Time
+	read       int64
+	observed   bool
+	scanned    bool
+}
+
+func (r *instrumentedRows) Next() bool {
+	if r.Rows.Next() {
+		r.read++
+		return true
+	}
+
+	if !r.scanned {
+		r.observe(r.Rows.Err())
+	}
+	return false
+}
+
+func (r *instrumentedRows) Close() {
+	r.Rows.Close()
+	if !r.scanned {
+		r.observe(r.Rows.Err())
+	}
+}
+
+func (r *instrumentedRows) observe(err error) {
+	if r.observed {
+		return
+	}
+	r.observed = true
+
+	r.c.observeQuery(r.ctx, r.dbFuncName, r.sql, r.args, r.start, r.read, err)
+}
+
+// observeScan defers the observation of the instrumented rows to the returned func, which takes the error of the scan.
+// The error of the rows is observed when the scan succeeded but the rows failed.
+func observeScan(rows pgx.Rows) func(err error) {
+	r, ok := rows.(*instrumentedRows)
+	if !ok {
+		return func(error) {}
+	}
+	r.scanned = true
+
+	return func(err error) {
+		if err == nil {
+			err = r.Rows.Err()
+		}
+		r.observe(err)
+	}
+}
+
+func (c *ConnectionPool) instrumentRow(ctx context.Context, row pgx.Row, dbFuncName string, sql string, args pgx.NamedArgs, start time.Time) *pgx.Row {
+	var r pgx.Row = &instrumentedRow{
+		ctx:        ctx,
+		row:        row,
+		c:          c,
+		dbFuncName: dbFuncName,
+		sql:        sql,
+		args:       args,
+		start:      start,
+	}
+
+	return &r
+}
+
+func (c *ConnectionPool) instrumentRows(ctx context.Context, rows pgx.Rows, dbFuncName string, sql string, args pgx.NamedArgs, start time.Time) *pgx.Rows {
+	var r pgx.Rows = &instrumentedRows{
+		Rows:       rows,
+		ctx:        ctx,
+		c:          c,
+		dbFuncName: dbFuncName,
+		sql:        sql,
+		args:       args,
+		start:      start,
+	}
+
+	return &r
+}
+
+// queryResult is the result label and the SQLSTATE label of the finished query
+func queryResult(err error) (string, string) {
+	if err == nil {
+		return querySuccess, sqlStateSuccess
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return queryNoRows, sqlStateNoData
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && isSQLState(pgErr.Code) {
+		return queryError, pgErr.Code
+	}
+
+	return queryError, sqlStateClient
+}
+
+// isSQLState keeps the label bounded by the codes defined by postgres
+func isSQLState(code string) bool {
+	if len(code) != 5 {
+		return false
+	}
+
+	for _, ch := range code {
+		if (ch < '0' || ch > '9') && (ch < 'A' || ch > 'Z') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// observeQuery records the finished query, the duration is observed only for the queries which completed.
+// Queries slower than the threshold are logged at warn, the other successful ones at debug. The query span
+// of the context is ended with the rows, which are unknownRows when they aren't known.
+func (c *ConnectionPool) observeQuery(ctx context.Context, dbFuncName string, sql string, args pgx.NamedArgs, start time.Time, rows int64, err error) {
+	duration := time.Since(start)
+	result, sqlState := queryResult(err)
+	endQuerySpan(ctx, sql, rows, result, sqlState, err)
+
+	if c.metrics.qm != nil {
+		if result != queryError {
+			c.metrics.qm.ObserveQueryDurationHistogram(duration.Seconds(), dbFuncName)
+		}
+		c.metrics.qm.IncQueryCounter(result, dbFuncName, sqlState)
+	}
+
+	log := c.log.WithContext(ctx)
+	slow := c.queryLog.slowQueryThreshold > 0 && duration >= c.queryLog.slowQueryThreshold
+	switch {
+	case result == queryError:
+		if !c.queryLog.enabled(logger.ErrorLevel) {
+			return
+		}
+		metadata := c.queryLog.metadata(dbFuncName, sql, args, duration)
+		metadata["error"] = err.Error()
+		metadata["sqlstate"] = sqlState
+		log.ErrorWithMetadata("pg query error", metadata)
+	case slow:
+		if !c.queryLog.enabled(logger.WarnLevel) {
+			return
+		}
+		log.WarnWithMetadata("pg slow query", c.queryLog.metadata(dbFuncName, sql, args, duration))
+	default:
+		if !c.queryLog.enabled(logger.DebugLevel) {
+			return
+		}
+		metadata := c.queryLog.metadata(dbFuncName, sql, args, duration)
+		metadata["result"] = result
+		log.DebugWithMetadata("pg query success", metadata)
+	}
+}
@@ -0,0 +1,213 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingQueryMetrics struct {
+	counters  [][]string
+	durations int
+}
+
+func (m *recordingQueryMetrics) ObserveQueryDurationHistogram(_ float64, _ ...string) {
+	m.durations++
+}
+
+func (m *recordingQueryMetrics) IncQueryCounter(labels ...string) {
+	m.counters = append(m.counters, labels)
+}
+
+type fakeRow struct {
+	err error
+}
+
+func (r fakeRow) Scan(_ ...any) error {
+	return r.err
+}
+
+// fakeRows has the id column, numbered from 1, unless the columns are given
+type fakeRows struct {
+	pgx.Rows
+	left    int
+	read    int
+	columns []string
+	err     error
+	scanErr error
+	closed  bool
+}
+
+func (r *fakeRows) FieldDescriptions() []pgconn.FieldDescription {
+	if r.columns == nil {
+		return []pgconn.FieldDescription{{Name: "id"}}
+	}
+
+	fields := make([]pgconn.FieldDescription, 0, len(r.columns))
+	for _, column := range r.columns {
+		fields = append(fields, pgconn.FieldDescription{Name: column})
+	}
+	return fields
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	if r.scanErr != nil {
+		return r.scanErr
+	}
+	*dest[0].(*int) = r.read
+
+	return nil
+}
+
+func (r *fakeRows) Next() bool {
+	if r.left == 0 {
+		r.closed = true
+		return false
+	}
+	r.left--
+	r.read++
+
+	return true
+}
+
+func (r *fakeRows) Close() {
+	r.closed = true
+}
+
+func (r *fakeRows) Err() error {
+	return r.err
+}
+
+// fakeQuerier hands the fake rows to the scan helpers the way the pool does
+type fakeQuerier struct {
+	c    *ConnectionPool
+	rows pgx.Rows
+}
+
+func (q fakeQuerier) queryRows(ctx context.Context, dbFuncName string, sql string, namedArgs pgx.NamedArgs) (pgx.Rows, func(err error), error) {
+	rows := *q.c.instrumentRows(ctx, q.rows, dbFuncName, sql, namedArgs, time.Now())
+
+	return rows, observeScan(rows), nil
+}
+
+type fakeRecord struct {
+	ID int `db:"id"`
+}
+
+func newInstrumentationTestPool() (*ConnectionPool, *recordingQueryMetrics) {
+	qm := &recordingQueryMetrics{}
+	c := &ConnectionPool{log: logger.New(logger.ParseLevel("fatal"), false)}
+	c.RegisterMetrics(RegisterMetricsOptions{Qm: qm})
+
+	return c, qm
+}
+
+func TestQueryResult(t *testing.T) {
+	for name, tc := range map[string]struct {
+		err      error
+		result   string
+		sqlState string
+	}{
+		"success":   {nil, querySuccess, sqlStateSuccess},
+		"no rows":   {pgx.ErrNoRows, queryNoRows, sqlStateNoData},
+		"wrapped":   {errors.Join(errors.New("get"), pgx.ErrNoRows), queryNoRows, sqlStateNoData},
+		"server":    {&pgconn.PgError{Code: "23505"}, queryError, "23505"},
+		"bad code":  {&pgconn.PgError{Code: "not a code"}, queryError, sqlStateClient},
+		"client":    {context.DeadlineExceeded, queryError, sqlStateClient},
+		"lowercase": {&pgconn.PgError{Code: "2350a"}, queryError, sqlStateClient},
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, sqlState := queryResult(tc.err)
+			assert.Equal(t, tc.result, result)
+			assert.Equal(t, tc.sqlState, sqlState)
+		})
+	}
+}
+
+func TestInstrumentedRow_ObservesOnScan(t *testing.T) {
+	c, qm := newInstrumentationTestPool()
+
+	r := c.instrumentRow(context.Background(), fakeRow{err: pgx.ErrNoRows}, "GetClient", "SELECT 1", nil, time.Now())
+	assert.Empty(t, qm.counters)
+
+	assert.ErrorIs(t, (*r).Scan(), pgx.ErrNoRows)
+	assert.Equal(t, [][]string{{queryNoRows, "GetClient", sqlStateNoData}}, qm.counters)
+	assert.Equal(t, 1, qm.durations)
+
+	r = c.instrumentRow(context.Background(), fakeRow{err: &pgconn.PgError{Code: "23505"}}, "CreateClient", "INSERT", nil, time.Now())
+	assert.Error(t, (*r).Scan())
+	assert.Equal(t, []string{queryError, "CreateClient", "23505"}, qm.counters[1])
+	assert.Equal(t, 1, qm.durations)
+}
+
+func TestInstrumentedRows_ObservesOnce(t *testing.T) {
+	c, qm := newInstrumentationTestPool()
+
+	rows := &fakeRows{left: 2}
+	r := c.instrumentRows(context.Background(), rows, "ListClients", "SELECT", nil, time.Now())
+	for (*r).Next() {
+		assert.Empty(t, qm.counters)
+	}
+	(*r).Close()
+	assert.Equal(t, [][]string{{querySuccess, "ListClients", sqlStateSuccess}}, qm.counters)
+
+	rows = &fakeRows{left: 1, err: &pgconn.PgError{Code: "57014"}}
+	r = c.instrumentRows(context.Background(), rows, "ListClients", "SELECT", nil, time.Now())
+	(*r).Close()
+	(*r).Close()
+	assert.True(t, rows.closed)
+	assert.Equal(t, []string{queryError, "ListClients", "57014"}, qm.counters[1])
+	assert.Len(t, qm.counters, 2)
+}
+
+func TestQueryOne_ObservesCollectResult(t *testing.T) {
+	for name, tc := range map[string]struct {
+		rows     *fakeRows
+		err      error
+		result   string
+		sqlState string
+	}{
+		"one row":       {&fakeRows{left: 1}, nil, querySuccess, sqlStateSuccess},
+		"zero rows":     {&fakeRows{}, pgx.ErrNoRows, queryNoRows, sqlStateNoData},
+		"too many rows": {&fakeRows{left: 2}, pgx.ErrTooManyRows, queryError, sqlStateClient},
+		"scan error":    {&fakeRows{left: 1, scanErr: errors.New("cannot scan")}, nil, queryError, sqlStateClient},
+		"rows error":    {&fakeRows{err: &pgconn.PgError{Code: "57014"}}, nil, queryError, "57014"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c, qm := newInstrumentationTestPool()
+
+			v, err := QueryOne[fakeRecord](context.Background(), fakeQuerier{c, tc.rows}, "GetClient", "SELECT", nil)
+			if tc.result == querySuccess {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, v.ID)
+			} else {
+				assert.Error(t, err)
+			}
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+			}
+			assert.True(t, tc.rows.closed)
+			assert.Equal(t, [][]string{{tc.result, "GetClient", tc.sqlState}}, qm.counters)
+		})
+	}
+}
+
+func TestQueryAll_ObservesScanError(t *testing.T) {
+	c, qm := newInstrumentationTestPool()
+
+	_, err := QueryAll[fakeRecord](context.Background(), fakeQuerier{c, &fakeRows{left: 2, scanErr: errors.New("cannot scan")}}, "ListClients", "SELECT", nil)
+	assert.Error(t, err)
+	assert.Equal(t, [][]string{{queryError, "ListClients", sqlStateClient}}, qm.counters)
+
+	v, err := QueryAll[fakeRecord](context.Background(), fakeQuerier{c, &fakeRows{left: 2}}, "ListClients", "SELECT", nil)
+	assert.NoError(t, err)
+	assert.Len(t, v, 2)
+	assert.Equal(t, []string{querySuccess, "ListClients", sqlStateSuccess}, qm.counters[1])
+	assert.Len(t, qm.counters, 2)
+}
@@ -9,14 +9,32 @@ import (
 type querier interface {
 	Query(ctx context.Context, dbFuncName string, sql string, namedArgs pgx.NamedArgs) (*pgx.Rows, context.CancelFunc, error)
 	QueryRow(ctx context.Context, dbFuncName string, sql string, namedArgs pgx.NamedArgs) (*pgx.Row, context.CancelFunc)
+	// Exec runs a statement which returns no rows and returns the number of affected rows
+	Exec(ctx context.Context, dbFuncName string, sql string, namedArgs pgx.NamedArgs) (int64, error)
+	// SendBatch pipelines the statements of the batch in one round-trip, f reads their results in order
+	SendBatch(ctx context.Context, dbFuncName string, batch *Batch, f func(br BatchResults) error) error
 }
 
 type Connection interface {
 	querier
+	Querier
 	WithTransaction(ctx context.Context, name string, txOptions TxOptions, f func(tx ConnectionTx) error) (context.CancelFunc, error)
+	// RunInTransaction runs f in a transaction which is joined by the queries made with the context passed to f
+	RunInTransaction(ctx context.Context, name string, txOptions TxOptions, f func(ctx context.Context) error) error
+	// QueryCursor streams the rows of the query to f through a server-side cursor, the query timeout is not applied
+	QueryCursor(ctx context.Context, dbFuncName string, sql string, namedArgs pgx.NamedArgs, fetchSize int, f func(row Row) error) error
 }
 
 type ConnectionTx interface {
+	Querier
 	Query(ctx context.Context, dbFuncName string, sql string, namedArgs pgx.NamedArgs) (*pgx.Rows, error)
 	QueryRow(ctx context.Context, dbFuncName string, sql string, namedArgs pgx.NamedArgs) *pgx.Row
+	// Exec runs a statement which returns no rows and returns the number of affected rows
+	Exec(ctx context.Context, dbFuncName string, sql string, namedArgs pgx.NamedArgs) (int64, error)
+	// SendBatch pipelines the statements of the batch in one round-trip, f reads their results in order
+	SendBatch(ctx context.Context, dbFuncName string, batch *Batch, f func(br BatchResults) error) error
+	// CopyFrom bulk loads the rows into the table with the COPY protocol and returns the number of copied rows
+	CopyFrom(ctx context.Context, dbFuncName string, table string, columns []string, rows [][]any) (int64, error)
+	// WithTransaction runs f in a savepoint which is rolled back alone when f fails
+	WithTransaction(ctx context.Context, name string, f func(tx ConnectionTx) error) error
 }
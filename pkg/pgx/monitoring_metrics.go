@@ -3,6 +3,7 @@ package pgx
 type MonitoringMetrics struct {
 	qm QueryMetrics
 	tm TransactionMetrics
+	rm ReplicaMetrics
 }
 
 type QueryMetrics interface {
@@ -19,3 +20,10 @@ type ConnectionMetrics interface {
 	IncDbConnGauge()
 	DecDbConnGauge()
 }
+
+type ReplicaMetrics interface {
+	// IncQueryTargetCounter counts the queries and transactions routed to the primary or a replica
+	IncQueryTargetCounter(labels ...string)
+	SetReplicaLagGauge(lag float64, labels ...string)
+	SetReplicaHealthyGauge(healthy float64, labels ...string)
+}
@@ -0,0 +1,105 @@
+package pgx
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type poolStatsMetric struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	value     func(s *pgxpool.Stat) float64
+}
+
+// PoolStatsCollector exports the statistics of the primary pool and the replica pools partitioned by target,
+// they are read from the pools on every scrape
+type PoolStatsCollector struct {
+	c       *ConnectionPool
+	metrics []poolStatsMetric
+}
+
+func NewPoolStatsCollector(c *ConnectionPool, subsystem string) *PoolStatsCollector {
+	desc := func(name string, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName("", subsystem, name), help, []string{"target"}, nil)
+	}
+
+	return &PoolStatsCollector{
+		c: c,
+		metrics: []poolStatsMetric{
+			{
+				desc:      desc("pg_pool_acquired_conns", "Number of connections currently acquired from the pool"),
+				valueType: prometheus.GaugeValue,
+				value:     func(s *pgxpool.Stat) float64 { return float64(s.AcquiredConns()) },
+			},
+			{
+				desc:      desc("pg_pool_idle_conns", "Number of idle connections in the pool"),
+				valueType: prometheus.GaugeValue,
+				value:     func(s *pgxpool.Stat) float64 { return float64(s.IdleConns()) },
+			},
+			{
+				desc:      desc("pg_pool_constructing_conns", "Number of connections being established"),
+				valueType: prometheus.GaugeValue,
+				value:     func(s *pgxpool.Stat) float64 { return float64(s.ConstructingConns()) },
+			},
+			{
+				desc:      desc("pg_pool_total_conns", "Number of connections in the pool, acquired, idle and constructing"),
+				valueType: prometheus.GaugeValue,
+				value:     func(s *pgxpool.Stat) float64 { return float64(s.TotalConns()) },
+			},
+			{
+				desc:      desc("pg_pool_max_conns", "Maximum number of connections in the pool"),
+				valueType: prometheus.GaugeValue,
+				value:     func(s *pgxpool.Stat) float64 { return float64(s.MaxConns()) },
+			},
+			{
+				desc:      desc("pg_pool_acquires_total", "Number of successful acquires from the pool"),
+				valueType: prometheus.CounterValue,
+				value:     func(s *pgxpool.Stat) float64 { return float64(s.AcquireCount()) },
+			},
+			{
+				desc:      desc("pg_pool_acquire_duration_seconds_total", "Total time spent by the successful acquires from the pool"),
+				valueType: prometheus.CounterValue,
+				value:     func(s *pgxpool.Stat) float64 { return s.AcquireDuration().Seconds() },
+			},
+			{
+				desc:      desc("pg_pool_empty_acquires_total", "Number of successful acquires which waited for a connection because the pool was empty"),
+				valueType: prometheus.CounterValue,
+				value:     func(s *pgxpool.Stat) float64 { return float64(s.EmptyAcquireCount()) },
+			},
+			{
+				desc:      desc("pg_pool_canceled_acquires_total", "Number of acquires canceled by their context"),
+				valueType: prometheus.CounterValue,
+				value:     func(s *pgxpool.Stat) float64 { return float64(s.CanceledAcquireCount()) },
+			},
+			{
+				desc:      desc("pg_pool_max_lifetime_destroys_total", "Number of connections closed for exceeding the max connection lifetime"),
+				valueType: prometheus.CounterValue,
+				value:     func(s *pgxpool.Stat) float64 { return float64(s.MaxLifetimeDestroyCount()) },
+			},
+			{
+				desc:      desc("pg_pool_max_idle_destroys_total", "Number of connections closed for exceeding the max connection idle time"),
+				valueType: prometheus.CounterValue,
+				value:     func(s *pgxpool.Stat) float64 { return float64(s.MaxIdleDestroyCount()) },
+			},
+		},
+	}
+}
+
+func (pc *PoolStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range pc.metrics {
+		ch <- m.desc
+	}
+}
+
+func (pc *PoolStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	pc.collect(ch, primaryTarget, pc.c.pool.Stat())
+	for _, r := range pc.c.replicas {
+		pc.collect(ch, r.name, r.pool.Stat())
+	}
+}
+
+func (pc *PoolStatsCollector) collect(ch chan<- prometheus.Metric, target string, s *pgxpool.Stat) {
+	for _, m := range pc.metrics {
+		ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, m.value(s), target)
+	}
+}
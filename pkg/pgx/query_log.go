@@ -0,0 +1,132 @@
+package pgx
+
+import (
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
+)
+
+const redactedArg = "[REDACTED]"
+
+// DefaultRedactedArgs mask the personal data and secrets of the arguments, an argument is masked
+// when its name contains any of them regardless of the case
+var DefaultRedactedArgs = []string{"email", "name", "dateofbirth", "secret", "password", "payload", "body", "rows"}
+
+type queryLog struct {
+	level              logger.Level
+	slowQueryThreshold time.Duration
+	logArgs            bool
+	redactedArgs       []string
+}
+
+func newQueryLog(cfg Config) queryLog {
+	redactedArgs := cfg.RedactedArgs
+	if redactedArgs == nil {
+		redactedArgs = DefaultRedactedArgs
+	}
+
+	lowered := make([]string, 0, len(redactedArgs))
+	for _, arg := range redactedArgs {
+		lowered = append(lowered, strings.ToLower(arg))
+	}
+
+	return queryLog{
+		level:              logger.ParseLevel(cfg.LogLevel),
+		slowQueryThreshold: cfg.SlowQueryThreshold,
+		logArgs:            cfg.LogArgs,
+		redactedArgs:       lowered,
+	}
+}
+
+func (l queryLog) enabled(level logger.Level) bool {
+	return level >= l.level
+}
+
+func (l queryLog) metadata(dbFuncName string, sql string, args pgx.NamedArgs, duration time.Duration) map[string]any {
+	metadata := map[string]any{
+		"func":        dbFuncName,
+		"fingerprint": fingerprint(sql),
+		"duration_ms": duration.Milliseconds(),
+	}
+	if l.logArgs && len(args) > 0 {
+		metadata["args"] = l.redact(args)
+	}
+
+	return metadata
+}
+
+func (l queryLog) redact(args pgx.NamedArgs) map[string]any {
+	redacted := make(map[string]any, len(args))
+	for name, value := range args {
+		redacted[name] = value
+
+		lowered := strings.ToLower(name)
+		for _, arg := range l.redactedArgs {
+			if strings.Contains(lowered, arg) {
+				redacted[name] = redactedArg
+				break
+			}
+		}
+	}
+
+	return redacted
+}
+
+// fingerprint normalizes the query for the log, the literals are replaced by ? and the whitespace is collapsed,
+// so the same query logs the same fingerprint whatever literals it contains
+func fingerprint(sql string) string {
+	var b strings.Builder
+	b.Grow(len(sql))
+
+	var last byte
+	space := false
+	write := func(s string) {
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteString(s)
+		last = s[len(s)-1]
+	}
+
+	for i := 0; i < len(sql); i++ {
+		ch := sql[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			space = true
+		case ch == '\'':
+			// '' escapes the quote inside of the literal
+			for i++; i < len(sql); i++ {
+				if sql[i] != '\'' {
+					continue
+				}
+				if i+1 < len(sql) && sql[i+1] == '\'' {
+					i++
+					continue
+				}
+				break
+			}
+			write("?")
+		case isDigit(ch) && (space || !isIdentChar(last)):
+			for i+1 < len(sql) && (isDigit(sql[i+1]) || sql[i+1] == '.') {
+				i++
+			}
+			write("?")
+		default:
+			write(sql[i : i+1])
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), ";")
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+// isIdentChar keeps the digits of identifiers and parameters such as id1, @p1 or FLOAT8
+func isIdentChar(ch byte) bool {
+	return ch == '_' || ch == '@' || ch == '$' || isDigit(ch) || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
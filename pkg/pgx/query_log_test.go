@@ -0,0 +1,72 @@
+package pgx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprint(t *testing.T) {
+	for sql, expected := range map[string]string{
+		"\nSELECT\n\tname,\n\temail\nFROM\n\tclient\nWHERE\n\tuuid = @uuid;\n": "SELECT name, email FROM client WHERE uuid = @uuid",
+		"SELECT 1 AS ok": "SELECT ? AS ok",
+		"SELECT * FROM client WHERE email = 'a@b.cz'":    "SELECT * FROM client WHERE email = ?",
+		"SELECT 'it''s', 3.14, id1 FROM t LIMIT 10":      "SELECT ?, ?, id1 FROM t LIMIT ?",
+		"SELECT @p1::FLOAT8, $2 FROM t":                  "SELECT @p1::FLOAT8, $2 FROM t",
+		"SELECT NOW() - @retention::INTERVAL":            "SELECT NOW() - @retention::INTERVAL",
+		"INSERT INTO a (id) VALUES (1);\nDELETE FROM b;": "INSERT INTO a (id) VALUES (?); DELETE FROM b",
+	} {
+		assert.Equal(t, expected, fingerprint(sql), sql)
+	}
+}
+
+func TestQueryLog_Redact(t *testing.T) {
+	l := newQueryLog(Config{})
+	assert.Equal(t, map[string]any{
+		"uuid":        "9f0c3c1e-3d9d-4a3b-9d3b-6a5d1b0e4f21",
+		"email":       redactedArg,
+		"emailDomain": redactedArg,
+		"namePrefix":  redactedArg,
+		"dateOfBirth": redactedArg,
+		"limit":       10,
+	}, l.redact(NamedArgs{
+		"uuid":        "9f0c3c1e-3d9d-4a3b-9d3b-6a5d1b0e4f21",
+		"email":       "a@b.cz",
+		"emailDomain": "%@b.cz",
+		"namePrefix":  "Jo%",
+		"dateOfBirth": time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+		"limit":       10,
+	}))
+
+	l = newQueryLog(Config{RedactedArgs: []string{"UUID"}})
+	assert.Equal(t, map[string]any{"uuid": redactedArg, "email": "a@b.cz"}, l.redact(NamedArgs{
+		"uuid":  "9f0c3c1e-3d9d-4a3b-9d3b-6a5d1b0e4f21",
+		"email": "a@b.cz",
+	}))
+}
+
+func TestQueryLog_Metadata(t *testing.T) {
+	args := NamedArgs{"email": "a@b.cz"}
+
+	metadata := newQueryLog(Config{}).metadata("GetClient", "SELECT 1", args, 1500*time.Millisecond)
+	assert.Equal(t, map[string]any{
+		"func":        "GetClient",
+		"fingerprint": "SELECT ?",
+		"duration_ms": int64(1500),
+	}, metadata)
+
+	metadata = newQueryLog(Config{LogArgs: true}).metadata("GetClient", "SELECT 1", args, 0)
+	assert.Equal(t, map[string]any{"email": redactedArg}, metadata["args"])
+}
+
+func TestQueryLog_Enabled(t *testing.T) {
+	l := newQueryLog(Config{LogLevel: "warn"})
+	assert.False(t, l.enabled(logger.DebugLevel))
+	assert.True(t, l.enabled(logger.WarnLevel))
+	assert.True(t, l.enabled(logger.ErrorLevel))
+
+	// the level defaults to info
+	assert.False(t, newQueryLog(Config{}).enabled(logger.DebugLevel))
+}
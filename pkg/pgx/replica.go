@@ -0,0 +1,253 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	primaryTarget       = "primary"
+	replicaTargetPrefix = "replica-"
+
+	defaultReplicaCheckPeriod = 5 * time.Second
+)
+
+type replica struct {
+	name    string
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+}
+
+type replicaReadKey struct{}
+
+// ReadFromReplica marks the queries made with the context as reads which can be served by a replica,
+// they may not see the latest writes. Queries in a transaction of the context run in the transaction.
+func ReadFromReplica(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replicaReadKey{}, true)
+}
+
+func readsFromReplica(ctx context.Context) bool {
+	read, _ := ctx.Value(replicaReadKey{}).(bool)
+	return read
+}
+
+// reader returns where the query of the context runs, the replica is nil for the primary
+func (c *ConnectionPool) reader(ctx context.Context) (dbtx, *replica) {
+	if tx, ok := txFromContext(ctx); ok {
+		return *tx.tx, tx.replica
+	}
+
+	if readsFromReplica(ctx) {
+		if r := c.pickReplica(); r != nil {
+			return r.pool, r
+		}
+	}
+
+	return c.pool, nil
+}
+
+// txPool returns the pool to begin the transaction in, only read only transactions run on a replica
+func (c *ConnectionPool) txPool(txOptions TxOptions) (*pgxpool.Pool, *replica) {
+	if txOptions.AccessMode == ReadOnly && txOptions.BeginQuery == "" {
+		if r := c.pickReplica(); r != nil {
+			return r.pool, r
+		}
+	}
+
+	return c.pool, nil
+}
+
+// pickReplica takes turns among the healthy replicas, it returns nil when none is healthy
+func (c *ConnectionPool) pickReplica() *replica {
+	n := len(c.replicas)
+	if n == 0 {
+		return nil
+	}
+
+	start := c.nextReplica.Add(1)
+	for i := range n {
+		r := c.replicas[(start+uint64(i))%uint64(n)]
+		if r.healthy.Load() {
+			return r
+		}
+	}
+
+	return nil
+}
+
+// fallBack tells whether the failed query of the replica should run on the primary, the replica is not used
+// until the next check when it can't be reached
+func (c *ConnectionPool) fallBack(ctx context.Context, r *replica, err error) bool {
+	if r == nil || !isConnError(ctx, err) {
+		return false
+	}
+
+	c.setReplicaHealthy(r, false)
+	c.log.WithContext(ctx).WarnWithMetadata("pg replica failed, falling back to the primary", map[string]any{
+		"error":   err.Error(),
+		"replica": r.name,
+	})
+
+	return true
+}
+
+// isConnError tells whether the query failed on the connection rather than in the database,
+// a missing row and a failed scan of a row are not connection errors
+func isConnError(ctx context.Context, err error) bool {
+	var pgErr *pgconn.PgError
+	var scanErr pgx.ScanArgError
+	return !errors.As(err, &pgErr) && !errors.As(err, &scanErr) && !errors.Is(err, pgx.ErrNoRows) && ctx.Err() == nil
+}
+
+// replicaRow reads the row from the primary when the replica fails on the connection, the failure is only known
+// when the row is scanned. The query target is counted once the row is read.
+type replicaRow struct {
+	ctx     context.Context
+	c       *ConnectionPool
+	replica *replica
+	row     pgx.Row
+	sql     string
+	args    pgx.NamedArgs
+}
+
+func (r *replicaRow) Scan(dest ...any) error {
+	err := r.row.Scan(dest...)
+	if err != nil && r.c.fallBack(r.ctx, r.replica, err) {
+		r.c.incQueryTarget(nil)
+		return r.c.pool.QueryRow(r.ctx, r.sql, r.args).Scan(dest...)
+	}
+	r.c.incQueryTarget(r.replica)
+
+	return err
+}
+
+func (c *ConnectionPool) incQueryTarget(r *replica) {
+	if c.metrics.rm == nil {
+		return
+	}
+
+	if r == nil {
+		c.metrics.rm.IncQueryTargetCounter(primaryTarget)
+		return
+	}
+	c.metrics.rm.IncQueryTargetCounter(r.name)
+}
+
+func (c *ConnectionPool) setReplicaHealthy(r *replica, healthy bool) {
+	r.healthy.Store(healthy)
+
+	if c.metrics.rm != nil {
+		var v float64
+		if healthy {
+			v = 1
+		}
+		c.metrics.rm.SetReplicaHealthyGauge(v, r.name)
+	}
+}
+
+// ReplicaNames returns the names of the replicas in the order of Config.ReplicaURLs
+func (c *ConnectionPool) ReplicaNames() []string {
+	names := make([]string, 0, len(c.replicas))
+	for _, r := range c.replicas {
+		names = append(names, r.name)
+	}
+
+	return names
+}
+
+// CheckReplica checks the connection and the replication lag of the replica, the queries are routed to it only when it passes
+func (c *ConnectionPool) CheckReplica(ctx context.Context, name string) error {
+	for _, r := range c.replicas {
+		if r.name == name {
+			return c.checkReplica(ctx, r)
+		}
+	}
+
+	return fmt.Errorf("unknown replica %s", name)
+}
+
+func (c *ConnectionPool) checkReplica(ctx context.Context, r *replica) error {
+	ctx, cancel := context.WithTimeout(ctx, c.queryTimeout)
+	defer cancel()
+
+	var lag float64
+	err := r.pool.QueryRow(ctx, replicationLagSQL()).Scan(&lag)
+	if err != nil {
+		c.setReplicaHealthy(r, false)
+		return err
+	}
+
+	if c.metrics.rm != nil {
+		c.metrics.rm.SetReplicaLagGauge(lag, r.name)
+	}
+
+	if c.maxReplicationLag > 0 && lag > c.maxReplicationLag.Seconds() {
+		c.setReplicaHealthy(r, false)
+		return fmt.Errorf("replica %s lags %.1fs behind the primary", r.name, lag)
+	}
+
+	c.setReplicaHealthy(r, true)
+	return nil
+}
+
+// checkReplicas logs only the changes of the replica health, the first check logs every unhealthy replica
+func (c *ConnectionPool) checkReplicas(ctx context.Context, first bool) {
+	for _, r := range c.replicas {
+		wasHealthy := r.healthy.Load()
+		err := c.checkReplica(ctx, r)
+		switch {
+		case err != nil && (wasHealthy || first):
+			c.log.WarnWithMetadata("pg replica is not used", map[string]any{
+				"error":   err.Error(),
+				"replica": r.name,
+			})
+		case err == nil && !wasHealthy:
+			c.log.InfoWithMetadata("pg replica is used", map[string]any{
+				"replica": r.name,
+			})
+		}
+	}
+}
+
+func (c *ConnectionPool) runReplicaChecks(ctx context.Context, period time.Duration) {
+	if period <= 0 {
+		period = defaultReplicaCheckPeriod
+	}
+
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.checkReplicas(ctx, false)
+		}
+	}
+}
+
+func (c *ConnectionPool) closeReplicas() {
+	for _, r := range c.replicas {
+		r.pool.Close()
+	}
+}
+
+// replicationLagSQL is zero when the replica replayed everything it received, so an idle primary doesn't look lagging.
+// The lag of a server which is not a replica is zero.
+func replicationLagSQL() string {
+	return `
+SELECT
+	CASE
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM NOW() - pg_last_xact_replay_timestamp()), 0)
+	END::FLOAT8 AS lag;
+`
+}
@@ -0,0 +1,92 @@
+package pgx
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Querier is the ConnectionPool or a Transaction, the query helpers work with both
+type Querier interface {
+	queryRows(ctx context.Context, dbFuncName string, sql string, namedArgs pgx.NamedArgs) (pgx.Rows, func(err error), error)
+}
+
+// queryRows returns the rows whose query is observed by done with the error of the whole scan, not only of the rows,
+// so ErrNoRows, ErrTooManyRows and the scan errors of the helpers are recorded
+func (c *ConnectionPool) queryRows(ctx context.Context, dbFuncName string, sql string, namedArgs pgx.NamedArgs) (pgx.Rows, func(err error), error) {
+	r, cancel, err := c.Query(ctx, dbFuncName, sql, namedArgs)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+
+	rows := *r
+	done := observeScan(rows)
+	return rows, func(err error) {
+		done(err)
+		cancel()
+	}, nil
+}
+
+func (t *Transaction) queryRows(ctx context.Context, dbFuncName string, sql string, namedArgs pgx.NamedArgs) (pgx.Rows, func(err error), error) {
+	r, err := t.Query(ctx, dbFuncName, sql, namedArgs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return *r, observeScan(*r), nil
+}
+
+// QueryOne scans the only row of the query into T by the db tags of its fields, it returns ErrNoRows when there is no row
+// and an error when there are more of them. Every column must have a field and every field a column.
+func QueryOne[T any](ctx context.Context, q Querier, dbFuncName string, sql string, namedArgs pgx.NamedArgs) (T, error) {
+	rows, done, err := q.queryRows(ctx, dbFuncName, sql, namedArgs)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	v, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[T])
+	done(err)
+
+	return v, err
+}
+
+// QueryAll scans the rows of the query into T by the db tags of its fields, the slice is empty when there is no row
+func QueryAll[T any](ctx context.Context, q Querier, dbFuncName string, sql string, namedArgs pgx.NamedArgs) ([]T, error) {
+	rows, done, err := q.queryRows(ctx, dbFuncName, sql, namedArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := pgx.CollectRows(rows, pgx.RowToStructByName[T])
+	done(err)
+
+	return v, err
+}
+
+// QueryIter scans the rows of the query into T one at a time and passes them to f, the iteration stops when f fails
+func QueryIter[T any](ctx context.Context, q Querier, dbFuncName string, sql string, namedArgs pgx.NamedArgs, f func(v T) error) (err error) {
+	rows, done, err := q.queryRows(ctx, dbFuncName, sql, namedArgs)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		rows.Close()
+		done(err)
+	}()
+
+	for rows.Next() {
+		v, err := pgx.RowToStructByName[T](rows)
+		if err != nil {
+			return err
+		}
+
+		err = f(v)
+		if err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,93 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeNamedRecord struct {
+	ID   int    `db:"id"`
+	Name string `db:"name"`
+}
+
+func TestQueryOne(t *testing.T) {
+	c, _ := newInstrumentationTestPool()
+
+	v, err := QueryOne[fakeRecord](context.Background(), fakeQuerier{c, &fakeRows{left: 1}}, "GetClient", "SELECT", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, fakeRecord{ID: 1}, v)
+
+	_, err = QueryOne[fakeRecord](context.Background(), fakeQuerier{c, &fakeRows{}}, "GetClient", "SELECT", nil)
+	assert.ErrorIs(t, err, ErrNoRows)
+
+	rows := &fakeRows{left: 2}
+	_, err = QueryOne[fakeRecord](context.Background(), fakeQuerier{c, rows}, "GetClient", "SELECT", nil)
+	assert.ErrorIs(t, err, pgx.ErrTooManyRows)
+	assert.True(t, rows.closed)
+}
+
+func TestQueryOne_MatchesColumnsByTag(t *testing.T) {
+	c, _ := newInstrumentationTestPool()
+
+	// the column has no field
+	rows := &fakeRows{left: 1, columns: []string{"id", "email"}}
+	_, err := QueryOne[fakeRecord](context.Background(), fakeQuerier{c, rows}, "GetClient", "SELECT", nil)
+	assert.ErrorContains(t, err, "email")
+	assert.True(t, rows.closed)
+
+	// the field has no column
+	_, err = QueryOne[fakeNamedRecord](context.Background(), fakeQuerier{c, &fakeRows{left: 1}}, "GetClient", "SELECT", nil)
+	assert.ErrorContains(t, err, "name")
+}
+
+func TestQueryAll(t *testing.T) {
+	c, _ := newInstrumentationTestPool()
+
+	v, err := QueryAll[fakeRecord](context.Background(), fakeQuerier{c, &fakeRows{left: 3}}, "ListClients", "SELECT", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []fakeRecord{{ID: 1}, {ID: 2}, {ID: 3}}, v)
+
+	v, err = QueryAll[fakeRecord](context.Background(), fakeQuerier{c, &fakeRows{}}, "ListClients", "SELECT", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, v)
+
+	_, err = QueryAll[fakeRecord](context.Background(), fakeQuerier{c, &fakeRows{left: 1, columns: []string{"id", "email"}}}, "ListClients", "SELECT", nil)
+	assert.ErrorContains(t, err, "email")
+}
+
+func TestQueryIter(t *testing.T) {
+	c, qm := newInstrumentationTestPool()
+
+	var ids []int
+	rows := &fakeRows{left: 3}
+	err := QueryIter[fakeRecord](context.Background(), fakeQuerier{c, rows}, "ExportClients", "SELECT", nil, func(v fakeRecord) error {
+		ids = append(ids, v.ID)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, ids)
+	assert.True(t, rows.closed)
+	assert.Equal(t, [][]string{{querySuccess, "ExportClients", sqlStateSuccess}}, qm.counters)
+}
+
+func TestQueryIter_StopsEarly(t *testing.T) {
+	c, qm := newInstrumentationTestPool()
+
+	stop := errors.New("stop")
+	var ids []int
+	rows := &fakeRows{left: 3}
+	err := QueryIter[fakeRecord](context.Background(), fakeQuerier{c, rows}, "ExportClients", "SELECT", nil, func(v fakeRecord) error {
+		ids = append(ids, v.ID)
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, []int{1}, ids)
+	// the remaining rows are not read, only closed
+	assert.Equal(t, 2, rows.left)
+	assert.True(t, rows.closed)
+	assert.Equal(t, [][]string{{queryError, "ExportClients", sqlStateClient}}, qm.counters)
+}
@@ -0,0 +1,68 @@
+package pgx
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/jamm3e3333/whalebone-go-test-project/pkg/pgx"
+
+const (
+	dbSystemAttr     = attribute.Key("db.system")
+	dbFuncNameAttr   = attribute.Key("db.func_name")
+	fingerprintAttr  = attribute.Key("db.query.fingerprint")
+	rowsAttr         = attribute.Key("db.rows")
+	sqlStateAttr     = attribute.Key("db.response.status_code")
+	txAttemptsAttr   = attribute.Key("db.transaction.attempts")
+	dbSystemPostgres = "postgresql"
+)
+
+// unknownRows is passed for the queries whose number of rows isn't known, e.g. the batches
+const unknownRows = -1
+
+func defaultTracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// startQuerySpan starts the span of the query, observeQuery ends it
+func (c *ConnectionPool) startQuerySpan(ctx context.Context, dbFuncName string) context.Context {
+	ctx, _ = c.tracer.Start(ctx, dbFuncName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(dbSystemAttr.String(dbSystemPostgres), dbFuncNameAttr.String(dbFuncName)),
+	)
+
+	return ctx
+}
+
+// endQuerySpan ends the query span of the context, the no rows error doesn't fail the span
+func endQuerySpan(ctx context.Context, sql string, rows int64, result string, sqlState string, err error) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(fingerprintAttr.String(fingerprint(sql)), sqlStateAttr.String(sqlState))
+	if rows != unknownRows {
+		span.SetAttributes(rowsAttr.Int64(rows))
+	}
+	if result == queryError {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (c *ConnectionPool) startTransactionSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return c.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(dbSystemAttr.String(dbSystemPostgres), dbFuncNameAttr.String(name)),
+	)
+}
+
+func endTransactionSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,98 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTracingTestPool() (*ConnectionPool, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	return &ConnectionPool{
+		log:    logger.New(logger.ParseLevel("fatal"), false),
+		tracer: tp.Tracer(tracerName),
+	}, exporter
+}
+
+func spanAttributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+
+	return attrs
+}
+
+func TestQuerySpan(t *testing.T) {
+	c, exporter := newTracingTestPool()
+
+	ctx := c.startQuerySpan(context.Background(), "GetClient")
+	c.observeQuery(ctx, "GetClient", "SELECT * FROM client WHERE email = 'a@b.cz'", nil, time.Now(), 1, nil)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "GetClient", spans[0].Name)
+	assert.Equal(t, codes.Unset, spans[0].Status.Code)
+
+	attrs := spanAttributes(spans[0])
+	assert.Equal(t, dbSystemPostgres, attrs[dbSystemAttr].AsString())
+	assert.Equal(t, "GetClient", attrs[dbFuncNameAttr].AsString())
+	assert.Equal(t, "SELECT * FROM client WHERE email = ?", attrs[fingerprintAttr].AsString())
+	assert.Equal(t, int64(1), attrs[rowsAttr].AsInt64())
+	assert.Equal(t, sqlStateSuccess, attrs[sqlStateAttr].AsString())
+}
+
+func TestQuerySpan_Error(t *testing.T) {
+	c, exporter := newTracingTestPool()
+
+	ctx := c.startQuerySpan(context.Background(), "SendBatch")
+	c.observeQuery(ctx, "SendBatch", "INSERT", nil, time.Now(), unknownRows, &pgconn.PgError{Code: "23505"})
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+
+	attrs := spanAttributes(spans[0])
+	assert.Equal(t, "23505", attrs[sqlStateAttr].AsString())
+	_, ok := attrs[rowsAttr]
+	assert.False(t, ok)
+}
+
+func TestQuerySpan_CountsReadRows(t *testing.T) {
+	c, exporter := newTracingTestPool()
+
+	ctx := c.startQuerySpan(context.Background(), "ListClients")
+	r := c.instrumentRows(ctx, &fakeRows{left: 3}, "ListClients", "SELECT", nil, time.Now())
+	for (*r).Next() {
+		assert.Empty(t, exporter.GetSpans())
+	}
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, int64(3), spanAttributes(spans[0])[rowsAttr].AsInt64())
+}
+
+func TestTransactionSpan(t *testing.T) {
+	c, exporter := newTracingTestPool()
+
+	ctx, span := c.startTransactionSpan(context.Background(), "UpdateClient")
+	queryCtx := c.startQuerySpan(ctx, "UpdateClient")
+	c.observeQuery(queryCtx, "UpdateClient", "UPDATE client", nil, time.Now(), 1, nil)
+	endTransactionSpan(span, errors.New("version mismatch"))
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, spans[1].SpanContext.SpanID(), spans[0].Parent.SpanID())
+	assert.Equal(t, codes.Error, spans[1].Status.Code)
+}
@@ -2,16 +2,29 @@ package pgx
 
 import (
 	"context"
+	"errors"
+	"math/rand/v2"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	serializationFailureCode = "40001"
+	deadlockDetectedCode     = "40P01"
+
+	txRetryMinBackoff = 10 * time.Millisecond
 )
 
 type Transaction struct {
 	tx *pgx.Tx
 	c  *ConnectionPool
+	// replica runs the read only transaction, it is nil for the primary
+	replica *replica
 }
 
+// QueryRow is observed when the row is scanned
 func (t *Transaction) QueryRow(
 	ctx context.Context,
 	dbFuncName string,
@@ -19,15 +32,10 @@ func (t *Transaction) QueryRow(
 	namedArgs pgx.NamedArgs,
 ) *pgx.Row {
 	start := time.Now()
+	ctx = t.c.startQuerySpan(ctx, dbFuncName)
 	r := (*t.tx).QueryRow(ctx, sql, namedArgs)
-	diff := time.Since(start)
-
-	if t.c.metrics.qm != nil {
-		t.c.metrics.qm.ObserveQueryDurationHistogram(diff.Seconds(), dbFuncName)
-		t.c.metrics.qm.IncQueryCounter(querySuccess, dbFuncName)
-	}
 
-	return &r
+	return t.c.instrumentRow(ctx, r, dbFuncName, sql, namedArgs, start)
 }
 
 func (t *Transaction) Query(
@@ -37,30 +45,109 @@ func (t *Transaction) Query(
 	namedArgs pgx.NamedArgs,
 ) (*pgx.Rows, error) {
 	start := time.Now()
+	ctx = t.c.startQuerySpan(ctx, dbFuncName)
 	r, err := (*t.tx).Query(ctx, sql, namedArgs)
-
+	if err == nil {
+		err = r.Err()
+	}
 	if err != nil {
-		if t.c.metrics.qm != nil {
-			t.c.metrics.qm.IncQueryCounter(queryError, dbFuncName)
-		}
+		t.c.observeQuery(ctx, dbFuncName, sql, namedArgs, start, 0, err)
 
 		return nil, err
 	}
-	err = r.Err()
+
+	return t.c.instrumentRows(ctx, r, dbFuncName, sql, namedArgs, start), nil
+}
+
+func (t *Transaction) CopyFrom(
+	ctx context.Context,
+	dbFuncName string,
+	table string,
+	columns []string,
+	rows [][]any,
+) (copied int64, err error) {
+	start := time.Now()
+	ctx = t.c.startQuerySpan(ctx, dbFuncName)
+	defer func() {
+		t.c.observeQuery(ctx, dbFuncName, "COPY "+table, nil, start, copied, err)
+	}()
+
+	return (*t.tx).CopyFrom(ctx, pgx.Identifier{table}, columns, pgx.CopyFromRows(rows))
+}
+
+// WithTransaction runs f in a savepoint, the changes of f are rolled back when it fails and the outer transaction goes on
+func (t *Transaction) WithTransaction(ctx context.Context, name string, f func(tx ConnectionTx) error) error {
+	return t.withSavepoint(ctx, name, func(_ context.Context, tx *Transaction) error {
+		return f(tx)
+	})
+}
+
+func (t *Transaction) withSavepoint(ctx context.Context, name string, f func(ctx context.Context, tx *Transaction) error) (err error) {
+	ctx, span := t.c.startTransactionSpan(ctx, name)
+	defer func() {
+		endTransactionSpan(span, err)
+	}()
+
+	savepoint, err := (*t.tx).Begin(ctx)
 	if err != nil {
-		if t.c.metrics.qm != nil {
-			t.c.metrics.qm.IncQueryCounter(queryError, dbFuncName)
+		return err
+	}
+
+	tErr := f(ctx, &Transaction{
+		tx:      &savepoint,
+		c:       t.c,
+		replica: t.replica,
+	})
+	if tErr != nil {
+		if t.c.metrics.tm != nil {
+			t.c.metrics.tm.IncTransactionCounter(transactionRollback, name)
 		}
 
-		return nil, err
+		if rollbackErr := savepoint.Rollback(ctx); rollbackErr != nil && !errors.Is(rollbackErr, pgx.ErrTxClosed) {
+			t.c.log.WithContext(ctx).ErrorWithMetadata("unexpected error during savepoint rollback", map[string]any{
+				"error": rollbackErr.Error(),
+				"name":  name,
+			})
+			return rollbackErr
+		}
+
+		return tErr
+	}
+
+	err = savepoint.Commit(ctx)
+	if err != nil {
+		return err
 	}
 
-	diff := time.Since(start)
+	if t.c.metrics.tm != nil {
+		t.c.metrics.tm.IncTransactionCounter(transactionCommit, name)
+	}
+
+	return nil
+}
 
-	if t.c.metrics.qm != nil {
-		t.c.metrics.qm.ObserveQueryDurationHistogram(diff.Seconds(), dbFuncName)
-		t.c.metrics.qm.IncQueryCounter(querySuccess, dbFuncName)
+func pgxTxOptions(o TxOptions) pgx.TxOptions {
+	return pgx.TxOptions{
+		IsoLevel:       pgx.TxIsoLevel(o.IsoLevel),
+		AccessMode:     pgx.TxAccessMode(o.AccessMode),
+		DeferrableMode: pgx.TxDeferrableMode(o.DeferrableMode),
+		BeginQuery:     o.BeginQuery,
 	}
+}
+
+// isRetryableTxError tells whether the transaction was aborted by a conflict and can succeed when run again
+func isRetryableTxError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	return pgErr.Code == serializationFailureCode || pgErr.Code == deadlockDetectedCode
+}
+
+// txRetryBackoff doubles with every attempt, the jitter spreads the conflicting transactions apart
+func txRetryBackoff(attempt int) time.Duration {
+	backoff := txRetryMinBackoff << min(attempt-1, 6)
 
-	return &r, nil
+	return backoff/2 + rand.N(backoff/2+1)
 }
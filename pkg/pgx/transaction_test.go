@@ -0,0 +1,48 @@
+package pgx
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPgxTxOptions(t *testing.T) {
+	assert.Equal(t, pgx.TxOptions{
+		IsoLevel:       pgx.Serializable,
+		AccessMode:     pgx.ReadOnly,
+		DeferrableMode: pgx.Deferrable,
+	}, pgxTxOptions(TxOptions{
+		IsoLevel:       Serializable,
+		AccessMode:     ReadOnly,
+		DeferrableMode: Deferrable,
+	}))
+	assert.Equal(t, pgx.TxOptions{BeginQuery: "BEGIN ISOLATION LEVEL REPEATABLE READ"}, pgxTxOptions(TxOptions{
+		BeginQuery: "BEGIN ISOLATION LEVEL REPEATABLE READ",
+	}))
+	assert.Equal(t, pgx.TxOptions{}, pgxTxOptions(TxOptions{}))
+}
+
+func TestIsRetryableTxError(t *testing.T) {
+	assert.True(t, isRetryableTxError(&pgconn.PgError{Code: serializationFailureCode}))
+	assert.True(t, isRetryableTxError(fmt.Errorf("commit: %w", &pgconn.PgError{Code: deadlockDetectedCode})))
+	assert.False(t, isRetryableTxError(&pgconn.PgError{Code: "23505"}))
+	assert.False(t, isRetryableTxError(errors.New("connection refused")))
+}
+
+func TestTxRetryBackoff(t *testing.T) {
+	for attempt, maxBackoff := range map[int]time.Duration{
+		1:   10 * time.Millisecond,
+		2:   20 * time.Millisecond,
+		3:   40 * time.Millisecond,
+		100: 640 * time.Millisecond,
+	} {
+		backoff := txRetryBackoff(attempt)
+		assert.GreaterOrEqual(t, backoff, maxBackoff/2)
+		assert.LessOrEqual(t, backoff, maxBackoff)
+	}
+}
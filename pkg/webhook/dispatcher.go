@@ -0,0 +1,169 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
+)
+
+type Delivery struct {
+	ID        int64
+	URL       string
+	Secret    string
+	EventType string
+	Body      []byte
+	// Attempts is the number of failed delivery attempts
+	Attempts int
+}
+
+type Store interface {
+	// Claim leases up to limit pending deliveries whose retry is due
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error)
+	Complete(ctx context.Context, id int64, statusCode int) error
+	// Fail schedules the next attempt after the backoff, dead deliveries are not attempted anymore.
+	// The status code is zero when no response was received.
+	Fail(ctx context.Context, id int64, statusCode int, reason string, backoff time.Duration, dead bool) error
+}
+
+type DispatcherConfig struct {
+	Interval  time.Duration
+	BatchSize int
+	// Lease is how long a claimed delivery is hidden from other dispatchers, it has to be longer than the client timeout
+	Lease       time.Duration
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+	MaxAttempts int
+}
+
+// Dispatcher periodically POSTs the pending deliveries signed with the subscription secret.
+// Failed deliveries are retried with exponential backoff and become dead after MaxAttempts.
+type Dispatcher struct {
+	store  Store
+	client *http.Client
+	cfg    DispatcherConfig
+	lg     logger.Logger
+}
+
+func NewDispatcher(store Store, client *http.Client, cfg DispatcherConfig, lg logger.Logger) *Dispatcher {
+	return &Dispatcher{
+		store:  store,
+		client: client,
+		cfg:    cfg,
+		lg:     lg,
+	}
+}
+
+// Run blocks until the context is done
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		// a full batch means there are probably more deliveries waiting
+		for d.dispatch(ctx) == d.cfg.BatchSize {
+			if ctx.Err() != nil {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatch delivers one batch concurrently and returns its size
+func (d *Dispatcher) dispatch(ctx context.Context) int {
+	deliveries, err := d.store.Claim(ctx, d.cfg.BatchSize, d.cfg.Lease)
+	if err != nil {
+		d.lg.ErrorWithMetadata("unable to claim webhook deliveries", map[string]any{
+			"error": err.Error(),
+		})
+		return 0
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.deliver(ctx, delivery)
+		}()
+	}
+	wg.Wait()
+
+	return len(deliveries)
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery Delivery) {
+	statusCode, err := d.post(ctx, delivery)
+	if err == nil {
+		err = d.store.Complete(ctx, delivery.ID, statusCode)
+		if err != nil {
+			d.lg.ErrorWithMetadata("unable to complete webhook delivery", map[string]any{
+				"error": err.Error(),
+				"id":    delivery.ID,
+			})
+		}
+		return
+	}
+
+	dead := delivery.Attempts+1 >= d.cfg.MaxAttempts
+	backoff := d.backoff(delivery.Attempts)
+	d.lg.WarnWithMetadata("webhook delivery failed", map[string]any{
+		"error":    err.Error(),
+		"id":       delivery.ID,
+		"attempts": delivery.Attempts + 1,
+		"dead":     dead,
+	})
+
+	err = d.store.Fail(ctx, delivery.ID, statusCode, err.Error(), backoff, dead)
+	if err != nil {
+		d.lg.ErrorWithMetadata("unable to fail webhook delivery", map[string]any{
+			"error": err.Error(),
+			"id":    delivery.ID,
+		})
+	}
+}
+
+// post returns an error for any other than 2xx response
+func (d *Dispatcher) post(ctx context.Context, delivery Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(DeliveryIDHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(EventTypeHeader, delivery.EventType)
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, time.Now(), delivery.Body))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return res.StatusCode, fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
+
+// backoff doubles with every failed attempt up to MaxBackoff
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	backoff := d.cfg.MinBackoff
+	for i := 0; i < attempts && backoff < d.cfg.MaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, d.cfg.MaxBackoff)
+}
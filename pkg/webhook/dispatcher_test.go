@@ -0,0 +1,153 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jamm3e3333/whalebone-go-test-project/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type storeMock struct {
+	mu         sync.Mutex
+	deliveries map[int64]*Delivery
+	delivered  map[int64]int
+	dead       map[int64]bool
+	backoffs   map[int64]time.Duration
+}
+
+func newStoreMock(deliveries ...Delivery) *storeMock {
+	s := &storeMock{
+		deliveries: map[int64]*Delivery{},
+		delivered:  map[int64]int{},
+		dead:       map[int64]bool{},
+		backoffs:   map[int64]time.Duration{},
+	}
+	for _, d := range deliveries {
+		s.deliveries[d.ID] = &d
+	}
+
+	return s
+}
+
+// Claim returns every pending delivery ignoring the backoff
+func (s *storeMock) Claim(_ context.Context, limit int, _ time.Duration) ([]Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	claimed := make([]Delivery, 0, limit)
+	for _, d := range s.deliveries {
+		if len(claimed) == limit {
+			break
+		}
+		claimed = append(claimed, *d)
+	}
+
+	return claimed, nil
+}
+
+func (s *storeMock) Complete(_ context.Context, id int64, statusCode int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.deliveries, id)
+	s.delivered[id] = statusCode
+
+	return nil
+}
+
+func (s *storeMock) Fail(_ context.Context, id int64, _ int, _ string, backoff time.Duration, dead bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deliveries[id].Attempts++
+	s.backoffs[id] = backoff
+	if dead {
+		delete(s.deliveries, id)
+		s.dead[id] = true
+	}
+
+	return nil
+}
+
+func newTestDispatcher(store Store) *Dispatcher {
+	return NewDispatcher(store, &http.Client{Timeout: time.Second}, DispatcherConfig{
+		Interval:    time.Second,
+		BatchSize:   10,
+		Lease:       time.Minute,
+		MinBackoff:  time.Second,
+		MaxBackoff:  5 * time.Second,
+		MaxAttempts: 3,
+	}, logger.New(logger.ParseLevel("debug"), false))
+}
+
+func TestDispatcher_DeliversSignedRequest(t *testing.T) {
+	const secret = "whsec_test_secret"
+	body := []byte(`{"event_type":"client.created"}`)
+
+	var (
+		mu      sync.Mutex
+		headers http.Header
+		payload []byte
+	)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		headers = r.Header.Clone()
+		payload, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	store := newStoreMock(Delivery{ID: 7, URL: receiver.URL, Secret: secret, EventType: "client.created", Body: body})
+	dispatcher := newTestDispatcher(store)
+
+	assert.Equal(t, 1, dispatcher.dispatch(context.Background()))
+
+	assert.Equal(t, http.StatusNoContent, store.delivered[7])
+	assert.Equal(t, body, payload)
+	assert.Equal(t, "application/json", headers.Get("Content-Type"))
+	assert.Equal(t, "7", headers.Get(DeliveryIDHeader))
+	assert.Equal(t, "client.created", headers.Get(EventTypeHeader))
+	require.NoError(t, Verify(secret, headers.Get(SignatureHeader), payload, time.Minute, time.Now()))
+	assert.ErrorIs(t, Verify("other_secret", headers.Get(SignatureHeader), payload, time.Minute, time.Now()), ErrInvalidSignature)
+}
+
+func TestDispatcher_FailedDeliveryBecomesDead(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	store := newStoreMock(Delivery{ID: 1, URL: receiver.URL, Secret: "secret", Body: []byte(`{}`)})
+	dispatcher := newTestDispatcher(store)
+
+	dispatcher.dispatch(context.Background())
+	assert.Equal(t, time.Second, store.backoffs[1])
+	assert.False(t, store.dead[1])
+
+	dispatcher.dispatch(context.Background())
+	assert.Equal(t, 2*time.Second, store.backoffs[1])
+	assert.False(t, store.dead[1])
+
+	dispatcher.dispatch(context.Background())
+	assert.True(t, store.dead[1])
+	assert.Equal(t, 0, dispatcher.dispatch(context.Background()))
+	assert.Empty(t, store.delivered)
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	dispatcher := newTestDispatcher(newStoreMock())
+
+	assert.Equal(t, time.Second, dispatcher.backoff(0))
+	assert.Equal(t, 2*time.Second, dispatcher.backoff(1))
+	assert.Equal(t, 4*time.Second, dispatcher.backoff(2))
+	assert.Equal(t, 5*time.Second, dispatcher.backoff(3))
+	assert.Equal(t, 5*time.Second, dispatcher.backoff(100))
+}
@@ -0,0 +1,65 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SignatureHeader carries "t=<unix timestamp>,v1=<hex HMAC-SHA256 of "<timestamp>.<body>">"
+	SignatureHeader  = "X-Webhook-Signature"
+	DeliveryIDHeader = "X-Webhook-Delivery"
+	EventTypeHeader  = "X-Webhook-Event"
+
+	signatureTimestampKey = "t"
+	signatureV1Key        = "v1"
+)
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Sign returns the value of the SignatureHeader, the timestamp is signed to prevent replays of old deliveries
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+
+	return signatureTimestampKey + "=" + ts + "," + signatureV1Key + "=" + sign(secret, ts, body)
+}
+
+// Verify checks the value of the SignatureHeader, signatures older than the tolerance are rejected
+func Verify(secret string, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var ts, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case signatureTimestampKey:
+			ts = value
+		case signatureV1Key:
+			signature = value
+		}
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || signature == "" {
+		return ErrInvalidSignature
+	}
+	if now.Sub(time.Unix(unix, 0)).Abs() > tolerance {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(sign(secret, ts, body))) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func sign(secret string, ts string, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(ts + "."))
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
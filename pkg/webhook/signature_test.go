@@ -0,0 +1,22 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	const secret = "whsec_test_secret"
+	body := []byte(`{"id":1}`)
+	now := time.Unix(1_700_000_000, 0)
+	header := Sign(secret, now, body)
+
+	assert.NoError(t, Verify(secret, header, body, time.Minute, now.Add(30*time.Second)))
+	assert.ErrorIs(t, Verify(secret, header, body, time.Minute, now.Add(2*time.Minute)), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify(secret, header, []byte(`{"id":2}`), time.Minute, now), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("other", header, body, time.Minute, now), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify(secret, "", body, time.Minute, now), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify(secret, "t=abc,v1=00", body, time.Minute, now), ErrInvalidSignature)
+}